@@ -22,5 +22,5 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	log.Println(ctx)
+	log.Println(ctx.GetAssets())
 }
@@ -2,6 +2,9 @@ package asset
 
 // GetAssetKeyMap creates a map mapping asset IDs to quantities.
 func (ctx *Context) GetAssetKeyMap() map[string]*Asset {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
 	m := map[string]*Asset{}
 
 	for i := range ctx.Assets {
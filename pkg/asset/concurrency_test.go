@@ -0,0 +1,50 @@
+package asset_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+// TestContext_Concurrent_Price_Updates updates prices while other goroutines read them. Run with -race to detect unsynchronized access.
+func TestContext_Concurrent_Price_Updates(t *testing.T) {
+	t.Parallel()
+
+	ctx := asset.Context{}
+	assert.Nil(t, ctx.AddAssets(syntheticAssets(10)))
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				id := fmt.Sprintf("ASSET-%d", i%10)
+				assert.Nil(t, ctx.AddAsset(&asset.Asset{Id: id, UnitPrice: big.NewRat(int64(w*100+i), 1)}))
+			}
+		}(w)
+	}
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_ = ctx.GetAssetKeyPriceMap()
+				_ = ctx.GetAssetKeyMap()
+				_ = ctx.ValidateContext()
+				_, _ = ctx.GetAsset("ASSET-3")
+				for _, a := range ctx.GetAssets() {
+					_ = a.UnitPrice.Sign()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 10, len(ctx.GetAssets()))
+}
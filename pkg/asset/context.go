@@ -2,19 +2,31 @@ package asset
 
 import (
 	"fmt"
+	"github.com/wlachs/wstonks/pkg/internal/index"
 	"log"
 	"math/big"
 	"slices"
+	"sync"
 )
 
 // Context holding Asset data
+// The methods of the Context are safe for concurrent use, e.g. to update prices while calculations are running. The exported slice is not
+// guarded, so code sharing a Context between goroutines should read it through GetAssets instead of accessing it directly. Assets added to
+// the Context must not be modified afterwards; prices are updated by adding a new Asset with the same ID.
 type Context struct {
 	Assets []*Asset
+	// mu guards the assets of the Context.
+	mu sync.RWMutex
+	// index maps asset IDs to their position in Assets.
+	index index.Index[*Asset]
 }
 
 // AddAssets adds a slice of Asset objects to the Context. If the asset can already be found in the Context, the price is updated to the
 // newly imported value.
 func (ctx *Context) AddAssets(assets []*Asset) error {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
 	var err error
 	for _, t := range assets {
 		e := ctx.addAssetInternal(t, false)
@@ -28,14 +40,25 @@ func (ctx *Context) AddAssets(assets []*Asset) error {
 		return err
 	}
 
-	return ctx.ValidateContext()
+	return ctx.validateContext()
 }
 
 // AddAsset adds an Asset to the Context.
 func (ctx *Context) AddAsset(asset *Asset) error {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
 	return ctx.addAssetInternal(asset, true)
 }
 
+// GetAssets returns a copy of the assets of the Context.
+func (ctx *Context) GetAssets() []*Asset {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return append([]*Asset(nil), ctx.Assets...)
+}
+
 // addAssetInternal adds the Asset to the Context.
 // If the validate parameter is true, the Context will be validated after adding the Asset.
 func (ctx *Context) addAssetInternal(asset *Asset, validate bool) error {
@@ -45,7 +68,7 @@ func (ctx *Context) addAssetInternal(asset *Asset, validate bool) error {
 	}
 
 	if validate {
-		return ctx.ValidateContext()
+		return ctx.validateContext()
 	}
 
 	return nil
@@ -57,14 +80,11 @@ func updateAssets(ctx *Context, asset *Asset) error {
 		return fmt.Errorf("missing asset ID %v", asset)
 	}
 
-	i := slices.IndexFunc(ctx.Assets, func(a *Asset) bool {
-		return a.Id == asset.Id
-	})
-
 	// If the asset is not yet known, add it
+	i := ctx.assetIndex(asset.Id)
 	if i == -1 {
-		i = len(ctx.Assets)
-		ctx.Assets = append(ctx.Assets, asset)
+		ctx.indexAsset(asset)
+		return nil
 	}
 
 	ctx.Assets[i] = asset
@@ -73,6 +93,14 @@ func updateAssets(ctx *Context, asset *Asset) error {
 
 // ValidateContext verifies that the Context is in a valid state.
 func (ctx *Context) ValidateContext() error {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.validateContext()
+}
+
+// validateContext verifies that the Context is in a valid state without locking it.
+func (ctx *Context) validateContext() error {
 	i := slices.IndexFunc(ctx.Assets, func(a *Asset) bool {
 		return a.UnitPrice.Cmp(big.NewRat(0, 1)) == -1
 	})
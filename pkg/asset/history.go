@@ -0,0 +1,115 @@
+package asset
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+)
+
+// PricePoint is the unit price of an asset at a given time.
+type PricePoint struct {
+	Timestamp time.Time
+	UnitPrice *big.Rat
+}
+
+// PriceHistory holds historical unit prices of assets. The prices of every asset are kept in chronological order. The methods of the
+// PriceHistory are safe for concurrent use.
+type PriceHistory struct {
+	mu     sync.RWMutex
+	series map[string][]PricePoint
+}
+
+// AddPrice adds a price of the asset to the history. A price at the same time as an existing one replaces it.
+func (h *PriceHistory) AddPrice(assetId string, point PricePoint) error {
+	if assetId == "" {
+		return fmt.Errorf("missing asset ID")
+	}
+
+	if point.UnitPrice == nil || point.UnitPrice.Sign() < 0 {
+		return fmt.Errorf("invalid price of asset %s at %s", assetId, point.Timestamp)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.series == nil {
+		h.series = map[string][]PricePoint{}
+	}
+
+	s := h.series[assetId]
+	i, found := slices.BinarySearchFunc(s, point.Timestamp, func(p PricePoint, t time.Time) int {
+		return p.Timestamp.Compare(t)
+	})
+
+	if found {
+		s[i] = point
+	} else {
+		h.series[assetId] = slices.Insert(s, i, point)
+	}
+
+	return nil
+}
+
+// GetPrices returns a copy of the prices of the asset in chronological order.
+func (h *PriceHistory) GetPrices(assetId string) []PricePoint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return slices.Clone(h.series[assetId])
+}
+
+// GetAssetIds returns the IDs of the assets with prices in the history in ascending order.
+func (h *PriceHistory) GetAssetIds() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ids := make([]string, 0, len(h.series))
+	for id := range h.series {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	return ids
+}
+
+// PriceAt returns the latest price of the asset at or before the given time, e.g. to value a holding. The second return value is false if
+// there is no such price.
+func (h *PriceHistory) PriceAt(assetId string, t time.Time) (PricePoint, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	s := h.series[assetId]
+	i, found := slices.BinarySearchFunc(s, t, func(p PricePoint, t time.Time) int {
+		return p.Timestamp.Compare(t)
+	})
+
+	if found {
+		return s[i], true
+	}
+
+	if i == 0 {
+		return PricePoint{}, false
+	}
+
+	return s[i-1], true
+}
+
+// PriceOnOrAfter returns the earliest price of the asset at or after the given time, e.g. the price an order placed on a non-trading day
+// is executed at. The second return value is false if there is no such price.
+func (h *PriceHistory) PriceOnOrAfter(assetId string, t time.Time) (PricePoint, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	s := h.series[assetId]
+	i, _ := slices.BinarySearchFunc(s, t, func(p PricePoint, t time.Time) int {
+		return p.Timestamp.Compare(t)
+	})
+
+	if i == len(s) {
+		return PricePoint{}, false
+	}
+
+	return s[i], true
+}
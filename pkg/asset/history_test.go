@@ -0,0 +1,38 @@
+package asset_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// TestPriceHistory_PriceAt checks the price lookups before, between and after the prices of the history.
+func TestPriceHistory_PriceAt(t *testing.T) {
+	t.Parallel()
+
+	h := asset.PriceHistory{}
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	assert.NoError(t, h.AddPrice("A", asset.PricePoint{Timestamp: day(5), UnitPrice: big.NewRat(2, 1)}))
+	assert.NoError(t, h.AddPrice("A", asset.PricePoint{Timestamp: day(1), UnitPrice: big.NewRat(1, 1)}))
+	assert.NoError(t, h.AddPrice("A", asset.PricePoint{Timestamp: day(5), UnitPrice: big.NewRat(3, 1)}))
+	assert.Error(t, h.AddPrice("A", asset.PricePoint{Timestamp: day(6)}))
+
+	_, ok := h.PriceAt("A", day(0))
+	assert.False(t, ok)
+
+	p, ok := h.PriceAt("A", day(3))
+	assert.True(t, ok)
+	assert.Equal(t, day(1), p.Timestamp)
+
+	p, ok = h.PriceOnOrAfter("A", day(3))
+	assert.True(t, ok)
+	assert.Equal(t, big.NewRat(3, 1), p.UnitPrice)
+
+	_, ok = h.PriceOnOrAfter("A", day(6))
+	assert.False(t, ok)
+	assert.Len(t, h.GetPrices("A"), 2)
+}
@@ -0,0 +1,29 @@
+package asset
+
+// GetAsset returns the Asset with the given ID. The second return value is false if the Context holds no such asset.
+func (ctx *Context) GetAsset(assetId string) (*Asset, bool) {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	i := ctx.assetIndex(assetId)
+	if i == -1 {
+		return nil, false
+	}
+
+	return ctx.Assets[i], true
+}
+
+// assetIndex returns the position of the Asset with the given ID in the Assets slice or -1 if the Context holds no such asset.
+func (ctx *Context) assetIndex(assetId string) int {
+	return ctx.index.Lookup(ctx.Assets, assetIdOf, assetId)
+}
+
+// indexAsset appends the Asset to the Assets slice and records its position in the index.
+func (ctx *Context) indexAsset(asset *Asset) {
+	ctx.Assets = ctx.index.Append(ctx.Assets, assetIdOf, asset)
+}
+
+// assetIdOf returns the ID of the Asset.
+func assetIdOf(asset *Asset) string {
+	return asset.Id
+}
@@ -0,0 +1,120 @@
+package asset_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"math/big"
+	"slices"
+	"testing"
+)
+
+// syntheticAssets generates n assets with distinct IDs.
+func syntheticAssets(n int) []*asset.Asset {
+	assets := make([]*asset.Asset, n)
+	for i := range assets {
+		assets[i] = &asset.Asset{Id: fmt.Sprintf("ASSET-%d", i), UnitPrice: big.NewRat(int64(100+i%7), 1)}
+	}
+
+	return assets
+}
+
+// TestContext_GetAsset tests the asset lookup of the Context, including updating the price of a known asset.
+func TestContext_GetAsset(t *testing.T) {
+	t.Parallel()
+
+	ctx := asset.Context{}
+	err := ctx.AddAssets(syntheticAssets(5))
+	assert.Nil(t, err)
+
+	err = ctx.AddAsset(&asset.Asset{Id: "ASSET-3", UnitPrice: big.NewRat(1, 2)})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(ctx.Assets))
+
+	a, ok := ctx.GetAsset("ASSET-3")
+	assert.True(t, ok)
+	assert.Equal(t, big.NewRat(1, 2), a.UnitPrice)
+
+	_, ok = ctx.GetAsset("ASSET-5")
+	assert.False(t, ok)
+}
+
+// TestContext_GetAsset_Modified_Assets tests that the asset lookup stays consistent if the Assets slice is modified directly.
+func TestContext_GetAsset_Modified_Assets(t *testing.T) {
+	t.Parallel()
+
+	ctx := asset.Context{}
+	err := ctx.AddAssets(syntheticAssets(3))
+	assert.Nil(t, err)
+
+	ctx.Assets = slices.Delete(ctx.Assets, 0, 1)
+	_, ok := ctx.GetAsset("ASSET-0")
+	assert.False(t, ok)
+
+	a, ok := ctx.GetAsset("ASSET-2")
+	assert.True(t, ok)
+	assert.Equal(t, ctx.Assets[1], a)
+
+	ctx.Assets = append(ctx.Assets, &asset.Asset{Id: "B", UnitPrice: big.NewRat(1, 1)})
+	err = ctx.AddAsset(&asset.Asset{Id: "B", UnitPrice: big.NewRat(2, 1)})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(ctx.Assets))
+	assert.Equal(t, big.NewRat(2, 1), ctx.Assets[2].UnitPrice)
+}
+
+// TestContext_GetAsset_Replaced_Asset tests that the asset lookup notices an entry of the Assets slice replaced directly once the replaced
+// asset is looked up.
+func TestContext_GetAsset_Replaced_Asset(t *testing.T) {
+	t.Parallel()
+
+	ctx := asset.Context{}
+	err := ctx.AddAssets(syntheticAssets(3))
+	assert.Nil(t, err)
+
+	ctx.Assets[1] = &asset.Asset{Id: "B", UnitPrice: big.NewRat(1, 1)}
+	_, ok := ctx.GetAsset("ASSET-1")
+	assert.False(t, ok)
+
+	a, ok := ctx.GetAsset("B")
+	assert.True(t, ok)
+	assert.Equal(t, ctx.Assets[1], a)
+
+	err = ctx.AddAsset(&asset.Asset{Id: "B", UnitPrice: big.NewRat(2, 1)})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(ctx.Assets))
+	assert.Equal(t, big.NewRat(2, 1), ctx.Assets[1].UnitPrice)
+}
+
+// TestContext_GetAsset_Duplicate_Ids tests that neither duplicate IDs in the Assets slice nor misses cause the index to be rebuilt on every
+// lookup.
+// The test does not run in parallel, since testing.AllocsPerRun doesn't allow it.
+func TestContext_GetAsset_Duplicate_Ids(t *testing.T) {
+	ctx := asset.Context{}
+	err := ctx.AddAssets(syntheticAssets(3))
+	assert.Nil(t, err)
+
+	ctx.Assets = append(ctx.Assets, &asset.Asset{Id: "ASSET-1", UnitPrice: big.NewRat(1, 1)})
+	a, ok := ctx.GetAsset("ASSET-1")
+	assert.True(t, ok)
+	assert.Equal(t, "ASSET-1", a.Id)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = ctx.GetAsset("ASSET-0")
+		_, _ = ctx.GetAsset("ASSET-1")
+		_, _ = ctx.GetAsset("MISSING")
+	})
+	assert.Zero(t, allocs)
+}
+
+// BenchmarkContext_AddAssets measures loading assets into a Context.
+func BenchmarkContext_AddAssets(b *testing.B) {
+	for _, m := range []int{100, 1000, 10000} {
+		assets := syntheticAssets(m)
+		b.Run(fmt.Sprintf("assets=%d", m), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ctx := asset.Context{}
+				_ = ctx.AddAssets(assets)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/wlachs/wstonks/pkg/asset"
 	"github.com/wlachs/wstonks/pkg/ioutils"
+	"io"
+	"io/fs"
 	"log"
 )
 
@@ -12,30 +14,68 @@ type LiveAssetCsvLoader struct {
 	Path string
 }
 
+// LiveAssetCsvReaderLoader implements the LiveAssetLoader interface to allow importing context data from CSV content provided by a reader,
+// e.g. an HTTP response body or the standard input.
+type LiveAssetCsvReaderLoader struct {
+	Reader io.Reader
+}
+
+// LiveAssetCsvFSLoader implements the LiveAssetLoader interface to allow importing context data from a CSV file of a file system, e.g. an
+// embed.FS holding embedded test data.
+type LiveAssetCsvFSLoader struct {
+	FS   fs.FS
+	Path string
+}
+
 // Load tries to parse the CSV file at Path and loads the data into the context.
 func (l LiveAssetCsvLoader) Load(ctx *asset.Context) error {
-	a, err := parseCsv(l.Path)
+	fileContent, err := ioutils.ReadCsvFile(l.Path)
 	if err != nil {
 		return err
 	}
 
-	return ctx.AddAssets(a)
+	return loadCsv(ctx, fileContent)
 }
 
-// parseCsv reads the CSV file at the given path and tries to convert it to a asset.Asset slice.
-func parseCsv(path string) ([]*asset.Asset, error) {
-	fileContent, err := ioutils.ReadCsvFile(path)
+// Load tries to parse the CSV content of Reader and loads the data into the context.
+func (l LiveAssetCsvReaderLoader) Load(ctx *asset.Context) error {
+	content, err := ioutils.ReadCsv(l.Reader)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	assets := make([]*asset.Asset, 0, len(fileContent))
-	if len(fileContent) == 0 {
+	return loadCsv(ctx, content)
+}
+
+// Load tries to parse the CSV file at Path of FS and loads the data into the context.
+func (l LiveAssetCsvFSLoader) Load(ctx *asset.Context) error {
+	fileContent, err := ioutils.ReadCsvFS(l.FS, l.Path)
+	if err != nil {
+		return err
+	}
+
+	return loadCsv(ctx, fileContent)
+}
+
+// loadCsv converts the CSV rows and adds them to the context.
+func loadCsv(ctx *asset.Context, content [][]string) error {
+	a, err := parseCsv(content)
+	if err != nil {
+		return err
+	}
+
+	return ctx.AddAssets(a)
+}
+
+// parseCsv tries to convert the CSV rows to an asset.Asset slice.
+func parseCsv(content [][]string) ([]*asset.Asset, error) {
+	assets := make([]*asset.Asset, 0, len(content))
+	if len(content) == 0 {
 		log.Println("the CSV file is empty")
 		return assets, nil
 	}
 
-	for _, row := range fileContent {
+	for _, row := range content {
 		a, rowErr := readCsvRow(row)
 		if rowErr != nil {
 			return nil, rowErr
@@ -49,6 +89,10 @@ func parseCsv(path string) ([]*asset.Asset, error) {
 
 // readCsvRow converts a single entry of the CSV file to a model.Tx object.
 func readCsvRow(row []string) (*asset.Asset, error) {
+	if len(row) < 2 {
+		return nil, fmt.Errorf("missing columns in row %v", row)
+	}
+
 	assetId, err := parseAssetId(row[0])
 	if err != nil {
 		return nil, err
@@ -0,0 +1,60 @@
+package io_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/asset/io"
+	"math/big"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// TestLiveAssetCsvLoader_Load is a smoke-test for a well-formatted asset input CSV.
+func TestLiveAssetCsvLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	ctx := asset.Context{}
+	loader := io.LiveAssetCsvLoader{Path: "../../../test/data/io/assets/smoke.csv"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ctx.Assets))
+}
+
+// TestLiveAssetCsvReaderLoader_Load tests loading CSV content from a reader.
+func TestLiveAssetCsvReaderLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	ctx := asset.Context{}
+	loader := io.LiveAssetCsvReaderLoader{Reader: strings.NewReader("A,107.1234\nB,51.21121\n")}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*big.Rat{"A": big.NewRat(535617, 5000), "B": big.NewRat(5121121, 100000)}, ctx.GetAssetKeyPriceMap())
+}
+
+// TestLiveAssetCsvFSLoader_Load tests loading a CSV file of an in-memory file system.
+func TestLiveAssetCsvFSLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{"assets.csv": {Data: []byte("A,1/3\n")}}
+	ctx := asset.Context{}
+	loader := io.LiveAssetCsvFSLoader{FS: fsys, Path: "assets.csv"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewRat(1, 3), ctx.Assets[0].UnitPrice)
+}
+
+// TestLiveAssetCsvFSLoader_Load_Missing_File tests loading a non-existing file of an in-memory file system.
+func TestLiveAssetCsvFSLoader_Load_Missing_File(t *testing.T) {
+	t.Parallel()
+
+	ctx := asset.Context{}
+	loader := io.LiveAssetCsvFSLoader{FS: fstest.MapFS{}, Path: "assets.csv"}
+	err := loader.Load(&ctx)
+
+	assert.Equal(t, fmt.Errorf("failed to open file \"assets.csv\""), err)
+}
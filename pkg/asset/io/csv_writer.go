@@ -0,0 +1,30 @@
+package io
+
+import (
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/ioutils"
+)
+
+// LiveAssetCsvWriter implements the LiveAssetWriter interface to allow exporting the context's assets to a CSV file that can be read by
+// LiveAssetCsvLoader. Unit prices are written with the given number of Decimals using the given Rounding mode. The zero value of Rounding
+// is ioutils.RoundExact, which writes the values without losing precision.
+type LiveAssetCsvWriter struct {
+	Path     string
+	Decimals int
+	Rounding ioutils.Rounding
+}
+
+// Write saves the context's assets to the CSV file at Path.
+func (w LiveAssetCsvWriter) Write(ctx *asset.Context) error {
+	assets := ctx.GetAssets()
+	rows := make([][]string, 0, len(assets))
+
+	for _, a := range assets {
+		rows = append(rows, []string{
+			a.Id,
+			ioutils.FormatRatWithPrecision(a.UnitPrice, w.Decimals, w.Rounding),
+		})
+	}
+
+	return ioutils.WriteCsvFile(w.Path, rows)
+}
@@ -0,0 +1,51 @@
+package io_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/asset/io"
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLiveAssetCsvWriter_Write tests that a written CSV file can be loaded again without losing data.
+func TestLiveAssetCsvWriter_Write(t *testing.T) {
+	t.Parallel()
+
+	ctx := asset.Context{}
+	err := io.LiveAssetCsvLoader{Path: "../../../test/data/io/assets/smoke_sales.csv"}.Load(&ctx)
+	assert.Nil(t, err)
+
+	err = ctx.AddAsset(&asset.Asset{Id: "G", UnitPrice: big.NewRat(2, 3)})
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "assets.csv")
+	err = io.LiveAssetCsvWriter{Path: path}.Write(&ctx)
+	assert.Nil(t, err)
+
+	loaded := asset.Context{}
+	err = io.LiveAssetCsvLoader{Path: path}.Load(&loaded)
+	assert.Nil(t, err)
+
+	assert.Equal(t, ctx.GetAssetKeyPriceMap(), loaded.GetAssetKeyPriceMap())
+}
+
+// TestLiveAssetCsvWriter_Write_Rounding tests writing rounded unit prices.
+func TestLiveAssetCsvWriter_Write_Rounding(t *testing.T) {
+	t.Parallel()
+
+	ctx := asset.Context{}
+	err := ctx.AddAsset(&asset.Asset{Id: "A", UnitPrice: big.NewRat(2, 3)})
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "assets.csv")
+	err = io.LiveAssetCsvWriter{Path: path, Decimals: 4, Rounding: ioutils.RoundHalfUp}.Write(&ctx)
+	assert.Nil(t, err)
+
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "A,0.6667\n", string(content))
+}
@@ -0,0 +1,61 @@
+package io
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"log"
+	"strconv"
+	"time"
+)
+
+// PriceHistoryCsvLoader implements the PriceHistoryLoader interface to allow importing historical prices from a CSV file. Every row
+// holds the Unix millisecond timestamp, the asset ID and the unit price, e.g. "1712000000000,A,107.1234".
+type PriceHistoryCsvLoader struct {
+	Path string
+}
+
+// Load tries to parse the CSV file at Path and adds the prices to the history.
+func (l PriceHistoryCsvLoader) Load(h *asset.PriceHistory) error {
+	fileContent, err := ioutils.ReadCsvFile(l.Path)
+	if err != nil {
+		return err
+	}
+
+	if len(fileContent) == 0 {
+		log.Println("the CSV file is empty")
+		return nil
+	}
+
+	for _, row := range fileContent {
+		if rowErr := readHistoryCsvRow(h, row); rowErr != nil {
+			return rowErr
+		}
+	}
+
+	return nil
+}
+
+// readHistoryCsvRow converts a single entry of the CSV file to an asset.PricePoint and adds it to the history.
+func readHistoryCsvRow(h *asset.PriceHistory, row []string) error {
+	if len(row) < 3 {
+		return fmt.Errorf("missing columns in row %v", row)
+	}
+
+	ms, err := strconv.ParseInt(row[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse TS of row %v", row)
+	}
+
+	assetId, err := parseAssetId(row[1])
+	if err != nil {
+		return fmt.Errorf("failed to parse asset ID of row %v", row)
+	}
+
+	unitPrice, err := ioutils.ParseRat(row[2])
+	if err != nil {
+		return fmt.Errorf("failed to parse unit price of row %v", row)
+	}
+
+	return h.AddPrice(assetId, asset.PricePoint{Timestamp: time.UnixMilli(ms), UnitPrice: unitPrice})
+}
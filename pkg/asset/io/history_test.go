@@ -0,0 +1,37 @@
+package io_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/asset/io"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// TestPriceHistoryCsvLoader_Load is a smoke-test for a well-formatted, unordered price history CSV.
+func TestPriceHistoryCsvLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	h := asset.PriceHistory{}
+	loader := io.PriceHistoryCsvLoader{Path: "../../../test/data/io/history/smoke.csv"}
+	err := loader.Load(&h)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"A", "B"}, h.GetAssetIds())
+
+	prices := h.GetPrices("A")
+	assert.Len(t, prices, 2)
+	assert.Equal(t, time.UnixMilli(1706659200000), prices[0].Timestamp)
+	assert.Equal(t, big.NewRat(125, 1), prices[1].UnitPrice)
+}
+
+// TestPriceHistoryCsvLoader_Load_Invalid checks that a missing file is reported.
+func TestPriceHistoryCsvLoader_Load_Invalid(t *testing.T) {
+	t.Parallel()
+
+	h := asset.PriceHistory{}
+	loader := io.PriceHistoryCsvLoader{Path: "../../../test/data/io/history/missing.csv"}
+
+	assert.Error(t, loader.Load(&h))
+}
@@ -9,3 +9,9 @@ type LiveAssetLoader interface {
 	// Load loads data to the context from an arbitrary source.
 	Load(ctx *asset.Context) error
 }
+
+// PriceHistoryLoader interface to allow populating asset.PriceHistory with historical prices.
+type PriceHistoryLoader interface {
+	// Load loads historical prices from an arbitrary source.
+	Load(h *asset.PriceHistory) error
+}
@@ -0,0 +1,75 @@
+package io
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"log"
+)
+
+// LiveAssetOfxLoader implements the LiveAssetLoader interface to allow importing the position prices (INVPOSLIST) of an OFX or QFX
+// investment statement. Securities are identified by their ticker symbol if the statement contains a security list, otherwise by their
+// unique ID, e.g. the CUSIP.
+type LiveAssetOfxLoader struct {
+	Path string
+}
+
+// Load tries to parse the OFX file at Path and loads the position prices into the context.
+func (l LiveAssetOfxLoader) Load(ctx *asset.Context) error {
+	a, err := parseOfx(l.Path)
+	if err != nil {
+		return err
+	}
+
+	return ctx.AddAssets(a)
+}
+
+// parseOfx reads the OFX file at the given path and tries to convert the positions to an asset.Asset slice.
+func parseOfx(path string) ([]*asset.Asset, error) {
+	root, err := ioutils.ReadOfxFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := ioutils.OfxTickers(root)
+	positions := root.FindAll("INVPOS")
+	assets := make([]*asset.Asset, 0, len(positions))
+
+	for _, position := range positions {
+		a, posErr := readOfxPosition(position, tickers)
+		if posErr != nil {
+			return nil, posErr
+		}
+
+		assets = append(assets, a)
+	}
+
+	if len(assets) == 0 {
+		log.Println("the OFX statement contains no positions")
+	}
+
+	return assets, nil
+}
+
+// readOfxPosition converts a single INVPOS aggregate of the OFX file to an asset.Asset object.
+func readOfxPosition(position *ioutils.OfxElement, tickers map[string]string) (*asset.Asset, error) {
+	uniqueId := position.Text("SECID", "UNIQUEID")
+	if ticker, ok := tickers[uniqueId]; ok {
+		uniqueId = ticker
+	}
+
+	assetId, err := parseAssetId(uniqueId)
+	if err != nil {
+		return nil, err
+	}
+
+	unitPrice, err := ioutils.ParseRat(position.Text("UNITPRICE"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse unit price of position %s", assetId)
+	}
+
+	return &asset.Asset{
+		Id:        assetId,
+		UnitPrice: unitPrice,
+	}, nil
+}
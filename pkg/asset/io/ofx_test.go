@@ -0,0 +1,34 @@
+package io_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/asset/io"
+	"math/big"
+	"testing"
+)
+
+// TestLiveAssetOfxLoader_Load is a smoke-test for the position prices of an SGML based OFX 1.x investment statement.
+func TestLiveAssetOfxLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	ctx := asset.Context{}
+	loader := io.LiveAssetOfxLoader{Path: "../../../test/data/io/ofx/smoke.ofx"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ctx.Assets))
+	assert.Equal(t, map[string]*big.Rat{"AAPL": big.NewRat(209, 4), "VOO": big.NewRat(5001, 10)}, ctx.GetAssetKeyPriceMap())
+}
+
+// TestLiveAssetOfxLoader_Load_V2 tests loading the position prices of an XML based OFX 2.x investment statement.
+func TestLiveAssetOfxLoader_Load_V2(t *testing.T) {
+	t.Parallel()
+
+	ctx := asset.Context{}
+	loader := io.LiveAssetOfxLoader{Path: "../../../test/data/io/ofx/smoke_v2.ofx"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*big.Rat{"IE00B4L5Y983": big.NewRat(361, 4)}, ctx.GetAssetKeyPriceMap())
+}
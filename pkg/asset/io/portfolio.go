@@ -0,0 +1,71 @@
+package io
+
+import (
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/portfolio"
+	"github.com/wlachs/wstonks/pkg/transaction"
+)
+
+var (
+	_ LiveAssetWriter = LiveAssetJsonWriter{}
+	_ LiveAssetWriter = LiveAssetYamlWriter{}
+)
+
+// LiveAssetJsonLoader implements the LiveAssetLoader interface to allow importing context data from a JSON portfolio document.
+type LiveAssetJsonLoader struct {
+	Path string
+}
+
+// LiveAssetYamlLoader implements the LiveAssetLoader interface to allow importing context data from a YAML portfolio document.
+type LiveAssetYamlLoader struct {
+	Path string
+}
+
+// LiveAssetJsonWriter implements the LiveAssetWriter interface to allow exporting the context data to a JSON portfolio document. Since a
+// portfolio document holds the transactions as well, they can be written to the same document by setting Transactions.
+type LiveAssetJsonWriter struct {
+	Path         string
+	Transactions *transaction.Context
+}
+
+// LiveAssetYamlWriter implements the LiveAssetWriter interface to allow exporting the context data to a YAML portfolio document. Since a
+// portfolio document holds the transactions as well, they can be written to the same document by setting Transactions.
+type LiveAssetYamlWriter struct {
+	Path         string
+	Transactions *transaction.Context
+}
+
+// Load tries to parse the JSON portfolio document at Path and loads the assets into the context.
+func (l LiveAssetJsonLoader) Load(ctx *asset.Context) error {
+	return loadPortfolio(ctx, l.Path, portfolio.JSON)
+}
+
+// Load tries to parse the YAML portfolio document at Path and loads the assets into the context.
+func (l LiveAssetYamlLoader) Load(ctx *asset.Context) error {
+	return loadPortfolio(ctx, l.Path, portfolio.YAML)
+}
+
+// loadPortfolio reads the portfolio document in the given format and adds its assets to the context.
+func loadPortfolio(ctx *asset.Context, path string, format portfolio.Format) error {
+	d, err := portfolio.ReadFile(path, format)
+	if err != nil {
+		return err
+	}
+
+	a, err := d.GetAssets()
+	if err != nil {
+		return err
+	}
+
+	return ctx.AddAssets(a)
+}
+
+// Write creates the JSON portfolio document at Path from the context and the optional Transactions.
+func (w LiveAssetJsonWriter) Write(ctx *asset.Context) error {
+	return portfolio.JsonWriter{Path: w.Path}.Write(w.Transactions, ctx)
+}
+
+// Write creates the YAML portfolio document at Path from the context and the optional Transactions.
+func (w LiveAssetYamlWriter) Write(ctx *asset.Context) error {
+	return portfolio.YamlWriter{Path: w.Path}.Write(w.Transactions, ctx)
+}
@@ -0,0 +1,11 @@
+package io
+
+import (
+	"github.com/wlachs/wstonks/pkg/asset"
+)
+
+// LiveAssetWriter interface to allow exporting the context data of asset.Context.
+type LiveAssetWriter interface {
+	// Write saves the context data to an arbitrary destination.
+	Write(ctx *asset.Context) error
+}
@@ -6,4 +6,21 @@ import "math/big"
 type Asset struct {
 	Id        string
 	UnitPrice *big.Rat
+	// Name is the human-readable name of the asset.
+	Name string
+	// Currency is the ISO 4217 code of the currency the unit price is quoted in.
+	Currency string
+	// Tags can be used to group assets, e.g. by asset class or region.
+	Tags []string
+}
+
+// Clone copies the Asset and creates a new *big.Rat instance for the unit price, so the copy can be modified independently.
+func (a *Asset) Clone() *Asset {
+	c := *a
+	if a.UnitPrice != nil {
+		c.UnitPrice = big.NewRat(0, 1).Set(a.UnitPrice)
+	}
+	c.Tags = append([]string(nil), a.Tags...)
+
+	return &c
 }
@@ -4,6 +4,9 @@ import "math/big"
 
 // GetAssetKeyPriceMap creates a map mapping quantities to their respective live unit prices.
 func (ctx *Context) GetAssetKeyPriceMap() map[string]*big.Rat {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
 	m := map[string]*big.Rat{}
 
 	for _, asset := range ctx.Assets {
@@ -0,0 +1,59 @@
+package calculation_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	assetio "github.com/wlachs/wstonks/pkg/asset/io"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	txio "github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+// TestContext_Concurrent_Calculations runs calculations on a shared portfolio while prices are updated. Run with -race to detect
+// unsynchronized access.
+func TestContext_Concurrent_Calculations(t *testing.T) {
+	t.Parallel()
+
+	txCtx := transaction.Context{}
+	assert.Nil(t, txio.TxCsvLoader{Path: "../../test/data/io/transactions/smoke_sales.csv"}.Load(&txCtx))
+
+	assetCtx := asset.Context{}
+	assert.Nil(t, assetio.LiveAssetCsvLoader{Path: "../../test/data/io/assets/smoke_sales.csv"}.Load(&assetCtx))
+
+	ctx := calculation.Context{AssetContext: &assetCtx, TransactionContext: &txCtx}
+	order := assetCtx.GetAssets()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			for _, a := range order {
+				price := big.NewRat(0, 1).Add(a.UnitPrice, big.NewRat(int64(i%3), 100))
+				assert.Nil(t, assetCtx.AddAsset(&asset.Asset{Id: a.Id, UnitPrice: price}))
+			}
+		}
+	}()
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				_, _ = ctx.GetAssetWorth()
+				_, _ = ctx.GetSalesForReturn(big.NewRat(1, 1))
+				_, _, _ = ctx.GetMaxProfitAndLoss()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	/* Calculations must not reorder the assets of the context. */
+	for i, a := range assetCtx.GetAssets() {
+		assert.Equal(t, order[i].Id, a.Id)
+	}
+}
@@ -0,0 +1,160 @@
+package calculation
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math"
+	"math/big"
+	"time"
+)
+
+// DividendSummary holds the dividend income of an asset or of the whole portfolio.
+type DividendSummary struct {
+	// TotalIncome is the sum of all dividends paid up to the reference time.
+	TotalIncome *big.Rat
+	// TrailingIncome is the sum of the dividends paid in the 12 months up to the reference time.
+	TrailingIncome *big.Rat
+	// TrailingYield is the trailing income divided by the current worth. It is nil if nothing is held.
+	TrailingYield *big.Rat
+	// YieldOnCost is the trailing income divided by the initial worth of the open positions. It is nil if nothing is held.
+	YieldOnCost *big.Rat
+	// GrowthRate is the compound annual growth rate of the dividend income from the first year with dividends to the last complete
+	// calendar year before the reference time. It is NaN if there are less than two such years.
+	GrowthRate float64
+}
+
+// GetDividendSummaryMap calculates the DividendSummary of every asset of the asset.Context held or paid dividends for, based on the live
+// asset values and the dividends paid up to the given time.
+func (ctx *Context) GetDividendSummaryMap(asOf time.Time) (map[*asset.Asset]*DividendSummary, error) {
+	assetCtx := ctx.AssetContext
+	txCtx := ctx.TransactionContext
+
+	if assetCtx == nil || txCtx == nil {
+		return nil, fmt.Errorf("asset or transaction context is missing")
+	}
+
+	worthMap, err := ctx.GetAssetWorthMap()
+	if err != nil {
+		return nil, err
+	}
+
+	yearly := txCtx.GetAssetDividendsByYear()
+	calendar := txCtx.GetDividendCalendar(time.Time{}, asOf)
+	total := sumDividends(calendar, time.Time{})
+	trailing := sumDividends(calendar, trailingStart(asOf))
+	m := map[*asset.Asset]*DividendSummary{}
+
+	for _, a := range assetCtx.GetAssets() {
+		txAsset, ok := txCtx.GetAsset(a.Id)
+		if !ok {
+			continue
+		}
+
+		m[a] = newDividendSummary(
+			zeroIfNil(total[txAsset]),
+			zeroIfNil(trailing[txAsset]),
+			worthMap[a],
+			txCtx.GetAssetInitialWorth(txAsset),
+			yearly[txAsset],
+			asOf,
+		)
+	}
+
+	return m, nil
+}
+
+// sumDividends sums up the dividends of the calendar paid from the given time on per asset. A zero from sums up all dividends.
+func sumDividends(calendar []transaction.DividendPayment, from time.Time) map[*transaction.TxAsset]*big.Rat {
+	m := map[*transaction.TxAsset]*big.Rat{}
+	for _, p := range calendar {
+		if p.Timestamp.Before(from) {
+			continue
+		}
+
+		if m[p.Asset] == nil {
+			m[p.Asset] = big.NewRat(0, 1)
+		}
+		m[p.Asset].Add(m[p.Asset], p.Amount)
+	}
+
+	return m
+}
+
+// GetDividendSummary calculates the DividendSummary of all assets of the asset.Context combined, based on the live asset values and the
+// dividends paid up to the given time.
+func (ctx *Context) GetDividendSummary(asOf time.Time) (*DividendSummary, error) {
+	summaries, err := ctx.GetDividendSummaryMap(asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	total, trailing := big.NewRat(0, 1), big.NewRat(0, 1)
+	worth, initial := big.NewRat(0, 1), big.NewRat(0, 1)
+	yearly := map[int]*big.Rat{}
+	worthMap, err := ctx.GetAssetWorthMap()
+	if err != nil {
+		return nil, err
+	}
+
+	assetYearly := ctx.TransactionContext.GetAssetDividendsByYear()
+	for a, s := range summaries {
+		total.Add(total, s.TotalIncome)
+		trailing.Add(trailing, s.TrailingIncome)
+		worth.Add(worth, worthMap[a])
+
+		txAsset, _ := ctx.TransactionContext.GetAsset(a.Id)
+		initial.Add(initial, ctx.TransactionContext.GetAssetInitialWorth(txAsset))
+		for year, income := range assetYearly[txAsset] {
+			if yearly[year] == nil {
+				yearly[year] = big.NewRat(0, 1)
+			}
+			yearly[year].Add(yearly[year], income)
+		}
+	}
+
+	return newDividendSummary(total, trailing, worth, initial, yearly, asOf), nil
+}
+
+// newDividendSummary calculates the yields and the growth rate of the dividend income.
+func newDividendSummary(total *big.Rat, trailing *big.Rat, worth *big.Rat, initial *big.Rat, yearly map[int]*big.Rat, asOf time.Time) *DividendSummary {
+	s := &DividendSummary{TotalIncome: total, TrailingIncome: trailing, GrowthRate: dividendGrowthRate(yearly, asOf.Year()-1)}
+
+	if worth != nil && worth.Sign() != 0 {
+		s.TrailingYield = big.NewRat(0, 1).Quo(trailing, worth)
+	}
+
+	if initial.Sign() != 0 {
+		s.YieldOnCost = big.NewRat(0, 1).Quo(trailing, initial)
+	}
+
+	return s
+}
+
+// trailingStart returns the start of the 12 months before the given time. The start itself is excluded from the period.
+func trailingStart(asOf time.Time) time.Time {
+	return asOf.AddDate(-1, 0, 0).Add(time.Nanosecond)
+}
+
+// dividendGrowthRate calculates the compound annual growth rate of the yearly dividend income from the first year with income up to and
+// including the last year.
+func dividendGrowthRate(yearly map[int]*big.Rat, last int) float64 {
+	first := math.MaxInt
+	for year, income := range yearly {
+		if year <= last && income.Sign() > 0 {
+			first = min(first, year)
+		}
+	}
+
+	if first >= last {
+		return math.NaN()
+	}
+
+	end := 0.0
+	if income, ok := yearly[last]; ok {
+		end, _ = income.Float64()
+	}
+	start, _ := yearly[first].Float64()
+
+	return math.Pow(end/start, 1/float64(last-first)) - 1
+}
@@ -0,0 +1,101 @@
+package calculation_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// dividendTx creates a transaction of the asset on the given day.
+func dividendTx(id string, txType transaction.TxType, year int, month time.Month, day int, quantity int64, unitPrice int64) transaction.Tx {
+	return transaction.Tx{
+		Position: transaction.Position{
+			Asset:     &transaction.TxAsset{Id: id},
+			Timestamp: time.Date(year, month, day, 0, 0, 0, 0, time.UTC),
+			Quantity:  big.NewRat(quantity, 1),
+			UnitPrice: big.NewRat(unitPrice, 1),
+		},
+		Type: txType,
+	}
+}
+
+// dividendContext creates a Context holding 10 units of "A" bought for 100 and now worth 125, paying growing dividends, and 5 units of
+// "B" without dividends.
+func dividendContext(t *testing.T) (*calculation.Context, *asset.Asset, *asset.Asset) {
+	txCtx := transaction.Context{}
+	assert.NoError(t, txCtx.AddTransactions([]transaction.Tx{
+		dividendTx("A", transaction.BUY, 2021, time.January, 4, 10, 100),
+		dividendTx("A", transaction.DIVIDEND, 2021, time.June, 1, 1, 20),
+		dividendTx("A", transaction.DIVIDEND, 2022, time.June, 1, 1, 22),
+		dividendTx("A", transaction.DIVIDEND, 2023, time.June, 1, 1, 25),
+		dividendTx("A", transaction.DIVIDEND, 2023, time.December, 1, 1, 20),
+		dividendTx("A", transaction.DIVIDEND, 2024, time.June, 1, 1, 30),
+		dividendTx("B", transaction.BUY, 2023, time.January, 4, 5, 40),
+	}))
+
+	assetCtx := asset.Context{}
+	a := &asset.Asset{Id: "A", UnitPrice: big.NewRat(125, 1)}
+	b := &asset.Asset{Id: "B", UnitPrice: big.NewRat(50, 1)}
+	assert.NoError(t, assetCtx.AddAssets([]*asset.Asset{a, b}))
+
+	return &calculation.Context{AssetContext: &assetCtx, TransactionContext: &txCtx}, a, b
+}
+
+// TestContext_GetDividendSummaryMap checks the trailing yield, the yield on cost and the growth rate per asset.
+func TestContext_GetDividendSummaryMap(t *testing.T) {
+	t.Parallel()
+
+	ctx, a, b := dividendContext(t)
+	m, err := ctx.GetDividendSummaryMap(time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Len(t, m, 2)
+
+	s := m[a]
+	assert.Equal(t, big.NewRat(117, 1), s.TotalIncome)
+	assert.Equal(t, big.NewRat(50, 1), s.TrailingIncome)
+	assert.Equal(t, big.NewRat(50, 1250), s.TrailingYield)
+	assert.Equal(t, big.NewRat(50, 1000), s.YieldOnCost)
+	// (45 / 20)^(1/2) - 1
+	assert.InDelta(t, 0.5, s.GrowthRate, 1e-12)
+
+	assert.Equal(t, 0, m[b].TrailingIncome.Sign())
+	assert.Equal(t, 0, m[b].TrailingYield.Sign())
+	assert.True(t, math.IsNaN(m[b].GrowthRate))
+}
+
+// TestContext_GetDividendSummaryMap_Trailing checks that the trailing period covers exactly the 12 months before the reference time.
+func TestContext_GetDividendSummaryMap_Trailing(t *testing.T) {
+	t.Parallel()
+
+	ctx, a, _ := dividendContext(t)
+	m, err := ctx.GetDividendSummaryMap(time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(50, 1), m[a].TrailingIncome)
+	assert.Equal(t, big.NewRat(117, 1), m[a].TotalIncome)
+
+	m, err = ctx.GetDividendSummaryMap(time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(45, 1), m[a].TrailingIncome)
+	assert.Equal(t, big.NewRat(87, 1), m[a].TotalIncome)
+}
+
+// TestContext_GetDividendSummary checks the dividend summary of the whole portfolio.
+func TestContext_GetDividendSummary(t *testing.T) {
+	t.Parallel()
+
+	ctx, _, _ := dividendContext(t)
+	s, err := ctx.GetDividendSummary(time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(117, 1), s.TotalIncome)
+	assert.Equal(t, big.NewRat(50, 1500), s.TrailingYield)
+	assert.Equal(t, big.NewRat(50, 1200), s.YieldOnCost)
+	assert.InDelta(t, 0.5, s.GrowthRate, 1e-12)
+
+	_, err = (&calculation.Context{}).GetDividendSummary(time.Now())
+	assert.Error(t, err)
+}
@@ -0,0 +1,175 @@
+package calculation
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"slices"
+	"time"
+)
+
+// DividendEstimate replaces the dividend history of an asset in a dividend forecast with an expected dividend per share.
+type DividendEstimate struct {
+	AssetId string
+	// PerShare is the expected dividend per share of every payment.
+	PerShare *big.Rat
+	// Months lists the months of the year the dividend is paid in. If empty, the months the asset paid dividends in during the last 12
+	// months are used.
+	Months []time.Month
+}
+
+// DividendForecast holds the projected dividend income of the 12 months after the reference time.
+type DividendForecast struct {
+	// Assets holds the projected income of every asset ID per month. Assets without projected income are not listed.
+	Assets map[string]map[transaction.YearMonth]*big.Rat
+	// Monthly holds the projected income of all assets per month.
+	Monthly map[transaction.YearMonth]*big.Rat
+	Total   *big.Rat
+}
+
+// ForecastDividends projects the dividend income of the currently held quantities for the 12 months after the given time. By default, every
+// dividend paid in the 12 months before is expected to be paid again a year later, scaled by the change of the held quantity since the
+// payment. Assets with an estimate are projected with the estimated dividend per share instead.
+func (ctx *Context) ForecastDividends(asOf time.Time, estimates []DividendEstimate) (*DividendForecast, error) {
+	txCtx := ctx.TransactionContext
+	if txCtx == nil {
+		return nil, fmt.Errorf("transaction context not set")
+	}
+
+	byAsset := map[string]DividendEstimate{}
+	for _, e := range estimates {
+		if err := validateDividendEstimate(e); err != nil {
+			return nil, err
+		}
+
+		byAsset[e.AssetId] = e
+	}
+
+	trailing := txCtx.GetDividendCalendar(trailingStart(asOf), asOf)
+	f := &DividendForecast{
+		Assets:  map[string]map[transaction.YearMonth]*big.Rat{},
+		Monthly: map[transaction.YearMonth]*big.Rat{},
+		Total:   big.NewRat(0, 1),
+	}
+
+	for a, quantity := range txCtx.GetAssetMap() {
+		var payments []projectedPayment
+		var err error
+		if e, ok := byAsset[a.Id]; ok {
+			payments, err = estimatedPayments(e, quantity, trailing, asOf)
+		} else {
+			payments = projectedPayments(txCtx, a, quantity, trailing)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range payments {
+			f.add(a.Id, p)
+		}
+	}
+
+	return f, nil
+}
+
+// projectedPayment is a projected dividend payment of a forecast.
+type projectedPayment struct {
+	Month  transaction.YearMonth
+	Amount *big.Rat
+}
+
+// add adds the projected payment of the asset to the forecast.
+func (f *DividendForecast) add(assetId string, p projectedPayment) {
+	if p.Amount.Sign() == 0 {
+		return
+	}
+
+	if f.Assets[assetId] == nil {
+		f.Assets[assetId] = map[transaction.YearMonth]*big.Rat{}
+	}
+
+	for _, m := range []map[transaction.YearMonth]*big.Rat{f.Assets[assetId], f.Monthly} {
+		if m[p.Month] == nil {
+			m[p.Month] = big.NewRat(0, 1)
+		}
+		m[p.Month].Add(m[p.Month], p.Amount)
+	}
+
+	f.Total.Add(f.Total, p.Amount)
+}
+
+// projectedPayments repeats the dividends paid for the asset in the trailing 12 months a year later, scaled by the ratio of the current
+// quantity and the quantity held just before the payment, which the payment was made for. Units bought with a reinvested dividend are
+// booked at the time of the payment, so they are not part of that quantity. Payments made while holding nothing are repeated unchanged.
+func projectedPayments(txCtx *transaction.Context, a *transaction.TxAsset, quantity *big.Rat, trailing []transaction.DividendPayment) []projectedPayment {
+	var payments []projectedPayment
+	for _, p := range trailing {
+		if p.Asset != a {
+			continue
+		}
+
+		amount := big.NewRat(0, 1).Set(p.Amount)
+		if held := txCtx.GetAssetQuantityAt(a, p.Timestamp.Add(-time.Nanosecond)); held.Sign() > 0 {
+			amount.Mul(amount, quantity)
+			amount.Quo(amount, held)
+		}
+
+		next := p.Timestamp.AddDate(1, 0, 0)
+		payments = append(payments, projectedPayment{Month: transaction.YearMonth{Year: next.Year(), Month: next.Month()}, Amount: amount})
+	}
+
+	return payments
+}
+
+// estimatedPayments calculates the payments of the held quantity with the estimated dividend per share in the 12 months after the given
+// time. Every month is paid once, even if the asset paid several dividends in it.
+func estimatedPayments(e DividendEstimate, quantity *big.Rat, trailing []transaction.DividendPayment, asOf time.Time) ([]projectedPayment, error) {
+	months := slices.Clone(e.Months)
+	if len(months) == 0 {
+		for _, p := range trailing {
+			if p.Asset.Id == e.AssetId {
+				months = append(months, p.Timestamp.Month())
+			}
+		}
+	}
+
+	slices.Sort(months)
+	months = slices.Compact(months)
+
+	if len(months) == 0 {
+		return nil, fmt.Errorf("no payment months known for %s", e.AssetId)
+	}
+
+	amount := big.NewRat(0, 1).Mul(e.PerShare, quantity)
+	payments := make([]projectedPayment, 0, len(months))
+	for _, m := range months {
+		year := asOf.Year()
+		if m <= asOf.Month() {
+			year++
+		}
+
+		payments = append(payments, projectedPayment{Month: transaction.YearMonth{Year: year, Month: m}, Amount: amount})
+	}
+
+	return payments, nil
+}
+
+// validateDividendEstimate checks that the estimate has an asset, a non-negative dividend and valid months.
+func validateDividendEstimate(e DividendEstimate) error {
+	if e.AssetId == "" {
+		return fmt.Errorf("missing asset ID of dividend estimate")
+	}
+
+	if e.PerShare == nil || e.PerShare.Sign() < 0 {
+		return fmt.Errorf("dividend estimate of %s should not be negative", e.AssetId)
+	}
+
+	for _, m := range e.Months {
+		if m < time.January || m > time.December {
+			return fmt.Errorf("invalid payment month %d of %s", m, e.AssetId)
+		}
+	}
+
+	return nil
+}
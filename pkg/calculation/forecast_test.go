@@ -0,0 +1,99 @@
+package calculation_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// forecastContext creates a Context holding 20 units of "A", which doubled its holding after the first quarterly dividend of 2024, and a
+// sold-out asset "B".
+func forecastContext(t *testing.T) *calculation.Context {
+	txCtx := transaction.Context{}
+	assert.NoError(t, txCtx.AddTransactions([]transaction.Tx{
+		dividendTx("A", transaction.BUY, 2023, time.January, 4, 10, 100),
+		dividendTx("A", transaction.DIVIDEND, 2023, time.June, 1, 1, 8),
+		dividendTx("A", transaction.DIVIDEND, 2024, time.March, 1, 1, 5),
+		dividendTx("A", transaction.BUY, 2024, time.March, 10, 10, 100),
+		dividendTx("A", transaction.DIVIDEND, 2024, time.June, 1, 1, 10),
+		dividendTx("B", transaction.BUY, 2023, time.January, 4, 5, 40),
+		dividendTx("B", transaction.DIVIDEND, 2024, time.February, 1, 1, 7),
+		dividendTx("B", transaction.SELL, 2024, time.February, 2, 5, 40),
+	}))
+
+	return &calculation.Context{TransactionContext: &txCtx}
+}
+
+// TestContext_ForecastDividends checks that the dividends of the last 12 months are projected with the current quantity.
+func TestContext_ForecastDividends(t *testing.T) {
+	t.Parallel()
+
+	ctx := forecastContext(t)
+	f, err := ctx.ForecastDividends(time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC), nil)
+	assert.NoError(t, err)
+
+	march := transaction.YearMonth{Year: 2025, Month: time.March}
+	june := transaction.YearMonth{Year: 2025, Month: time.June}
+	assert.Equal(t, map[string]map[transaction.YearMonth]*big.Rat{
+		"A": {march: big.NewRat(10, 1), june: big.NewRat(10, 1)},
+	}, f.Assets)
+	assert.Equal(t, big.NewRat(10, 1), f.Monthly[march])
+	assert.Equal(t, big.NewRat(20, 1), f.Total)
+}
+
+// TestContext_ForecastDividends_Estimates checks that estimates replace the dividend history of an asset.
+func TestContext_ForecastDividends_Estimates(t *testing.T) {
+	t.Parallel()
+
+	ctx := forecastContext(t)
+	asOf := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+	f, err := ctx.ForecastDividends(asOf, []calculation.DividendEstimate{
+		{AssetId: "A", PerShare: big.NewRat(1, 4), Months: []time.Month{time.March, time.September}},
+		{AssetId: "B", PerShare: big.NewRat(1, 1)},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[transaction.YearMonth]*big.Rat{
+		{Year: 2024, Month: time.September}: big.NewRat(5, 1),
+		{Year: 2025, Month: time.March}:     big.NewRat(5, 1),
+	}, f.Assets["A"])
+	assert.Equal(t, big.NewRat(10, 1), f.Total)
+
+	f, err = ctx.ForecastDividends(asOf, []calculation.DividendEstimate{{AssetId: "A", PerShare: big.NewRat(1, 1)}})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(40, 1), f.Total)
+
+	_, err = ctx.ForecastDividends(asOf, []calculation.DividendEstimate{{AssetId: "A", PerShare: big.NewRat(-1, 1)}})
+	assert.Error(t, err)
+
+	_, err = ctx.ForecastDividends(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), []calculation.DividendEstimate{{AssetId: "A", PerShare: big.NewRat(1, 1)}})
+	assert.Error(t, err, "no payment months are known")
+}
+
+// TestContext_ForecastDividends_Reinvested checks that reinvested units are not part of the quantity a dividend was paid for, and that
+// estimates are paid once per month even if the asset paid several dividends in a month.
+func TestContext_ForecastDividends_Reinvested(t *testing.T) {
+	t.Parallel()
+
+	txCtx := transaction.Context{}
+	assert.NoError(t, txCtx.AddTransactions([]transaction.Tx{
+		dividendTx("A", transaction.BUY, 2024, time.January, 2, 10, 100),
+		dividendTx("A", transaction.REINVEST, 2024, time.April, 1, 1, 10),
+		dividendTx("A", transaction.DIVIDEND, 2024, time.April, 15, 1, 2),
+	}))
+	ctx := &calculation.Context{TransactionContext: &txCtx}
+	asOf := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+	april := transaction.YearMonth{Year: 2025, Month: time.April}
+
+	f, err := ctx.ForecastDividends(asOf, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(13, 1), f.Monthly[april], "the reinvested dividend of 10 for 10 units should be scaled to 11 units")
+
+	f, err = ctx.ForecastDividends(asOf, []calculation.DividendEstimate{{AssetId: "A", PerShare: big.NewRat(1, 1)}})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(11, 1), f.Monthly[april])
+	assert.Equal(t, big.NewRat(11, 1), f.Total)
+}
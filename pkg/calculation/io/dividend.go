@@ -0,0 +1,79 @@
+package io
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/portfolio"
+	"gopkg.in/yaml.v3"
+	"io"
+	"time"
+)
+
+// DividendEstimateJsonLoader implements the DividendEstimateLoader interface to allow loading dividend estimates from a JSON file.
+type DividendEstimateJsonLoader struct {
+	Path string
+}
+
+// DividendEstimateYamlLoader implements the DividendEstimateLoader interface to allow loading dividend estimates from a YAML file.
+type DividendEstimateYamlLoader struct {
+	Path string
+}
+
+// dividendFile is the serialized form of a payment schedule. The months are optional, e.g.
+//
+//	dividends:
+//	  - asset: A
+//	    perShare: "0.25"
+//	    months: [3, 6, 9, 12]
+//	  - asset: B
+//	    perShare: "1.1"
+type dividendFile struct {
+	Dividends []dividendEntry `json:"dividends" yaml:"dividends"`
+}
+
+// dividendEntry is the serialized form of a calculation.DividendEstimate.
+type dividendEntry struct {
+	Asset    string        `json:"asset" yaml:"asset"`
+	PerShare portfolio.Rat `json:"perShare" yaml:"perShare"`
+	Months   []int         `json:"months,omitempty" yaml:"months,omitempty"`
+}
+
+// Load tries to parse the JSON file at Path and returns the dividend estimates it defines.
+func (l DividendEstimateJsonLoader) Load() ([]calculation.DividendEstimate, error) {
+	return loadDividendEstimates(l.Path, func(r io.Reader, f *dividendFile) error {
+		return json.NewDecoder(r).Decode(f)
+	})
+}
+
+// Load tries to parse the YAML file at Path and returns the dividend estimates it defines.
+func (l DividendEstimateYamlLoader) Load() ([]calculation.DividendEstimate, error) {
+	return loadDividendEstimates(l.Path, func(r io.Reader, f *dividendFile) error {
+		return yaml.NewDecoder(r).Decode(f)
+	})
+}
+
+// loadDividendEstimates reads the payment schedule at the given path with the given decoder and converts it to
+// calculation.DividendEstimate objects.
+func loadDividendEstimates(path string, decode func(r io.Reader, f *dividendFile) error) ([]calculation.DividendEstimate, error) {
+	file, err := decodeFile(path, "dividend", decode)
+	if err != nil {
+		return nil, err
+	}
+
+	estimates := make([]calculation.DividendEstimate, 0, len(file.Dividends))
+	for i, entry := range file.Dividends {
+		if entry.Asset == "" || entry.PerShare.Rat == nil {
+			return nil, fmt.Errorf("dividend %d needs an asset and a dividend per share", i)
+		}
+
+		e := calculation.DividendEstimate{AssetId: entry.Asset, PerShare: entry.PerShare.Rat}
+		for _, m := range entry.Months {
+			e.Months = append(e.Months, time.Month(m))
+		}
+
+		estimates = append(estimates, e)
+	}
+
+	return estimates, nil
+}
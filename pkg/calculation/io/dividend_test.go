@@ -0,0 +1,47 @@
+package io_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/calculation/io"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// expectedDividendEstimates holds the dividend estimates defined by the smoke-test files.
+var expectedDividendEstimates = []calculation.DividendEstimate{
+	{AssetId: "A", PerShare: big.NewRat(1, 4), Months: []time.Month{time.March, time.June, time.September, time.December}},
+	{AssetId: "B", PerShare: big.NewRat(11, 10)},
+}
+
+// TestDividendEstimateJsonLoader_Load is a smoke-test for a well-formatted JSON payment schedule.
+func TestDividendEstimateJsonLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	estimates, err := io.DividendEstimateJsonLoader{Path: "../../../test/data/io/dividends/smoke.json"}.Load()
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedDividendEstimates, estimates)
+}
+
+// TestDividendEstimateYamlLoader_Load is a smoke-test for a well-formatted YAML payment schedule.
+func TestDividendEstimateYamlLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	estimates, err := io.DividendEstimateYamlLoader{Path: "../../../test/data/io/dividends/smoke.yaml"}.Load()
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedDividendEstimates, estimates)
+}
+
+// TestDividendEstimateYamlLoader_Load_Invalid checks that entries without dividend per share and missing files are rejected.
+func TestDividendEstimateYamlLoader_Load_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := io.DividendEstimateYamlLoader{Path: "../../../test/data/io/dividends/missing_amount.yaml"}.Load()
+	assert.Error(t, err)
+
+	_, err = io.DividendEstimateYamlLoader{Path: "../../../test/data/io/dividends/###.yaml"}.Load()
+	assert.Error(t, err)
+}
@@ -0,0 +1,29 @@
+package io
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// decodeFile opens the file at the given path and decodes its content with the given decoder. The kind of the file is used in error
+// messages, e.g. "scenario".
+func decodeFile[T any](path string, kind string, decode func(r io.Reader, v *T) error) (v T, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return v, fmt.Errorf("failed to open file \"%s\"", path)
+	}
+
+	defer func(f *os.File) {
+		cerr := f.Close()
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+	}(f)
+
+	if err = decode(f, &v); err != nil {
+		return v, fmt.Errorf("failed to decode %s file: %w", kind, err)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,17 @@
+package io
+
+import (
+	"github.com/wlachs/wstonks/pkg/calculation"
+)
+
+// ScenarioLoader interface to allow loading stress-test scenarios.
+type ScenarioLoader interface {
+	// Load loads scenarios from an arbitrary source.
+	Load() ([]calculation.Scenario, error)
+}
+
+// DividendEstimateLoader interface to allow loading dividend estimates and payment schedules.
+type DividendEstimateLoader interface {
+	// Load loads dividend estimates from an arbitrary source.
+	Load() ([]calculation.DividendEstimate, error)
+}
@@ -0,0 +1,105 @@
+package io
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/portfolio"
+	"gopkg.in/yaml.v3"
+	"io"
+)
+
+// ScenarioJsonLoader implements the ScenarioLoader interface to allow loading scenarios from a JSON file.
+type ScenarioJsonLoader struct {
+	Path string
+}
+
+// ScenarioYamlLoader implements the ScenarioLoader interface to allow loading scenarios from a YAML file.
+type ScenarioYamlLoader struct {
+	Path string
+}
+
+// scenarioFile is the serialized form of a list of scenarios. Every shock holds either a percentage or an absolute price change, e.g.
+//
+//	scenarios:
+//	  - name: equity crash
+//	    shocks:
+//	      - tag: equity
+//	        percent: "-30"
+//	      - tag: bond
+//	        percent: "-10"
+type scenarioFile struct {
+	Scenarios []scenarioEntry `json:"scenarios" yaml:"scenarios"`
+}
+
+// scenarioEntry is the serialized form of a calculation.Scenario.
+type scenarioEntry struct {
+	Name   string       `json:"name" yaml:"name"`
+	Shocks []shockEntry `json:"shocks" yaml:"shocks"`
+}
+
+// shockEntry is the serialized form of a calculation.Shock.
+type shockEntry struct {
+	Asset    string        `json:"asset,omitempty" yaml:"asset,omitempty"`
+	Tag      string        `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Percent  portfolio.Rat `json:"percent,omitempty" yaml:"percent,omitempty"`
+	Absolute portfolio.Rat `json:"absolute,omitempty" yaml:"absolute,omitempty"`
+}
+
+// Load tries to parse the JSON file at Path and returns the scenarios it defines.
+func (l ScenarioJsonLoader) Load() ([]calculation.Scenario, error) {
+	return loadScenarios(l.Path, func(r io.Reader, f *scenarioFile) error {
+		return json.NewDecoder(r).Decode(f)
+	})
+}
+
+// Load tries to parse the YAML file at Path and returns the scenarios it defines.
+func (l ScenarioYamlLoader) Load() ([]calculation.Scenario, error) {
+	return loadScenarios(l.Path, func(r io.Reader, f *scenarioFile) error {
+		return yaml.NewDecoder(r).Decode(f)
+	})
+}
+
+// loadScenarios reads the scenario file at the given path with the given decoder and converts it to calculation.Scenario objects.
+func loadScenarios(path string, decode func(r io.Reader, f *scenarioFile) error) ([]calculation.Scenario, error) {
+	file, err := decodeFile(path, "scenario", decode)
+	if err != nil {
+		return nil, err
+	}
+
+	scenarios := make([]calculation.Scenario, 0, len(file.Scenarios))
+	for _, entry := range file.Scenarios {
+		s, entryErr := readScenario(entry)
+		if entryErr != nil {
+			return nil, entryErr
+		}
+
+		scenarios = append(scenarios, s)
+	}
+
+	return scenarios, nil
+}
+
+// readScenario converts a single scenario of the file to a calculation.Scenario.
+func readScenario(entry scenarioEntry) (calculation.Scenario, error) {
+	if entry.Name == "" {
+		return calculation.Scenario{}, fmt.Errorf("missing scenario name")
+	}
+
+	s := calculation.Scenario{Name: entry.Name, Shocks: make([]calculation.Shock, 0, len(entry.Shocks))}
+	for i, shock := range entry.Shocks {
+		if (shock.Percent.Rat == nil) == (shock.Absolute.Rat == nil) {
+			return calculation.Scenario{}, fmt.Errorf("shock %d of scenario \"%s\" needs either a percentage or an absolute value", i, entry.Name)
+		}
+
+		c := calculation.Shock{AssetId: shock.Asset, Tag: shock.Tag, Type: calculation.PERCENTAGE, Value: shock.Percent.Rat}
+		if shock.Absolute.Rat != nil {
+			c.Type = calculation.ABSOLUTE
+			c.Value = shock.Absolute.Rat
+		}
+
+		s.Shocks = append(s.Shocks, c)
+	}
+
+	return s, s.Validate()
+}
@@ -0,0 +1,66 @@
+package io_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/calculation/io"
+	"math/big"
+	"testing"
+)
+
+// expectedScenarios holds the scenarios defined by the smoke-test files.
+var expectedScenarios = []calculation.Scenario{
+	{
+		Name: "equity crash",
+		Shocks: []calculation.Shock{
+			{Tag: "equity", Type: calculation.PERCENTAGE, Value: big.NewRat(-30, 1)},
+			{Tag: "bond", Type: calculation.PERCENTAGE, Value: big.NewRat(-10, 1)},
+		},
+	},
+	{
+		Name: "single asset",
+		Shocks: []calculation.Shock{
+			{AssetId: "A", Type: calculation.ABSOLUTE, Value: big.NewRat(-71234, 10000)},
+			{Type: calculation.PERCENTAGE, Value: big.NewRat(5, 1)},
+		},
+	},
+}
+
+// TestScenarioJsonLoader_Load is a smoke-test for a well-formatted JSON scenario file.
+func TestScenarioJsonLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	scenarios, err := io.ScenarioJsonLoader{Path: "../../../test/data/io/scenarios/smoke.json"}.Load()
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedScenarios, scenarios)
+}
+
+// TestScenarioYamlLoader_Load is a smoke-test for a well-formatted YAML scenario file.
+func TestScenarioYamlLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	scenarios, err := io.ScenarioYamlLoader{Path: "../../../test/data/io/scenarios/smoke.yaml"}.Load()
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedScenarios, scenarios)
+}
+
+// TestScenarioJsonLoader_Load_Ambiguous tests loading a shock with both a percentage and an absolute value.
+func TestScenarioJsonLoader_Load_Ambiguous(t *testing.T) {
+	t.Parallel()
+
+	_, err := io.ScenarioJsonLoader{Path: "../../../test/data/io/scenarios/ambiguous.json"}.Load()
+
+	assert.Equal(t, fmt.Errorf("shock 0 of scenario \"ambiguous\" needs either a percentage or an absolute value"), err)
+}
+
+// TestScenarioYamlLoader_Load_Missing_File tests loading a non-existing file.
+func TestScenarioYamlLoader_Load_Missing_File(t *testing.T) {
+	t.Parallel()
+
+	_, err := io.ScenarioYamlLoader{Path: "../../../test/data/io/scenarios/###.yaml"}.Load()
+
+	assert.Equal(t, fmt.Errorf("failed to open file \"../../../test/data/io/scenarios/###.yaml\""), err)
+}
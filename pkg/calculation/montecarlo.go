@@ -0,0 +1,235 @@
+package calculation
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/mathutils"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+)
+
+// MonteCarloAsset holds the assumptions about the future performance of an asset for a Monte Carlo projection.
+type MonteCarloAsset struct {
+	Id string
+	// ExpectedReturn is the expected simple return per period, e.g. 0.07 for 7%.
+	ExpectedReturn float64
+	// Volatility is the standard deviation of the logarithmic return per period, e.g. 0.15 for 15%.
+	Volatility float64
+}
+
+// MonteCarloConfig configures a Monte Carlo projection of the portfolio value. Periods are arbitrary, e.g. years or months, as long as
+// the return assumptions and the contribution refer to the same period.
+type MonteCarloConfig struct {
+	// Assets holds the assumptions for every asset held. Its order defines the rows and columns of Correlation.
+	Assets []MonteCarloAsset
+	// Correlation is the correlation matrix of the asset returns. If nil, the returns are uncorrelated.
+	Correlation [][]float64
+	// Periods is the number of periods to project.
+	Periods int
+	// Paths is the number of simulated paths.
+	Paths int
+	// Seed makes the projection deterministic: the same configuration and seed always yield the same result.
+	Seed uint64
+	// Contribution is paid into the portfolio at the end of every period and split according to the initial allocation. A negative value
+	// is a withdrawal, which is taken proportionally from the current holdings.
+	Contribution float64
+	// Percentiles lists the percentiles (0-100) of the portfolio value to report, e.g. 5, 50 and 95.
+	Percentiles []float64
+	// Target is the portfolio value whose probability of being reached at the end of the projection is reported.
+	Target float64
+}
+
+// MonteCarloResult holds the outcome of a Monte Carlo projection.
+type MonteCarloResult struct {
+	// Percentiles lists the reported percentiles in the order of MonteCarloConfig.Percentiles.
+	Percentiles []float64
+	// Paths holds a path for every reported percentile: Paths[i][t] is the Percentiles[i]-th percentile of the simulated portfolio values
+	// after t periods, where t = 0 is the current value.
+	Paths [][]float64
+	// Mean holds the mean of the simulated portfolio values after every period.
+	Mean []float64
+	// TargetProbability is the share of paths whose final value reaches MonteCarloConfig.Target.
+	TargetProbability float64
+}
+
+// ProjectMonteCarlo projects the value of the current holdings, as given by GetAssetWorthMap, with a Monte Carlo simulation. Every
+// period, correlated normally distributed logarithmic returns are drawn for the assets, the holdings grow accordingly and the contribution
+// is paid in or withdrawn. The values of a path never drop below zero.
+func (ctx *Context) ProjectMonteCarlo(config MonteCarloConfig) (*MonteCarloResult, error) {
+	if err := validateMonteCarloConfig(config); err != nil {
+		return nil, err
+	}
+
+	worthMap, err := ctx.GetAssetWorthMap()
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[string]int{}
+	for i, a := range config.Assets {
+		index[a.Id] = i
+	}
+
+	initial := make([]float64, len(config.Assets))
+	for a, w := range worthMap {
+		if w.Sign() == 0 {
+			continue
+		}
+
+		i, ok := index[a.Id]
+		if !ok {
+			return nil, fmt.Errorf("missing Monte Carlo assumptions for asset %s", a.Id)
+		}
+
+		initial[i], _ = w.Float64()
+	}
+
+	return projectMonteCarlo(config, initial)
+}
+
+// validateMonteCarloConfig verifies that the projection can be run with the configuration.
+func validateMonteCarloConfig(config MonteCarloConfig) error {
+	if len(config.Assets) == 0 {
+		return fmt.Errorf("missing Monte Carlo assumptions")
+	}
+
+	if config.Periods <= 0 || config.Paths <= 0 {
+		return fmt.Errorf("number of periods and paths should be positive")
+	}
+
+	for _, a := range config.Assets {
+		if a.Volatility < 0 || a.ExpectedReturn <= -1 {
+			return fmt.Errorf("invalid Monte Carlo assumptions for asset %s", a.Id)
+		}
+	}
+
+	for _, p := range config.Percentiles {
+		if p < 0 || p > 100 {
+			return fmt.Errorf("percentile %v out of range", p)
+		}
+	}
+
+	return nil
+}
+
+// projectMonteCarlo runs the simulation for the initial values of the assets of the configuration.
+func projectMonteCarlo(config MonteCarloConfig, initial []float64) (*MonteCarloResult, error) {
+	n := len(config.Assets)
+	correlation := config.Correlation
+	if correlation == nil {
+		correlation = mathutils.Identity(n)
+	}
+
+	if len(correlation) != n {
+		return nil, fmt.Errorf("correlation matrix should be %d×%d", n, n)
+	}
+
+	l, err := mathutils.Cholesky(correlation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid correlation matrix: %w", err)
+	}
+
+	/* Drift of the logarithmic return such that the expected simple return matches the assumption. */
+	drift := make([]float64, n)
+	for i, a := range config.Assets {
+		drift[i] = math.Log1p(a.ExpectedReturn) - a.Volatility*a.Volatility/2
+	}
+
+	weights := allocationWeights(initial)
+	rng := rand.New(rand.NewPCG(config.Seed, 0))
+
+	values := make([][]float64, config.Paths)
+	for p := range values {
+		values[p] = slices.Clone(initial)
+	}
+
+	result := &MonteCarloResult{
+		Percentiles: slices.Clone(config.Percentiles),
+		Paths:       make([][]float64, len(config.Percentiles)),
+		Mean:        make([]float64, 0, config.Periods+1),
+	}
+
+	totals := make([]float64, config.Paths)
+	z := make([]float64, n)
+
+	for t := 0; t <= config.Periods; t++ {
+		for p, v := range values {
+			if t > 0 {
+				for i := range z {
+					z[i] = rng.NormFloat64()
+				}
+
+				eps := mathutils.MulVec(l, z)
+				for i := range v {
+					v[i] *= math.Exp(drift[i] + config.Assets[i].Volatility*eps[i])
+				}
+
+				contribute(v, weights, config.Contribution)
+			}
+
+			totals[p] = sum(v)
+		}
+
+		slices.Sort(totals)
+		for i, percentile := range config.Percentiles {
+			result.Paths[i] = append(result.Paths[i], mathutils.PercentileSorted(totals, percentile))
+		}
+		result.Mean = append(result.Mean, mathutils.Mean(totals))
+	}
+
+	reached := len(totals) - sort.SearchFloat64s(totals, config.Target)
+	result.TargetProbability = float64(reached) / float64(len(totals))
+
+	return result, nil
+}
+
+// allocationWeights calculates the share of every asset in the initial value. If there is no initial value, the assets are weighted
+// equally.
+func allocationWeights(initial []float64) []float64 {
+	weights := make([]float64, len(initial))
+	total := sum(initial)
+
+	for i, v := range initial {
+		if total > 0 {
+			weights[i] = v / total
+		} else {
+			weights[i] = 1 / float64(len(initial))
+		}
+	}
+
+	return weights
+}
+
+// contribute pays the contribution into the holdings according to the weights, or takes a withdrawal proportionally from the holdings.
+func contribute(values []float64, weights []float64, contribution float64) {
+	if contribution >= 0 {
+		for i := range values {
+			values[i] += contribution * weights[i]
+		}
+		return
+	}
+
+	total := sum(values)
+	if total <= -contribution {
+		for i := range values {
+			values[i] = 0
+		}
+		return
+	}
+
+	factor := (total + contribution) / total
+	for i := range values {
+		values[i] *= factor
+	}
+}
+
+// sum adds up the values.
+func sum(values []float64) float64 {
+	s := 0.0
+	for _, v := range values {
+		s += v
+	}
+
+	return s
+}
@@ -0,0 +1,149 @@
+package calculation_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// monteCarloTestSuite contains context information for testing Monte Carlo projections.
+type monteCarloTestSuite struct {
+	suite.Suite
+	ctx *calculation.Context
+}
+
+// TestMonteCarloTestSuite initializes and executes the test suite.
+func TestMonteCarloTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(monteCarloTestSuite))
+}
+
+// SetupTest runs before each test case. The portfolio holds stocks and bonds worth 600 and 400.
+func (suite *monteCarloTestSuite) SetupTest() {
+	txCtx := transaction.Context{}
+	err := txCtx.AddTransactions([]transaction.Tx{
+		{Position: transaction.Position{Asset: &transaction.TxAsset{Id: "STOCKS"}, Timestamp: time.UnixMilli(0), Quantity: big.NewRat(6, 1), UnitPrice: big.NewRat(90, 1)}, Type: transaction.BUY},
+		{Position: transaction.Position{Asset: &transaction.TxAsset{Id: "BONDS"}, Timestamp: time.UnixMilli(0), Quantity: big.NewRat(4, 1), UnitPrice: big.NewRat(100, 1)}, Type: transaction.BUY},
+	})
+	assert.NoError(suite.T(), err)
+
+	assetCtx := asset.Context{}
+	err = assetCtx.AddAssets([]*asset.Asset{{Id: "STOCKS", UnitPrice: big.NewRat(100, 1)}, {Id: "BONDS", UnitPrice: big.NewRat(100, 1)}})
+	assert.NoError(suite.T(), err)
+
+	suite.ctx = &calculation.Context{AssetContext: &assetCtx, TransactionContext: &txCtx}
+}
+
+// config creates the configuration shared by the tests.
+func (suite *monteCarloTestSuite) config() calculation.MonteCarloConfig {
+	return calculation.MonteCarloConfig{
+		Assets: []calculation.MonteCarloAsset{
+			{Id: "STOCKS", ExpectedReturn: 0.07, Volatility: 0.18},
+			{Id: "BONDS", ExpectedReturn: 0.03, Volatility: 0.05},
+		},
+		Correlation: [][]float64{{1, 0.2}, {0.2, 1}},
+		Periods:     10,
+		Paths:       2000,
+		Seed:        42,
+		Percentiles: []float64{5, 50, 95},
+		Target:      1500,
+	}
+}
+
+// TestProjectMonteCarlo_Deterministic checks that the same seed yields the same result.
+func (suite *monteCarloTestSuite) TestProjectMonteCarlo_Deterministic() {
+	first, err := suite.ctx.ProjectMonteCarlo(suite.config())
+	assert.NoError(suite.T(), err)
+
+	second, err := suite.ctx.ProjectMonteCarlo(suite.config())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), first, second, "projections with the same seed should match")
+
+	config := suite.config()
+	config.Seed = 7
+	third, err := suite.ctx.ProjectMonteCarlo(config)
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), first.Paths, third.Paths, "projections with different seeds should differ")
+}
+
+// TestProjectMonteCarlo_Percentiles checks the shape and ordering of the percentile paths.
+func (suite *monteCarloTestSuite) TestProjectMonteCarlo_Percentiles() {
+	result, err := suite.ctx.ProjectMonteCarlo(suite.config())
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), []float64{5, 50, 95}, result.Percentiles)
+	assert.Equal(suite.T(), 3, len(result.Paths))
+	for _, path := range result.Paths {
+		assert.Equal(suite.T(), 11, len(path))
+		assert.InDelta(suite.T(), 1000, path[0], 1e-9, "every path should start at the current worth")
+	}
+
+	for t := 1; t <= 10; t++ {
+		assert.Less(suite.T(), result.Paths[0][t], result.Paths[1][t])
+		assert.Less(suite.T(), result.Paths[1][t], result.Paths[2][t])
+	}
+
+	/* The expected value after 10 periods is 600·1.07¹⁰ + 400·1.03¹⁰ ≈ 1718. */
+	assert.InDelta(suite.T(), 600*math.Pow(1.07, 10)+400*math.Pow(1.03, 10), result.Mean[10], 25)
+	assert.Greater(suite.T(), result.TargetProbability, 0.5)
+	assert.Less(suite.T(), result.TargetProbability, 1.0)
+}
+
+// TestProjectMonteCarlo_Contributions checks contributions and withdrawals without volatility.
+func (suite *monteCarloTestSuite) TestProjectMonteCarlo_Contributions() {
+	config := suite.config()
+	config.Assets[0].Volatility = 0
+	config.Assets[1].Volatility = 0
+	config.Assets[1].ExpectedReturn = 0.07
+	config.Periods = 2
+	config.Contribution = 100
+
+	result, err := suite.ctx.ProjectMonteCarlo(config)
+	assert.NoError(suite.T(), err)
+	assert.InDelta(suite.T(), (1000*1.07+100)*1.07+100, result.Paths[1][2], 1e-9)
+	assert.Equal(suite.T(), 0.0, result.TargetProbability)
+
+	config.Contribution = -600
+	result, err = suite.ctx.ProjectMonteCarlo(config)
+	assert.NoError(suite.T(), err)
+	assert.InDelta(suite.T(), 1000*1.07-600, result.Paths[1][1], 1e-9)
+	assert.Equal(suite.T(), 0.0, result.Paths[1][2], "the portfolio should be depleted")
+}
+
+// TestProjectMonteCarlo_Perfect_Correlation checks that a singular correlation matrix of perfectly correlated assets is accepted.
+func (suite *monteCarloTestSuite) TestProjectMonteCarlo_Perfect_Correlation() {
+	config := suite.config()
+	config.Correlation = [][]float64{{1, 1}, {1, 1}}
+
+	result, err := suite.ctx.ProjectMonteCarlo(config)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result.Paths, len(config.Percentiles))
+	last := config.Periods
+	assert.LessOrEqual(suite.T(), result.Paths[0][last], result.Paths[1][last])
+	assert.LessOrEqual(suite.T(), result.Paths[1][last], result.Paths[2][last])
+}
+
+// TestProjectMonteCarlo_Invalid checks that invalid configurations are rejected.
+func (suite *monteCarloTestSuite) TestProjectMonteCarlo_Invalid() {
+	config := suite.config()
+	config.Assets = config.Assets[:1]
+	config.Correlation = nil
+	_, err := suite.ctx.ProjectMonteCarlo(config)
+	assert.EqualError(suite.T(), err, "missing Monte Carlo assumptions for asset BONDS")
+
+	config = suite.config()
+	config.Correlation = [][]float64{{1, 1.5}, {1.5, 1}}
+	_, err = suite.ctx.ProjectMonteCarlo(config)
+	assert.EqualError(suite.T(), err, "invalid correlation matrix: matrix is not positive semidefinite")
+
+	config = suite.config()
+	config.Paths = 0
+	_, err = suite.ctx.ProjectMonteCarlo(config)
+	assert.EqualError(suite.T(), err, "number of periods and paths should be positive")
+}
@@ -20,12 +20,12 @@ func (ctx *Context) GetSalesForReturn(r *big.Rat) (map[*asset.Asset]*big.Rat, er
 		return nil, fmt.Errorf("asset context not set")
 	}
 
-	return ctx.GetSalesForReturnWithAssets(r, assetCtx.Assets, true)
+	return ctx.GetSalesForReturnWithAssets(r, assetCtx.GetAssets(), true)
 }
 
 // GetSalesForReturnWithAssets calculates how much and which positions should be sold in order to realize the given return.
 // The last boolean flag can be used to reorder assets such that the desired profit / loss is realized with the fewest transactions.
-// If the flag is set to false, the initial asset slice is preserved and assets are sold in that order.
+// If the flag is set to false, assets are sold in the order of the asset slice. The asset slice itself is never reordered.
 func (ctx *Context) GetSalesForReturnWithAssets(r *big.Rat, assets []*asset.Asset, doOptimize bool) (map[*asset.Asset]*big.Rat, error) {
 	if r == nil {
 		return nil, fmt.Errorf("return shouldn't be nil")
@@ -49,6 +49,7 @@ func (ctx *Context) GetSalesForReturnWithAssets(r *big.Rat, assets []*asset.Asse
 // getSalesForProfitWithAssets calculates how much of the given assets have to be sold to get the given profit
 func (ctx *Context) getSalesForProfitWithAssets(r *big.Rat, assets []*asset.Asset, profits map[*asset.Asset]*big.Rat, doOptimize bool) (map[*asset.Asset]*big.Rat, error) {
 	if doOptimize {
+		assets = slices.Clone(assets)
 		sort.Slice(assets, func(i, j int) bool {
 			return profits[assets[i]].Cmp(profits[assets[j]]) > 0
 		})
@@ -121,6 +122,7 @@ func (ctx *Context) sellForProfit(r *big.Rat, assets []*asset.Asset, profits map
 // getSalesForLossWithAssets calculates how much of the given assets have to be sold to get the given loss
 func (ctx *Context) getSalesForLossWithAssets(r *big.Rat, assets []*asset.Asset, losses map[*asset.Asset]*big.Rat, doOptimize bool) (map[*asset.Asset]*big.Rat, error) {
 	if doOptimize {
+		assets = slices.Clone(assets)
 		sort.Slice(assets, func(i, j int) bool {
 			return losses[assets[i]].Cmp(losses[assets[j]]) < 0
 		})
@@ -196,7 +198,7 @@ func (ctx *Context) GetMaxProfitAndLoss() (map[*asset.Asset]*big.Rat, map[*asset
 		return nil, nil, fmt.Errorf("asset context not set")
 	}
 
-	return ctx.GetMaxProfitAndLossForAssets(assetCtx.Assets)
+	return ctx.GetMaxProfitAndLossForAssets(assetCtx.GetAssets())
 }
 
 // GetMaxProfitAndLossForAssets calculates the maximum realizable profit and loss for each asset with live data.
@@ -224,15 +226,11 @@ func (ctx *Context) GetMaxProfitAndLossForAsset(a *asset.Asset) (*big.Rat, *big.
 		return nil, nil, fmt.Errorf("transaction context not set")
 	}
 
-	i := slices.IndexFunc(txCtx.Assets, func(txAsset *transaction.TxAsset) bool {
-		return txAsset.Id == a.Id
-	})
-
-	if i == -1 {
+	txAsset, ok := txCtx.GetAsset(a.Id)
+	if !ok {
 		return big.NewRat(0, 1), big.NewRat(0, 1), nil
 	}
 
-	txAsset := txCtx.Assets[i]
 	p := txCtx.GetAssetPositions(txAsset)
 	maxProfit, maxLoss := big.NewRat(0, 1), big.NewRat(0, 1)
 	diff := big.NewRat(0, 1)
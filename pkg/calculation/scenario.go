@@ -0,0 +1,241 @@
+package calculation
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"math/big"
+	"slices"
+)
+
+// ShockType holds the different kinds of price shocks as a pseudo-enum.
+type ShockType = int
+
+const (
+	// PERCENTAGE changes the unit price by the given percentage, e.g. -30 for a drop of 30%.
+	PERCENTAGE ShockType = iota
+	// ABSOLUTE adds the given amount to the unit price, e.g. -5 for a drop of 5 units of the asset's currency.
+	ABSOLUTE
+)
+
+// Shock changes the unit price of the assets it applies to. A shock with an AssetId applies to that asset only, a shock with a Tag
+// applies to every asset carrying the tag, and a shock with neither applies to every asset.
+type Shock struct {
+	AssetId string
+	Tag     string
+	Type    ShockType
+	Value   *big.Rat
+}
+
+// Scenario is a named set of price shocks, e.g. "equities -30%, bonds -10%".
+// Every asset is affected by at most one shock: asset shocks take precedence over tag shocks, which take precedence over global shocks.
+// Among shocks of the same kind, the first matching one is used. Prices never drop below zero.
+type Scenario struct {
+	Name   string
+	Shocks []Shock
+}
+
+// StressTestResult describes the effect of a Scenario on the portfolio. Maps are keyed by asset ID.
+type StressTestResult struct {
+	Scenario    string
+	WorthBefore *big.Rat
+	WorthAfter  *big.Rat
+	// ProfitAndLoss maps every asset held to the change of its worth caused by the scenario.
+	ProfitAndLoss map[string]*big.Rat
+	// AllocationDrift maps every asset held to the change of its weight in the portfolio, assets with unchanged weight are omitted.
+	AllocationDrift map[string]*big.Rat
+	// Sales holds the quantities GetSalesForReturn would sell after the shock, nil if no return was requested or it cannot be realized.
+	Sales map[string]*big.Rat
+	// SalesError is the reason why GetSalesForReturn failed after the shock, e.g. if the return cannot be realized anymore.
+	SalesError error
+}
+
+// Validate verifies that every shock of the Scenario can be applied.
+func (s Scenario) Validate() error {
+	for i, shock := range s.Shocks {
+		if shock.Value == nil {
+			return fmt.Errorf("missing value of shock %d of scenario \"%s\"", i, s.Name)
+		}
+
+		if shock.Type != PERCENTAGE && shock.Type != ABSOLUTE {
+			return fmt.Errorf("unsupported type %d of shock %d of scenario \"%s\"", shock.Type, i, s.Name)
+		}
+
+		if shock.AssetId != "" && shock.Tag != "" {
+			return fmt.Errorf("shock %d of scenario \"%s\" has both an asset and a tag", i, s.Name)
+		}
+	}
+
+	return nil
+}
+
+// Apply creates a new asset.Context holding copies of the assets of ctx with the shocks of the Scenario applied to their unit prices.
+// The given context is not modified.
+func (s Scenario) Apply(ctx *asset.Context) (*asset.Context, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("asset context not set")
+	}
+
+	assets, err := s.shockAssets(ctx.GetAssets())
+	if err != nil {
+		return nil, err
+	}
+
+	shocked := &asset.Context{}
+	if len(assets) == 0 {
+		return shocked, nil
+	}
+
+	return shocked, shocked.AddAssets(assets)
+}
+
+// shockAssets returns copies of the assets with the shocks of the Scenario applied to their unit prices.
+func (s Scenario) shockAssets(assets []*asset.Asset) ([]*asset.Asset, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	shocked := make([]*asset.Asset, 0, len(assets))
+	for _, a := range assets {
+		c := a.Clone()
+		if shock, ok := s.shockOf(a); ok && c.UnitPrice != nil {
+			c.UnitPrice = shock.apply(c.UnitPrice)
+		}
+		shocked = append(shocked, c)
+	}
+
+	return shocked, nil
+}
+
+// shockOf returns the shock affecting the asset. The second return value is false if no shock applies to the asset.
+func (s Scenario) shockOf(a *asset.Asset) (Shock, bool) {
+	var byTag, global *Shock
+	for i := range s.Shocks {
+		shock := &s.Shocks[i]
+		switch {
+		case shock.AssetId != "":
+			if shock.AssetId == a.Id {
+				return *shock, true
+			}
+		case shock.Tag != "":
+			if byTag == nil && slices.Contains(a.Tags, shock.Tag) {
+				byTag = shock
+			}
+		default:
+			if global == nil {
+				global = shock
+			}
+		}
+	}
+
+	if byTag != nil {
+		return *byTag, true
+	}
+
+	if global != nil {
+		return *global, true
+	}
+
+	return Shock{}, false
+}
+
+// apply calculates the shocked unit price.
+func (s Shock) apply(unitPrice *big.Rat) *big.Rat {
+	price := big.NewRat(0, 1)
+	switch s.Type {
+	case PERCENTAGE:
+		factor := big.NewRat(0, 1).Quo(s.Value, big.NewRat(100, 1))
+		factor.Add(factor, big.NewRat(1, 1))
+		price.Mul(unitPrice, factor)
+	case ABSOLUTE:
+		price.Add(unitPrice, s.Value)
+	}
+
+	if price.Sign() < 0 {
+		return big.NewRat(0, 1)
+	}
+
+	return price
+}
+
+// StressTest applies the Scenario to a copy of the portfolio and reports the resulting worth, the profit and loss per asset, the drift of
+// the allocation and, if r is not nil, the sales GetSalesForReturn would propose for the return r afterwards. The contexts of ctx are not
+// modified.
+func (ctx *Context) StressTest(s Scenario, r *big.Rat) (*StressTestResult, error) {
+	before, err := NewSnapshot(ctx.TransactionContext, ctx.AssetContext)
+	if err != nil {
+		return nil, err
+	}
+
+	assets, err := s.shockAssets(before.assets)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := before.WithAssets(assets...)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeCtx, err := before.Context()
+	if err != nil {
+		return nil, err
+	}
+
+	afterCtx, err := after.Context()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := getPortfolioState(beforeCtx, big.NewRat(0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := getPortfolioState(afterCtx, big.NewRat(0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(b.Quantities))
+	for k := range b.Quantities {
+		keys = append(keys, k)
+	}
+
+	worthBefore, err := beforeCtx.GetAssetKeyWorthMapOfKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	worthAfter, err := afterCtx.GetAssetKeyWorthMapOfKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	pnl := map[string]*big.Rat{}
+	for _, k := range keys {
+		pnl[k] = big.NewRat(0, 1).Sub(worthAfter[k], worthBefore[k])
+	}
+
+	result := &StressTestResult{
+		Scenario:        s.Name,
+		WorthBefore:     b.Worth,
+		WorthAfter:      a.Worth,
+		ProfitAndLoss:   pnl,
+		AllocationDrift: diffMaps(b.Ratios, a.Ratios),
+	}
+
+	if r != nil {
+		sales, salesErr := afterCtx.GetSalesForReturn(r)
+		if salesErr != nil {
+			result.SalesError = salesErr
+			return result, nil
+		}
+
+		result.Sales = map[string]*big.Rat{}
+		for a, q := range sales {
+			result.Sales[a.Id] = q
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package calculation_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/wlachs/wstonks/pkg/asset"
+	assetio "github.com/wlachs/wstonks/pkg/asset/io"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	txio "github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"testing"
+)
+
+// scenarioTestSuite contains context information for testing stress-test scenarios.
+type scenarioTestSuite struct {
+	suite.Suite
+	ctx *calculation.Context
+}
+
+// TestScenarioTestSuite initializes and executes the test suite.
+func TestScenarioTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(scenarioTestSuite))
+}
+
+// SetupTest runs before each test case.
+func (suite *scenarioTestSuite) SetupTest() {
+	txCtx := transaction.Context{}
+	err := txio.TxCsvLoader{Path: "../../test/data/io/transactions/smoke_sales.csv"}.Load(&txCtx)
+	assert.NoError(suite.T(), err, "failed to load transaction context")
+
+	assetCtx := asset.Context{}
+	err = assetio.LiveAssetCsvLoader{Path: "../../test/data/io/assets/smoke_sales.csv"}.Load(&assetCtx)
+	assert.NoError(suite.T(), err, "failed to load asset context")
+
+	suite.ctx = &calculation.Context{AssetContext: &assetCtx, TransactionContext: &txCtx}
+}
+
+// TestScenario_Apply checks the precedence of asset, tag and global shocks.
+func (suite *scenarioTestSuite) TestScenario_Apply() {
+	ctx := &asset.Context{}
+	err := ctx.AddAssets([]*asset.Asset{
+		{Id: "A", UnitPrice: big.NewRat(100, 1), Tags: []string{"equity"}},
+		{Id: "B", UnitPrice: big.NewRat(100, 1), Tags: []string{"bond"}},
+		{Id: "C", UnitPrice: big.NewRat(100, 1), Tags: []string{"equity"}},
+		{Id: "D", UnitPrice: big.NewRat(100, 1)},
+		{Id: "E", UnitPrice: big.NewRat(3, 1)},
+	})
+	assert.NoError(suite.T(), err)
+
+	s := calculation.Scenario{
+		Name: "crash",
+		Shocks: []calculation.Shock{
+			{Type: calculation.PERCENTAGE, Value: big.NewRat(-5, 1)},
+			{Tag: "equity", Type: calculation.PERCENTAGE, Value: big.NewRat(-30, 1)},
+			{Tag: "bond", Type: calculation.PERCENTAGE, Value: big.NewRat(-10, 1)},
+			{AssetId: "C", Type: calculation.ABSOLUTE, Value: big.NewRat(-1, 2)},
+			{AssetId: "E", Type: calculation.ABSOLUTE, Value: big.NewRat(-5, 1)},
+		},
+	}
+
+	shocked, err := s.Apply(ctx)
+	assert.NoError(suite.T(), err)
+
+	prices := shocked.GetAssetKeyPriceMap()
+	assert.Equal(suite.T(), big.NewRat(70, 1), prices["A"], "tag shock should apply")
+	assert.Equal(suite.T(), big.NewRat(90, 1), prices["B"], "tag shock should apply")
+	assert.Equal(suite.T(), big.NewRat(199, 2), prices["C"], "asset shock should take precedence")
+	assert.Equal(suite.T(), big.NewRat(95, 1), prices["D"], "global shock should apply")
+	assert.Equal(suite.T(), big.NewRat(0, 1), prices["E"], "price should not drop below zero")
+	assert.Equal(suite.T(), big.NewRat(100, 1), ctx.GetAssetKeyPriceMap()["A"], "original context should not change")
+}
+
+// TestScenario_Validate checks that invalid shocks are rejected.
+func (suite *scenarioTestSuite) TestScenario_Validate() {
+	s := calculation.Scenario{Name: "invalid", Shocks: []calculation.Shock{{AssetId: "A", Tag: "equity", Value: big.NewRat(1, 1)}}}
+	_, err := s.Apply(suite.ctx.AssetContext)
+	assert.EqualError(suite.T(), err, "shock 0 of scenario \"invalid\" has both an asset and a tag")
+
+	s = calculation.Scenario{Name: "invalid", Shocks: []calculation.Shock{{AssetId: "A"}}}
+	_, err = s.Apply(suite.ctx.AssetContext)
+	assert.EqualError(suite.T(), err, "missing value of shock 0 of scenario \"invalid\"")
+}
+
+// TestStressTest checks worth, profit and loss, allocation drift and sales after a shock.
+func (suite *scenarioTestSuite) TestStressTest() {
+	s := calculation.Scenario{
+		Name: "single asset",
+		Shocks: []calculation.Shock{
+			{AssetId: "A", Type: calculation.ABSOLUTE, Value: big.NewRat(-71234, 10000)},
+			{Type: calculation.PERCENTAGE, Value: big.NewRat(5, 1)},
+		},
+	}
+
+	result, err := suite.ctx.StressTest(s, big.NewRat(1, 1))
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), "single asset", result.Scenario)
+	assert.Equal(suite.T(), big.NewRat(-15*71234, 10000), result.ProfitAndLoss["A"])
+	assert.Equal(suite.T(), big.NewRat(1, 1), result.ProfitAndLoss["C"])
+
+	drop := big.NewRat(0, 1).Sub(result.WorthAfter, result.WorthBefore)
+	sum := big.NewRat(0, 1)
+	for _, pnl := range result.ProfitAndLoss {
+		sum.Add(sum, pnl)
+	}
+	assert.Equal(suite.T(), drop, sum, "profit and loss should add up to the change of worth")
+
+	assert.True(suite.T(), result.AllocationDrift["A"].Sign() < 0, "the weight of A should decrease")
+	assert.Nil(suite.T(), result.SalesError)
+	assert.Equal(suite.T(), map[string]*big.Rat{"E": big.NewRat(10, 47)}, result.Sales, "the asset with the highest profit should be sold")
+}
+
+// TestStressTest_Unreachable_Return checks that the stress test succeeds if the return cannot be realized after the shock.
+func (suite *scenarioTestSuite) TestStressTest_Unreachable_Return() {
+	s := calculation.Scenario{Name: "crash", Shocks: []calculation.Shock{{Type: calculation.PERCENTAGE, Value: big.NewRat(-50, 1)}}}
+
+	result, err := suite.ctx.StressTest(s, big.NewRat(1, 1))
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), result.Sales)
+	assert.EqualError(suite.T(), result.SalesError, "not enough assets to sell")
+	assert.Equal(suite.T(), big.NewRat(0, 1).Quo(result.WorthBefore, big.NewRat(2, 1)), result.WorthAfter)
+}
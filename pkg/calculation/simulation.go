@@ -0,0 +1,230 @@
+package calculation
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+)
+
+// Order is a hypothetical BUY or SELL transaction of the given quantity of an asset at the given unit price.
+type Order struct {
+	AssetId   string
+	Type      transaction.TxType
+	Quantity  *big.Rat
+	UnitPrice *big.Rat
+}
+
+// PortfolioState holds the figures of a portfolio compared by a simulation. Maps are keyed by asset ID.
+type PortfolioState struct {
+	// Quantities maps every asset held to its quantity.
+	Quantities map[string]*big.Rat
+	// Ratios maps every asset held to its weight in the portfolio, see GetAssetRatio.
+	Ratios map[string]*big.Rat
+	// Worth is the current worth of the portfolio.
+	Worth *big.Rat
+	// Return is the difference between the current worth and the initial worth of the open positions.
+	Return         *big.Rat
+	RealizedProfit *big.Rat
+	RealizedLoss   *big.Rat
+	// EstimatedTax is the tax due on the net profit realized by sales at the tax rate of the simulation. Income like dividends and
+	// interest, and fees are not considered.
+	EstimatedTax *big.Rat
+}
+
+// Simulation holds the state of the portfolio before and after executing the orders, and the difference between the two.
+type Simulation struct {
+	Before PortfolioState
+	After  PortfolioState
+	// Diff holds the value of After minus the value of Before for every figure. Assets missing from one of the states count as zero.
+	Diff PortfolioState
+}
+
+// Simulate executes the orders in the given order on a copy of the portfolio and compares the portfolio before and after the execution.
+// The orders are executed at the timestamp of the latest transaction of the portfolio, after the transactions already booked at that
+// time, so the result does not depend on when the simulation runs. The contexts of ctx are not modified. Assets bought without a live
+// price are valued at the price of the order. The tax is estimated on the net profit realized by sales with the given tax rate, e.g. 1/4
+// for 25%.
+func (ctx *Context) Simulate(orders []Order, taxRate *big.Rat) (*Simulation, error) {
+	if taxRate == nil {
+		return nil, fmt.Errorf("tax rate shouldn't be nil")
+	}
+
+	before, err := NewSnapshot(ctx.TransactionContext, ctx.AssetContext)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := applyOrders(before, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeCtx, err := before.Context()
+	if err != nil {
+		return nil, err
+	}
+
+	afterCtx, err := after.Context()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := getPortfolioState(beforeCtx, taxRate)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := getPortfolioState(afterCtx, taxRate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Simulation{Before: b, After: a, Diff: diffPortfolioStates(b, a)}, nil
+}
+
+// applyOrders returns a new snapshot with the orders added as transactions at the timestamp of the latest transaction.
+func applyOrders(s *Snapshot, orders []Order) (*Snapshot, error) {
+	ts := s.latest
+
+	txs := make([]transaction.Tx, 0, len(orders))
+	var assets []*asset.Asset
+
+	for i, o := range orders {
+		if err := validateOrder(o); err != nil {
+			return nil, fmt.Errorf("invalid order %d: %w", i, err)
+		}
+
+		if _, ok := s.asset(o.AssetId); !ok {
+			assets = append(assets, &asset.Asset{Id: o.AssetId, UnitPrice: o.UnitPrice})
+		}
+
+		txs = append(txs, transaction.Tx{
+			Position: transaction.Position{
+				Asset:     &transaction.TxAsset{Id: o.AssetId},
+				Timestamp: ts,
+				Quantity:  o.Quantity,
+				UnitPrice: o.UnitPrice,
+			},
+			Type: o.Type,
+		})
+	}
+
+	if len(assets) > 0 {
+		var err error
+		if s, err = s.WithAssets(assets...); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.WithTransactions(txs...)
+}
+
+// validateOrder verifies that the order can be executed.
+func validateOrder(o Order) error {
+	if o.AssetId == "" {
+		return fmt.Errorf("missing asset ID")
+	}
+
+	if o.Type != transaction.BUY && o.Type != transaction.SELL {
+		return fmt.Errorf("unsupported order type %d", o.Type)
+	}
+
+	if o.Quantity == nil || o.Quantity.Sign() <= 0 {
+		return fmt.Errorf("quantity should be positive")
+	}
+
+	if o.UnitPrice == nil || o.UnitPrice.Sign() < 0 {
+		return fmt.Errorf("unit price shouldn't be negative")
+	}
+
+	return nil
+}
+
+// getPortfolioState calculates the figures of the portfolio compared by a simulation.
+func getPortfolioState(ctx *Context, taxRate *big.Rat) (PortfolioState, error) {
+	worth, err := ctx.GetAssetWorth()
+	if err != nil {
+		return PortfolioState{}, err
+	}
+
+	returns, err := ctx.GetAssetReturnMap()
+	if err != nil {
+		return PortfolioState{}, err
+	}
+
+	ret := big.NewRat(0, 1)
+	for _, r := range returns {
+		ret.Add(ret, r)
+	}
+
+	ratios := map[string]*big.Rat{}
+	if worth.Sign() != 0 {
+		m, ratioErr := ctx.GetAssetRatio(ctx.AssetContext.GetAssets())
+		if ratioErr != nil {
+			return PortfolioState{}, ratioErr
+		}
+
+		for a, r := range m {
+			if r.Sign() != 0 {
+				ratios[a.Id] = r
+			}
+		}
+	}
+
+	profit := ctx.TransactionContext.GetRealizedProfit()
+	loss := ctx.TransactionContext.GetRealizedLoss()
+
+	tax := big.NewRat(0, 1).Sub(ctx.TransactionContext.GetRealizedSalesProfit(), ctx.TransactionContext.GetRealizedSalesLoss())
+	if tax.Sign() < 0 {
+		tax = big.NewRat(0, 1)
+	}
+	tax.Mul(tax, taxRate)
+
+	return PortfolioState{
+		Quantities:     ctx.TransactionContext.GetAssetKeyMap(),
+		Ratios:         ratios,
+		Worth:          worth,
+		Return:         ret,
+		RealizedProfit: profit,
+		RealizedLoss:   loss,
+		EstimatedTax:   tax,
+	}, nil
+}
+
+// diffPortfolioStates subtracts every figure of the before state from the after state.
+func diffPortfolioStates(before PortfolioState, after PortfolioState) PortfolioState {
+	return PortfolioState{
+		Quantities:     diffMaps(before.Quantities, after.Quantities),
+		Ratios:         diffMaps(before.Ratios, after.Ratios),
+		Worth:          big.NewRat(0, 1).Sub(after.Worth, before.Worth),
+		Return:         big.NewRat(0, 1).Sub(after.Return, before.Return),
+		RealizedProfit: big.NewRat(0, 1).Sub(after.RealizedProfit, before.RealizedProfit),
+		RealizedLoss:   big.NewRat(0, 1).Sub(after.RealizedLoss, before.RealizedLoss),
+		EstimatedTax:   big.NewRat(0, 1).Sub(after.EstimatedTax, before.EstimatedTax),
+	}
+}
+
+// diffMaps subtracts the values of the before map from the values of the after map. Keys missing from one of the maps count as zero,
+// keys without difference are omitted.
+func diffMaps(before map[string]*big.Rat, after map[string]*big.Rat) map[string]*big.Rat {
+	m := map[string]*big.Rat{}
+	for k, a := range after {
+		m[k] = big.NewRat(0, 1).Set(a)
+	}
+
+	for k, b := range before {
+		if _, ok := m[k]; !ok {
+			m[k] = big.NewRat(0, 1)
+		}
+		m[k].Sub(m[k], b)
+	}
+
+	for k, d := range m {
+		if d.Sign() == 0 {
+			delete(m, k)
+		}
+	}
+
+	return m
+}
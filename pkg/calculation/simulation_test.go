@@ -0,0 +1,121 @@
+package calculation_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/wlachs/wstonks/pkg/asset"
+	assetio "github.com/wlachs/wstonks/pkg/asset/io"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	txio "github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"testing"
+)
+
+// simulationTestSuite contains context information for testing what-if simulations.
+type simulationTestSuite struct {
+	suite.Suite
+	ctx *calculation.Context
+}
+
+// TestSimulationTestSuite initializes and executes the test suite.
+func TestSimulationTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(simulationTestSuite))
+}
+
+// SetupTest runs before each test case.
+func (suite *simulationTestSuite) SetupTest() {
+	txCtx := transaction.Context{}
+	err := txio.TxCsvLoader{Path: "../../test/data/io/transactions/smoke_sales.csv"}.Load(&txCtx)
+	assert.NoError(suite.T(), err, "failed to load transaction context")
+
+	assetCtx := asset.Context{}
+	err = assetio.LiveAssetCsvLoader{Path: "../../test/data/io/assets/smoke_sales.csv"}.Load(&assetCtx)
+	assert.NoError(suite.T(), err, "failed to load asset context")
+
+	suite.ctx = &calculation.Context{AssetContext: &assetCtx, TransactionContext: &txCtx}
+}
+
+// TestSimulate_Sell simulates a profitable sale and checks the realized profit and the estimated tax.
+func (suite *simulationTestSuite) TestSimulate_Sell() {
+	orders := []calculation.Order{{AssetId: "A", Type: transaction.SELL, Quantity: big.NewRat(5, 1), UnitPrice: big.NewRat(120, 1)}}
+	s, err := suite.ctx.Simulate(orders, big.NewRat(1, 4))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), big.NewRat(15, 1), s.Before.Quantities["A"])
+	assert.Equal(suite.T(), big.NewRat(10, 1), s.After.Quantities["A"])
+	assert.Equal(suite.T(), map[string]*big.Rat{"A": big.NewRat(-5, 1)}, s.Diff.Quantities)
+	assert.Equal(suite.T(), big.NewRat(100, 1), s.Diff.RealizedProfit, "selling 5 units bought at 100 for 120 should realize 100")
+	assert.Equal(suite.T(), big.NewRat(0, 1), s.Before.EstimatedTax, "no tax is due on a net loss")
+	assert.Equal(suite.T(), big.NewRat(75, 4), s.After.EstimatedTax, "the dividend should not be taxed as a sales profit")
+	assert.Equal(suite.T(), big.NewRat(-5*1071234, 10000), s.Diff.Worth)
+	assert.True(suite.T(), s.Diff.Ratios["A"].Sign() < 0, "the weight of A should decrease")
+}
+
+// TestSimulate_Buy_New_Asset simulates buying an asset without live price, which is valued at the order price.
+func (suite *simulationTestSuite) TestSimulate_Buy_New_Asset() {
+	orders := []calculation.Order{{AssetId: "G", Type: transaction.BUY, Quantity: big.NewRat(2, 1), UnitPrice: big.NewRat(50, 1)}}
+	s, err := suite.ctx.Simulate(orders, big.NewRat(1, 4))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[string]*big.Rat{"G": big.NewRat(2, 1)}, s.Diff.Quantities)
+	assert.Equal(suite.T(), big.NewRat(100, 1), s.Diff.Worth)
+	assert.Equal(suite.T(), 0, s.Diff.Return.Sign())
+	assert.Equal(suite.T(), 0, s.Diff.RealizedProfit.Sign())
+	assert.Contains(suite.T(), s.After.Ratios, "G")
+	assert.NotContains(suite.T(), s.Before.Ratios, "G")
+}
+
+// TestSimulate_Original_Unchanged checks that the simulation does not modify the original contexts.
+func (suite *simulationTestSuite) TestSimulate_Original_Unchanged() {
+	quantities := suite.ctx.TransactionContext.GetAssetKeyMap()
+	prices := suite.ctx.AssetContext.GetAssetKeyPriceMap()
+
+	orders := []calculation.Order{
+		{AssetId: "A", Type: transaction.SELL, Quantity: big.NewRat(15, 1), UnitPrice: big.NewRat(90, 1)},
+		{AssetId: "G", Type: transaction.BUY, Quantity: big.NewRat(1, 1), UnitPrice: big.NewRat(1, 1)},
+	}
+	_, err := suite.ctx.Simulate(orders, big.NewRat(1, 4))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), quantities, suite.ctx.TransactionContext.GetAssetKeyMap())
+	assert.Equal(suite.T(), prices, suite.ctx.AssetContext.GetAssetKeyPriceMap())
+	assert.Equal(suite.T(), 11, len(suite.ctx.TransactionContext.GetTransactions()))
+}
+
+// TestSimulate_Invalid_Orders checks that invalid orders are rejected.
+func (suite *simulationTestSuite) TestSimulate_Invalid_Orders() {
+	_, err := suite.ctx.Simulate([]calculation.Order{{AssetId: "A", Type: transaction.DIVIDEND, Quantity: big.NewRat(1, 1), UnitPrice: big.NewRat(1, 1)}}, big.NewRat(0, 1))
+	assert.EqualError(suite.T(), err, "invalid order 0: unsupported order type 2")
+
+	_, err = suite.ctx.Simulate([]calculation.Order{{AssetId: "A", Type: transaction.SELL, Quantity: big.NewRat(16, 1), UnitPrice: big.NewRat(1, 1)}}, big.NewRat(0, 1))
+	assert.EqualError(suite.T(), err, "negative asset quantity A: -1.000000 < 0")
+
+	_, err = suite.ctx.Simulate(nil, nil)
+	assert.EqualError(suite.T(), err, "tax rate shouldn't be nil")
+}
+
+// TestSimulate_Income checks that dividends, interest and fees don't change the estimated tax, which is due on the net profit of sales.
+func TestSimulate_Income(t *testing.T) {
+	t.Parallel()
+
+	txCtx := transaction.Context{}
+	err := txio.TxCsvLoader{Path: "../../test/data/io/transactions/smoke_income.csv"}.Load(&txCtx)
+	assert.NoError(t, err, "failed to load transaction context")
+
+	assetCtx := asset.Context{}
+	err = assetio.LiveAssetCsvLoader{Path: "../../test/data/io/assets/smoke_income.csv"}.Load(&assetCtx)
+	assert.NoError(t, err, "failed to load asset context")
+
+	ctx := calculation.Context{AssetContext: &assetCtx, TransactionContext: &txCtx}
+	orders := []calculation.Order{{AssetId: "A", Type: transaction.SELL, Quantity: big.NewRat(2, 1), UnitPrice: big.NewRat(120, 1)}}
+	s, err := ctx.Simulate(orders, big.NewRat(1, 4))
+
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(50, 1), s.Before.RealizedProfit, "the dividend should be part of the realized profit")
+	assert.Equal(t, big.NewRat(20, 1), s.Before.RealizedLoss)
+	assert.Equal(t, big.NewRat(0, 1), s.Before.EstimatedTax, "no tax is due on a net loss of sales")
+	assert.Equal(t, big.NewRat(40, 1), s.Diff.RealizedProfit)
+	assert.Equal(t, big.NewRat(5, 1), s.After.EstimatedTax, "the tax should be due on the net profit of sales only")
+}
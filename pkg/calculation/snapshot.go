@@ -0,0 +1,262 @@
+package calculation
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"maps"
+	"math/big"
+	"time"
+)
+
+// Snapshot is an immutable copy of a portfolio, i.e. of a transaction.Context and an asset.Context. Changes made to the source contexts
+// after taking the snapshot are not reflected in it, and calculations run on the snapshot never touch the source data.
+// The modification methods of a Snapshot return a new Snapshot and leave the original one untouched. Unchanged data is shared between the
+// two: adding transactions only copies the added ones, and updating a price does not copy the transaction history at all. Snapshots are
+// safe for concurrent use.
+type Snapshot struct {
+	history *history
+	assets  []*asset.Asset
+	// quantities maps the ID of every asset traded to its owned quantity, to validate added transactions without replaying the history.
+	quantities map[string]*big.Rat
+	// latest is the timestamp of the latest transaction.
+	latest time.Time
+}
+
+// history is a persistent list of transactions. Adding transactions creates a new segment pointing to the previous one, so the
+// transactions already contained are shared between the snapshots instead of being copied. Segments are never modified.
+type history struct {
+	parent       *history
+	transactions []transaction.Tx
+	count        int
+}
+
+// append returns a new history holding the transactions of h followed by the given ones.
+func (h *history) append(transactions []transaction.Tx) *history {
+	if len(transactions) == 0 {
+		return h
+	}
+
+	n := &history{parent: h, transactions: transactions, count: len(transactions)}
+	if h != nil {
+		n.count += h.count
+	}
+
+	return n
+}
+
+// all returns the transactions of the history in the order they were added. The transactions share their values with the history and
+// must not be modified.
+func (h *history) all() []transaction.Tx {
+	if h == nil {
+		return nil
+	}
+
+	transactions := make([]transaction.Tx, h.count)
+	end := h.count
+	for s := h; s != nil; s = s.parent {
+		end -= len(s.transactions)
+		copy(transactions[end:], s.transactions)
+	}
+
+	return transactions
+}
+
+// NewSnapshot takes a snapshot of the given contexts. A nil context is treated as an empty one.
+func NewSnapshot(txCtx *transaction.Context, assetCtx *asset.Context) (*Snapshot, error) {
+	var transactions []transaction.Tx
+	if txCtx != nil {
+		for _, t := range txCtx.GetTransactions() {
+			transactions = append(transactions, cloneTx(*t))
+		}
+	}
+
+	var assets []*asset.Asset
+	if assetCtx != nil {
+		for _, a := range assetCtx.GetAssets() {
+			assets = append(assets, a.Clone())
+		}
+	}
+
+	if err := validateAssets(assets); err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{assets: assets, quantities: map[string]*big.Rat{}}
+	return s.withClonedTransactions(transactions)
+}
+
+// Context returns a Context for running calculations on the snapshot. Every call creates new contexts from the data of the snapshot, so
+// modifying them does not affect the snapshot. Callers running several calculations should reuse the returned Context.
+func (s *Snapshot) Context() (*Context, error) {
+	txCtx := &transaction.Context{}
+	if transactions := s.Transactions(); len(transactions) > 0 {
+		if err := txCtx.AddTransactions(transactions); err != nil {
+			return nil, err
+		}
+	}
+
+	assetCtx := &asset.Context{}
+	if assets := s.Assets(); len(assets) > 0 {
+		if err := assetCtx.AddAssets(assets); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Context{TransactionContext: txCtx, AssetContext: assetCtx}, nil
+}
+
+// Transactions returns a copy of the transaction history of the snapshot.
+func (s *Snapshot) Transactions() []transaction.Tx {
+	transactions := s.history.all()
+	for i, t := range transactions {
+		transactions[i] = cloneTx(t)
+	}
+
+	return transactions
+}
+
+// Assets returns a copy of the assets of the snapshot.
+func (s *Snapshot) Assets() []*asset.Asset {
+	assets := make([]*asset.Asset, 0, len(s.assets))
+	for _, a := range s.assets {
+		assets = append(assets, a.Clone())
+	}
+
+	return assets
+}
+
+// WithTransactions returns a new Snapshot with the given transactions added to the transaction history. The assets and the transactions
+// already contained are shared with the original snapshot. An error is returned if the resulting transaction history is invalid, e.g. if
+// more units are sold than held.
+func (s *Snapshot) WithTransactions(transactions ...transaction.Tx) (*Snapshot, error) {
+	txs := make([]transaction.Tx, 0, len(transactions))
+	for _, t := range transactions {
+		if t.Asset == nil || t.Asset.Id == "" {
+			return nil, fmt.Errorf("missing asset for transaction %v", t)
+		}
+
+		txs = append(txs, cloneTx(t))
+	}
+
+	return s.withClonedTransactions(txs)
+}
+
+// withClonedTransactions adds transactions that were already copied to a new Snapshot. Only the quantities of the assets traded by the
+// transactions are recalculated and validated.
+func (s *Snapshot) withClonedTransactions(transactions []transaction.Tx) (*Snapshot, error) {
+	quantities := maps.Clone(s.quantities)
+	if quantities == nil {
+		quantities = map[string]*big.Rat{}
+	}
+	latest := s.latest
+
+	for i := range transactions {
+		t := &transactions[i]
+		id := t.Asset.Id
+
+		q := big.NewRat(0, 1).Add(t.QuantityChange(), zeroIfNil(quantities[id]))
+		quantities[id] = q
+
+		if t.Timestamp.After(latest) {
+			latest = t.Timestamp
+		}
+	}
+
+	for id, q := range quantities {
+		if q.Sign() < 0 {
+			f, _ := q.Float32()
+			return nil, fmt.Errorf("negative asset quantity %s: %f < 0", id, f)
+		}
+	}
+
+	return &Snapshot{history: s.history.append(transactions), assets: s.assets, quantities: quantities, latest: latest}, nil
+}
+
+// WithAssets returns a new Snapshot with the given assets added. Assets already contained in the snapshot are replaced, e.g. to update
+// their price. The transaction history is shared with the original snapshot.
+func (s *Snapshot) WithAssets(assets ...*asset.Asset) (*Snapshot, error) {
+	a := make([]*asset.Asset, 0, len(s.assets)+len(assets))
+	a = append(a, s.assets...)
+
+	for _, n := range assets {
+		c := n.Clone()
+		replaced := false
+		for i := range a {
+			if a[i].Id == c.Id {
+				a[i] = c
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			a = append(a, c)
+		}
+	}
+
+	if err := validateAssets(a); err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{history: s.history, assets: a, quantities: s.quantities, latest: s.latest}, nil
+}
+
+// WithAssetPrice returns a new Snapshot with the unit price of the given asset set to price. The transaction history is shared with the
+// original snapshot.
+func (s *Snapshot) WithAssetPrice(assetId string, price *big.Rat) (*Snapshot, error) {
+	a, ok := s.asset(assetId)
+	if !ok {
+		return nil, fmt.Errorf("asset with key \"%s\" not found", assetId)
+	}
+
+	c := a.Clone()
+	c.UnitPrice = big.NewRat(0, 1).Set(price)
+	return s.WithAssets(c)
+}
+
+// asset returns the asset of the snapshot with the given ID. The asset is shared with the snapshot and must not be modified.
+func (s *Snapshot) asset(assetId string) (*asset.Asset, bool) {
+	for _, a := range s.assets {
+		if a.Id == assetId {
+			return a, true
+		}
+	}
+
+	return nil, false
+}
+
+// cloneTx copies the transaction including its quantity and unit price. The TxAsset is replaced with a new one holding only the ID, so
+// the copy can be added to a transaction.Context without affecting the context of the original.
+func cloneTx(t transaction.Tx) transaction.Tx {
+	c := t
+	c.Position = t.Position.Clone()
+	c.Asset = &transaction.TxAsset{Id: t.Asset.Id}
+	if t.Gross != nil {
+		c.Gross = big.NewRat(0, 1).Set(t.Gross)
+	}
+	if t.WithheldTax != nil {
+		c.WithheldTax = big.NewRat(0, 1).Set(t.WithheldTax)
+	}
+
+	return c
+}
+
+// validateAssets verifies that the assets form a valid asset.Context.
+func validateAssets(assets []*asset.Asset) error {
+	if len(assets) == 0 {
+		return nil
+	}
+
+	ctx := &asset.Context{}
+	return ctx.AddAssets(assets)
+}
+
+// zeroIfNil returns r or zero if r is nil.
+func zeroIfNil(r *big.Rat) *big.Rat {
+	if r == nil {
+		return big.NewRat(0, 1)
+	}
+
+	return r
+}
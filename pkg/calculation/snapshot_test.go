@@ -0,0 +1,181 @@
+package calculation_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/wlachs/wstonks/pkg/asset"
+	assetio "github.com/wlachs/wstonks/pkg/asset/io"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	txio "github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// snapshotTestSuite contains context information for testing portfolio snapshots.
+type snapshotTestSuite struct {
+	suite.Suite
+	ctx *calculation.Context
+}
+
+// TestSnapshotTestSuite initializes and executes the test suite.
+func TestSnapshotTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(snapshotTestSuite))
+}
+
+// SetupTest runs before each test case.
+func (suite *snapshotTestSuite) SetupTest() {
+	txCtx := transaction.Context{}
+	err := txio.TxCsvLoader{Path: "../../test/data/io/transactions/smoke_sales.csv"}.Load(&txCtx)
+	assert.NoError(suite.T(), err, "failed to load transaction context")
+
+	assetCtx := asset.Context{}
+	err = assetio.LiveAssetCsvLoader{Path: "../../test/data/io/assets/smoke_sales.csv"}.Load(&assetCtx)
+	assert.NoError(suite.T(), err, "failed to load asset context")
+
+	suite.ctx = &calculation.Context{AssetContext: &assetCtx, TransactionContext: &txCtx}
+}
+
+// snapshotContext returns the calculation context of the snapshot.
+func (suite *snapshotTestSuite) snapshotContext(s *calculation.Snapshot) *calculation.Context {
+	ctx, err := s.Context()
+	assert.NoError(suite.T(), err)
+
+	return ctx
+}
+
+// TestNewSnapshot checks that calculations on a snapshot yield the same results as on the source contexts.
+func (suite *snapshotTestSuite) TestNewSnapshot() {
+	s, err := calculation.NewSnapshot(suite.ctx.TransactionContext, suite.ctx.AssetContext)
+	assert.NoError(suite.T(), err)
+
+	expected, err := suite.ctx.GetAssetWorth()
+	assert.NoError(suite.T(), err)
+
+	ctx := suite.snapshotContext(s)
+	worth, err := ctx.GetAssetWorth()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), expected, worth, "worth should match")
+
+	sales, err := ctx.GetSalesForReturn(big.NewRat(1, 1))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), big.NewRat(10000, 71234), sales[ctx.AssetContext.GetAssetKeyMap()["A"]], "sell volume should match")
+}
+
+// TestNewSnapshot_Source_Modified checks that changes of the source contexts are not reflected in the snapshot.
+func (suite *snapshotTestSuite) TestNewSnapshot_Source_Modified() {
+	s, err := calculation.NewSnapshot(suite.ctx.TransactionContext, suite.ctx.AssetContext)
+	assert.NoError(suite.T(), err)
+	before, err := suite.snapshotContext(s).GetAssetWorth()
+	assert.NoError(suite.T(), err)
+
+	err = suite.ctx.AssetContext.AddAsset(&asset.Asset{Id: "A", UnitPrice: big.NewRat(1000, 1)})
+	assert.NoError(suite.T(), err)
+	suite.ctx.TransactionContext.Transactions[0].Quantity.SetInt64(1)
+
+	after, err := suite.snapshotContext(s).GetAssetWorth()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), before, after, "worth of the snapshot should not change")
+}
+
+// TestSnapshot_Context_Modified checks that modifying the contexts returned by Context does not affect the snapshot.
+func (suite *snapshotTestSuite) TestSnapshot_Context_Modified() {
+	s, err := calculation.NewSnapshot(suite.ctx.TransactionContext, suite.ctx.AssetContext)
+	assert.NoError(suite.T(), err)
+
+	ctx := suite.snapshotContext(s)
+	before, err := ctx.GetAssetWorth()
+	assert.NoError(suite.T(), err)
+
+	err = ctx.AssetContext.AddAsset(&asset.Asset{Id: "A", UnitPrice: big.NewRat(1000, 1)})
+	assert.NoError(suite.T(), err)
+	ctx.TransactionContext.Transactions[0].Quantity.SetInt64(1)
+
+	after, err := suite.snapshotContext(s).GetAssetWorth()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), before, after, "worth of the snapshot should not change")
+}
+
+// TestSnapshot_WithAssetPrice checks that updating a price creates a new snapshot with the same transaction history.
+func (suite *snapshotTestSuite) TestSnapshot_WithAssetPrice() {
+	s, err := calculation.NewSnapshot(suite.ctx.TransactionContext, suite.ctx.AssetContext)
+	assert.NoError(suite.T(), err)
+
+	updated, err := s.WithAssetPrice("A", big.NewRat(1000, 1))
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), s.Transactions(), updated.Transactions(), "transactions should match")
+	assert.NotEqual(suite.T(), big.NewRat(1000, 1), suite.snapshotContext(s).AssetContext.GetAssetKeyPriceMap()["A"])
+	assert.Equal(suite.T(), big.NewRat(1000, 1), suite.snapshotContext(updated).AssetContext.GetAssetKeyPriceMap()["A"])
+
+	_, err = s.WithAssetPrice("X", big.NewRat(1, 1))
+	assert.EqualError(suite.T(), err, "asset with key \"X\" not found")
+}
+
+// TestSnapshot_WithTransactions checks that adding transactions creates a new snapshot and leaves the original one untouched.
+func (suite *snapshotTestSuite) TestSnapshot_WithTransactions() {
+	s, err := calculation.NewSnapshot(suite.ctx.TransactionContext, suite.ctx.AssetContext)
+	assert.NoError(suite.T(), err)
+
+	quantity := suite.snapshotContext(s).TransactionContext.GetAssetKeyMap()["A"]
+	sell := transaction.Tx{
+		Position: transaction.Position{
+			Asset:     &transaction.TxAsset{Id: "A"},
+			Timestamp: time.Now(),
+			Quantity:  big.NewRat(1, 1),
+			UnitPrice: big.NewRat(100, 1),
+		},
+		Type: transaction.SELL,
+	}
+
+	updated, err := s.WithTransactions(sell)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), s.Assets(), updated.Assets(), "assets should match")
+	updatedQuantity := suite.snapshotContext(updated).TransactionContext.GetAssetKeyMap()["A"]
+	assert.Equal(suite.T(), big.NewRat(0, 1).Sub(quantity, big.NewRat(1, 1)), updatedQuantity)
+	originalQuantity := suite.snapshotContext(s).TransactionContext.GetAssetKeyMap()["A"]
+	assert.Equal(suite.T(), quantity, originalQuantity, "original snapshot should not change")
+	assert.Equal(suite.T(), len(s.Transactions())+1, len(updated.Transactions()))
+
+	sell.Quantity = big.NewRat(0, 1).Add(quantity, big.NewRat(1, 1))
+	_, err = s.WithTransactions(sell)
+	assert.Error(suite.T(), err, "selling more than held should fail")
+
+	sell.Quantity = quantity
+	_, err = updated.WithTransactions(sell)
+	assert.Error(suite.T(), err, "selling more than held after the previous sale should fail")
+}
+
+// TestSnapshot_WithTransactions_Branches checks that snapshots derived from the same snapshot don't see each other's transactions.
+func (suite *snapshotTestSuite) TestSnapshot_WithTransactions_Branches() {
+	s, err := calculation.NewSnapshot(suite.ctx.TransactionContext, suite.ctx.AssetContext)
+	assert.NoError(suite.T(), err)
+
+	buy := func(id string) transaction.Tx {
+		return transaction.Tx{
+			Position: transaction.Position{
+				Asset:     &transaction.TxAsset{Id: id},
+				Timestamp: time.UnixMilli(1800000000000),
+				Quantity:  big.NewRat(1, 1),
+				UnitPrice: big.NewRat(100, 1),
+			},
+			Type: transaction.BUY,
+		}
+	}
+
+	x, err := s.WithTransactions(buy("X"))
+	assert.NoError(suite.T(), err)
+	y, err := s.WithTransactions(buy("Y"))
+	assert.NoError(suite.T(), err)
+	xx, err := x.WithTransactions(buy("X"))
+	assert.NoError(suite.T(), err)
+
+	n := len(s.Transactions())
+	assert.Equal(suite.T(), "X", x.Transactions()[n].Asset.Id)
+	assert.Equal(suite.T(), "Y", y.Transactions()[n].Asset.Id)
+	assert.Equal(suite.T(), n+2, len(xx.Transactions()))
+	assert.Equal(suite.T(), big.NewRat(2, 1), suite.snapshotContext(xx).TransactionContext.GetAssetKeyMap()["X"])
+	assert.Empty(suite.T(), suite.snapshotContext(y).TransactionContext.GetAssetKeyMap()["X"])
+}
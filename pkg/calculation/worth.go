@@ -14,7 +14,7 @@ func (ctx *Context) GetAssetWorthMap() (map[*asset.Asset]*big.Rat, error) {
 		return nil, fmt.Errorf("asset context is missing")
 	}
 
-	assets := assetCtx.Assets
+	assets := assetCtx.GetAssets()
 	return ctx.GetAssetWorthMapOfAssets(assets)
 }
 
@@ -80,7 +80,7 @@ func (ctx *Context) GetAssetWorth() (*big.Rat, error) {
 		return nil, fmt.Errorf("asset context is missing")
 	}
 
-	assets := assetCtx.Assets
+	assets := assetCtx.GetAssets()
 	return ctx.GetAssetWorthOfAssets(assets)
 }
 
@@ -0,0 +1,83 @@
+package index
+
+import "sync"
+
+// Index maps the IDs of the entries of a slice to their positions in it. The slice itself is owned by the caller and passed to every call,
+// so the Index notices if it was changed without going through Append, e.g. by appending to it, deleting from it or replacing it. The zero
+// value is an empty Index ready to use. The methods are safe for concurrent use, as long as the slice isn't changed at the same time.
+type Index[T any] struct {
+	mu sync.Mutex
+	// positions maps the IDs to their positions in the slice the index was built from.
+	positions map[string]int
+	// first and length identify the slice the index was built from.
+	first  *T
+	length int
+}
+
+// Lookup returns the position of the entry with the given ID in the items or -1 if there is no such entry. The id function returns the ID
+// of an entry. Every hit is verified against the items, and the index is rebuilt if the items were changed since the last call. Entries
+// replaced directly in the items are noticed once the ID of the replaced entry is looked up. If an ID occurs more than once, the position of
+// its last occurrence is returned.
+func (x *Index[T]) Lookup(items []T, id func(T) string, key string) int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.current(items) {
+		x.rebuild(items, id)
+	}
+
+	i, ok := x.positions[key]
+	if !ok {
+		return -1
+	}
+
+	if id(items[i]) == key {
+		return i
+	}
+
+	x.rebuild(items, id)
+	if i, ok = x.positions[key]; ok {
+		return i
+	}
+
+	return -1
+}
+
+// Append appends the entry to the items, records its position in the index and returns the extended items, the same way as the builtin
+// append.
+func (x *Index[T]) Append(items []T, id func(T) string, item T) []T {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.current(items) {
+		x.rebuild(items, id)
+	}
+
+	items = append(items, item)
+	x.positions[id(item)] = len(items) - 1
+	x.first, x.length = &items[0], len(items)
+	return items
+}
+
+// current checks whether the index was built from the given items. Only the location and length of the slice are compared, so the check
+// doesn't depend on the number of entries. The caller must hold mu.
+func (x *Index[T]) current(items []T) bool {
+	if x.positions == nil || x.length != len(items) {
+		return false
+	}
+
+	return len(items) == 0 || x.first == &items[0]
+}
+
+// rebuild recreates the index from the items. The caller must hold mu.
+func (x *Index[T]) rebuild(items []T, id func(T) string) {
+	x.positions = make(map[string]int, len(items))
+	for i, item := range items {
+		x.positions[id(item)] = i
+	}
+
+	x.first, x.length = nil, len(items)
+	if len(items) > 0 {
+		x.first = &items[0]
+	}
+}
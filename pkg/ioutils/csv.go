@@ -1,9 +1,14 @@
 package ioutils
 
 import (
+	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"strings"
 )
 
 // ReadCsvFile tries to open and read a CSV file on the given path as a slice of string slices.
@@ -20,6 +25,109 @@ func ReadCsvFile(path string) ([][]string, error) {
 		}
 	}(f)
 
-	csvReader := csv.NewReader(f)
+	return ReadCsv(f)
+}
+
+// ReadCsvFS tries to open and read a CSV file on the given path of the file system as a slice of string slices.
+func ReadCsvFS(fsys fs.FS, path string) ([][]string, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file \"%s\"", path)
+	}
+
+	defer func(f fs.File) {
+		cerr := f.Close()
+		if cerr != nil {
+			err = cerr
+		}
+	}(f)
+
+	return ReadCsv(f)
+}
+
+// ReadCsv reads the CSV content of the reader as a slice of string slices. Rows may have a variable number of fields, e.g. to allow optional
+// trailing columns.
+func ReadCsv(r io.Reader) ([][]string, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = -1
 	return csvReader.ReadAll()
 }
+
+// ReadCsvStream reads the CSV content of the reader row by row and calls fn for every row, so the content never has to be held in memory
+// as a whole. Besides the row, fn receives the number of bytes read from the reader so far, e.g. to report progress. The row slice is
+// reused between calls and must not be retained by fn. Reading stops at the first error returned by fn or as soon as ctx is cancelled, in
+// which case the error of ctx is returned.
+func ReadCsvStream(ctx context.Context, r io.Reader, fn func(row []string, offset int64) error) error {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = -1
+	csvReader.ReuseRecord = true
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		row, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = fn(row, csvReader.InputOffset()); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadCsvFileWithComma tries to open and read a CSV file with the given field delimiter on the given path as a slice of string slices.
+// Like ReadCsvFile, rows may have a variable number of fields. Additionally, a leading UTF-8 byte order mark is removed, as is common for
+// spreadsheet exports.
+func ReadCsvFileWithComma(path string, comma rune) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file \"%s\"", path)
+	}
+
+	defer func(f *os.File) {
+		cerr := f.Close()
+		if cerr != nil {
+			err = cerr
+		}
+	}(f)
+
+	csvReader := csv.NewReader(f)
+	csvReader.Comma = comma
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	}
+
+	return rows, nil
+}
+
+// WriteCsvFile creates or truncates the file on the given path and writes the rows into it as CSV.
+func WriteCsvFile(path string, rows [][]string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file \"%s\"", path)
+	}
+
+	defer func(f *os.File) {
+		cerr := f.Close()
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+	}(f)
+
+	csvWriter := csv.NewWriter(f)
+	return csvWriter.WriteAll(rows)
+}
@@ -3,6 +3,7 @@ package ioutils
 import (
 	"fmt"
 	"math/big"
+	"strings"
 )
 
 // ParseRat converts the number string to big.Rat
@@ -14,3 +15,113 @@ func ParseRat(s string) (*big.Rat, error) {
 
 	return rat, nil
 }
+
+// ParseLocalizedRat converts a localized number string, e.g. "1.234,56" with ',' as decimal separator, to big.Rat. Every '.' or ','
+// that is not the decimal separator is treated as a digit group separator and removed, as are spaces.
+func ParseLocalizedRat(s string, decimalSeparator rune) (*big.Rat, error) {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case r == decimalSeparator:
+			b.WriteRune('.')
+		case r == '.' || r == ',' || r == ' ' || r == '\u00a0' || r == '\'':
+			// digit group separator
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	rat, err := ParseRat(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse numeric string %s", s)
+	}
+
+	return rat, nil
+}
+
+// FormatRat converts the big.Rat to a string without losing precision. If the number has a finite decimal representation, it is
+// formatted as a decimal number, e.g. "12.5", otherwise as a fraction, e.g. "1/3". Both representations can be read by ParseRat.
+func FormatRat(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	/* A reduced fraction has a finite decimal representation if the denominator has no prime factors other than 2 and 5. */
+	d := new(big.Int).Set(r.Denom())
+	twos := removeFactor(d, 2)
+	fives := removeFactor(d, 5)
+
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return r.String()
+	}
+
+	return r.FloatString(max(twos, fives))
+}
+
+// removeFactor divides n by the given factor as long as it is divisible and returns the number of divisions.
+func removeFactor(n *big.Int, factor int64) int {
+	f := big.NewInt(factor)
+	q, m := new(big.Int), new(big.Int)
+	count := 0
+
+	for {
+		q.QuoRem(n, f, m)
+		if m.Sign() != 0 {
+			return count
+		}
+		n.Set(q)
+		count++
+	}
+}
+
+// Rounding holds the rounding modes supported by FormatRatWithPrecision as a pseudo-enum.
+type Rounding = int
+
+const (
+	// RoundExact disables rounding, the number is formatted by FormatRat.
+	RoundExact Rounding = iota
+	// RoundHalfUp rounds to the nearest neighbour, ties are rounded away from zero.
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest neighbour, ties are rounded to the even neighbour.
+	RoundHalfEven
+	// RoundDown truncates the number towards zero.
+	RoundDown
+	// RoundUp rounds the number away from zero.
+	RoundUp
+)
+
+// FormatRatWithPrecision converts the big.Rat to a decimal string with the given number of decimals using the given rounding mode. If the
+// rounding mode is RoundExact, the number of decimals is ignored and the number is formatted without losing precision.
+func FormatRatWithPrecision(r *big.Rat, decimals int, rounding Rounding) string {
+	if rounding == RoundExact {
+		return FormatRat(r)
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(decimals, 0))), nil)
+	num := new(big.Int).Mul(r.Num(), scale)
+	q, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+
+	if rem.Sign() != 0 {
+		/* Compare the remainder with half of the denominator: 2*|rem| <=> denom */
+		half := new(big.Int).Abs(rem)
+		half.Lsh(half, 1)
+		cmp := half.Cmp(r.Denom())
+
+		awayFromZero := false
+		switch rounding {
+		case RoundHalfUp:
+			awayFromZero = cmp >= 0
+		case RoundHalfEven:
+			awayFromZero = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+		case RoundUp:
+			awayFromZero = true
+		default:
+		}
+
+		if awayFromZero {
+			q.Add(q, big.NewInt(int64(r.Sign())))
+		}
+	}
+
+	return new(big.Rat).SetFrac(q, scale).FloatString(max(decimals, 0))
+}
@@ -0,0 +1,207 @@
+package ioutils
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OfxElement is a node of an OFX document. Aggregates hold child elements, data elements hold a value.
+type OfxElement struct {
+	Name     string
+	Value    string
+	Children []*OfxElement
+}
+
+// ReadOfxFile tries to open and parse an OFX or QFX file on the given path.
+func ReadOfxFile(path string) (*OfxElement, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file \"%s\"", path)
+	}
+
+	return ParseOfx(string(content))
+}
+
+// ParseOfx parses an OFX document and returns its root element. Both the SGML based OFX 1.x format, where the closing tags of data
+// elements are omitted, and the XML based OFX 2.x format are supported. Everything before the <OFX> tag, i.e. the headers, is ignored.
+func ParseOfx(content string) (*OfxElement, error) {
+	start := strings.Index(strings.ToUpper(content), "<OFX>")
+	if start == -1 {
+		return nil, fmt.Errorf("missing OFX root element")
+	}
+
+	root := &OfxElement{}
+	stack := []*OfxElement{root}
+	rest := content[start:]
+
+	for {
+		open := strings.IndexByte(rest, '<')
+		if open == -1 {
+			break
+		}
+
+		if strings.HasPrefix(rest[open:], "<!--") {
+			end := strings.Index(rest[open:], "-->")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated OFX comment")
+			}
+			rest = rest[open+end+3:]
+			continue
+		}
+
+		end := strings.IndexByte(rest[open:], '>')
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated OFX tag")
+		}
+
+		tag := strings.ToUpper(strings.TrimSpace(rest[open+1 : open+end]))
+		rest = rest[open+end+1:]
+
+		if strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!") {
+			continue
+		}
+
+		/* Closing tag: close every unclosed aggregate up to the matching one. Closing tags of data elements are not on the stack. */
+		if strings.HasPrefix(tag, "/") {
+			name := tag[1:]
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].Name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+			continue
+		}
+
+		element := &OfxElement{Name: tag}
+		parent := stack[len(stack)-1]
+		parent.Children = append(parent.Children, element)
+
+		next := strings.IndexByte(rest, '<')
+		if next == -1 {
+			next = len(rest)
+		}
+
+		value := strings.TrimSpace(rest[:next])
+		if value != "" {
+			element.Value = html.UnescapeString(value)
+			rest = rest[next:]
+			continue
+		}
+
+		stack = append(stack, element)
+	}
+
+	if len(root.Children) == 0 {
+		return nil, fmt.Errorf("missing OFX root element")
+	}
+
+	return root.Children[0], nil
+}
+
+// Child returns the first direct child element with the given name or nil if there is none.
+func (e *OfxElement) Child(name string) *OfxElement {
+	if e == nil {
+		return nil
+	}
+
+	for _, c := range e.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Find follows the given path of element names starting from the element and returns the element at the end of the path or nil if the
+// path does not exist.
+func (e *OfxElement) Find(path ...string) *OfxElement {
+	current := e
+	for _, name := range path {
+		current = current.Child(name)
+	}
+
+	return current
+}
+
+// Text returns the value of the element at the end of the given path or an empty string if the path does not exist.
+func (e *OfxElement) Text(path ...string) string {
+	element := e.Find(path...)
+	if element == nil {
+		return ""
+	}
+
+	return element.Value
+}
+
+// FindAll returns every descendant element with the given name in document order.
+func (e *OfxElement) FindAll(name string) []*OfxElement {
+	if e == nil {
+		return nil
+	}
+
+	var elements []*OfxElement
+	for _, c := range e.Children {
+		if c.Name == name {
+			elements = append(elements, c)
+		}
+		elements = append(elements, c.FindAll(name)...)
+	}
+
+	return elements
+}
+
+// OfxTickers maps the unique IDs of the securities listed in the security list of the OFX document to their ticker symbols.
+func OfxTickers(root *OfxElement) map[string]string {
+	m := map[string]string{}
+	for _, info := range root.FindAll("SECINFO") {
+		uniqueId := info.Text("SECID", "UNIQUEID")
+		ticker := info.Text("TICKER")
+		if uniqueId != "" && ticker != "" {
+			m[uniqueId] = ticker
+		}
+	}
+
+	return m
+}
+
+// ParseOfxDate converts an OFX date-time string, e.g. "20240105103000.000[-5:EST]", to time.Time. If no time zone offset is given, UTC is
+// assumed.
+func ParseOfxDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	value, zone, _ := strings.Cut(s, "[")
+
+	digits := value
+	if i := strings.IndexByte(value, '.'); i != -1 {
+		digits = value[:i]
+	}
+
+	var layout string
+	switch len(digits) {
+	case 8:
+		layout = "20060102"
+	case 12:
+		layout = "200601021504"
+	case 14:
+		layout = "20060102150405"
+	default:
+		return time.Time{}, fmt.Errorf("unsupported OFX date \"%s\"", s)
+	}
+
+	location := time.UTC
+	if zone != "" {
+		offset, name, _ := strings.Cut(strings.TrimSuffix(zone, "]"), ":")
+		hours, err := strconv.ParseFloat(offset, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unsupported OFX date \"%s\"", s)
+		}
+		location = time.FixedZone(name, int(hours*3600))
+	}
+
+	return time.ParseInLocation(layout, digits, location)
+}
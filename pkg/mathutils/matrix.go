@@ -0,0 +1,79 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+)
+
+// choleskyTolerance is the tolerance of Cholesky relative to the diagonal, below which pivots are treated as zero.
+const choleskyTolerance = 1e-10
+
+// Cholesky calculates the lower triangular matrix L of the Cholesky decomposition A = L·Lᵀ of a symmetric, positive semidefinite matrix.
+// Pivots that are zero up to rounding errors, e.g. of a correlation matrix of perfectly correlated assets, are clamped to zero along with
+// the rest of their column, so L·Lᵀ still equals A.
+func Cholesky(a [][]float64) ([][]float64, error) {
+	n := len(a)
+	for i, row := range a {
+		if len(row) != n {
+			return nil, fmt.Errorf("matrix is not square: row %d has %d columns instead of %d", i, len(row), n)
+		}
+	}
+
+	l := make([][]float64, n)
+	for i := range l {
+		l[i] = make([]float64, n)
+	}
+
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			if math.Abs(a[i][j]-a[j][i]) > 1e-12 {
+				return nil, fmt.Errorf("matrix is not symmetric at (%d, %d)", i, j)
+			}
+
+			sum := a[i][j]
+			for k := 0; k < j; k++ {
+				sum -= l[i][k] * l[j][k]
+			}
+
+			tolerance := choleskyTolerance * math.Max(1, math.Max(math.Abs(a[i][i]), math.Abs(a[j][j])))
+			switch {
+			case i == j && sum < -tolerance:
+				return nil, fmt.Errorf("matrix is not positive semidefinite")
+			case i == j && sum <= tolerance:
+				l[i][i] = 0
+			case i == j:
+				l[i][i] = math.Sqrt(sum)
+			case l[j][j] != 0:
+				l[i][j] = sum / l[j][j]
+			case math.Abs(sum) > tolerance:
+				/* A zero pivot requires the rest of its column to vanish as well. */
+				return nil, fmt.Errorf("matrix is not positive semidefinite")
+			}
+		}
+	}
+
+	return l, nil
+}
+
+// Identity creates an n×n identity matrix.
+func Identity(n int) [][]float64 {
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+		m[i][i] = 1
+	}
+
+	return m
+}
+
+// MulVec multiplies the matrix with the vector.
+func MulVec(m [][]float64, v []float64) []float64 {
+	r := make([]float64, len(m))
+	for i, row := range m {
+		for j, x := range row {
+			r[i] += x * v[j]
+		}
+	}
+
+	return r
+}
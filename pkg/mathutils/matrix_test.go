@@ -0,0 +1,57 @@
+package mathutils_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/mathutils"
+	"testing"
+)
+
+// TestCholesky tests the decomposition of a positive definite matrix.
+func TestCholesky(t *testing.T) {
+	t.Parallel()
+
+	l, err := mathutils.Cholesky([][]float64{{4, 2}, {2, 5}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, [][]float64{{2, 0}, {1, 2}}, l)
+}
+
+// TestCholesky_Singular tests the decomposition of a singular correlation matrix of perfectly correlated assets.
+func TestCholesky_Singular(t *testing.T) {
+	t.Parallel()
+
+	a := [][]float64{{1, 1, 0.5}, {1, 1, 0.5}, {0.5, 0.5, 1}}
+	l, err := mathutils.Cholesky(a)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, l[1][1], "the pivot of the dependent row should be clamped to zero")
+	for i := range a {
+		for j := range a {
+			sum := 0.0
+			for k := range a {
+				sum += l[i][k] * l[j][k]
+			}
+			assert.InDelta(t, a[i][j], sum, 1e-12, "L·Lᵀ should equal A at (%d, %d)", i, j)
+		}
+	}
+}
+
+// TestCholesky_Not_Positive_Semidefinite tests the decomposition of a matrix that is not positive semidefinite.
+func TestCholesky_Not_Positive_Semidefinite(t *testing.T) {
+	t.Parallel()
+
+	_, err := mathutils.Cholesky([][]float64{{1, 2}, {2, 1}})
+	assert.EqualError(t, err, "matrix is not positive semidefinite")
+
+	_, err = mathutils.Cholesky([][]float64{{0, 1}, {1, 1}})
+	assert.EqualError(t, err, "matrix is not positive semidefinite")
+}
+
+// TestCholesky_Not_Symmetric tests the decomposition of a matrix that is not symmetric.
+func TestCholesky_Not_Symmetric(t *testing.T) {
+	t.Parallel()
+
+	_, err := mathutils.Cholesky([][]float64{{1, 0.5}, {0.2, 1}})
+
+	assert.EqualError(t, err, "matrix is not symmetric at (1, 0)")
+}
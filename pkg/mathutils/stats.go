@@ -0,0 +1,89 @@
+package mathutils
+
+import (
+	"math"
+	"slices"
+)
+
+// Percentile calculates the p-th percentile (0 ≤ p ≤ 100) of the values using linear interpolation between the closest ranks. The values
+// are not modified. NaN is returned for an empty slice.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	return PercentileSorted(sorted, p)
+}
+
+// PercentileSorted calculates the p-th percentile (0 ≤ p ≤ 100) of values sorted in ascending order using linear interpolation between
+// the closest ranks. NaN is returned for an empty slice.
+func PercentileSorted(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+
+	rank := math.Min(math.Max(p, 0), 100) / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (rank-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+// Mean calculates the arithmetic mean of the values. NaN is returned for an empty slice.
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+
+	return sum / float64(len(values))
+}
+
+// StdDev calculates the sample standard deviation of the values. NaN is returned for less than two values.
+func StdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return math.NaN()
+	}
+
+	m := Mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+// Covariance calculates the sample covariance of two equally long slices of values. NaN is returned for less than two values or if the
+// lengths differ.
+func Covariance(x []float64, y []float64) float64 {
+	if len(x) < 2 || len(x) != len(y) {
+		return math.NaN()
+	}
+
+	mx, my := Mean(x), Mean(y)
+	sum := 0.0
+	for i := range x {
+		sum += (x[i] - mx) * (y[i] - my)
+	}
+
+	return sum / float64(len(x)-1)
+}
+
+// Correlation calculates the Pearson correlation coefficient of two equally long slices of values. NaN is returned if the covariance is
+// undefined or one of the slices is constant.
+func Correlation(x []float64, y []float64) float64 {
+	sx, sy := StdDev(x), StdDev(y)
+	if sx == 0 || sy == 0 {
+		return math.NaN()
+	}
+
+	return Covariance(x, y) / (sx * sy)
+}
@@ -0,0 +1,42 @@
+package mathutils_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/mathutils"
+	"math"
+	"testing"
+)
+
+// TestPercentile tests interpolating percentiles of unsorted values.
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	values := []float64{4, 1, 3, 2, 5}
+
+	assert.Equal(t, 1.0, mathutils.Percentile(values, 0))
+	assert.Equal(t, 3.0, mathutils.Percentile(values, 50))
+	assert.Equal(t, 4.6, math.Round(mathutils.Percentile(values, 90)*10)/10)
+	assert.Equal(t, 5.0, mathutils.Percentile(values, 100))
+	assert.Equal(t, []float64{4, 1, 3, 2, 5}, values, "values should not be modified")
+	assert.True(t, math.IsNaN(mathutils.Percentile(nil, 50)))
+}
+
+// TestStdDev tests the sample standard deviation.
+func TestStdDev(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, math.Sqrt(2.5), mathutils.StdDev([]float64{1, 2, 3, 4, 5}))
+	assert.True(t, math.IsNaN(mathutils.StdDev([]float64{1})))
+}
+
+// TestCorrelation tests the covariance and correlation of perfectly correlated, anti-correlated and constant values.
+func TestCorrelation(t *testing.T) {
+	t.Parallel()
+
+	x := []float64{1, 2, 3, 4}
+	assert.InDelta(t, 5.0/3, mathutils.Covariance(x, x), 1e-12)
+	assert.InDelta(t, 1.0, mathutils.Correlation(x, []float64{2, 4, 6, 8}), 1e-12)
+	assert.InDelta(t, -1.0, mathutils.Correlation(x, []float64{4, 3, 2, 1}), 1e-12)
+	assert.True(t, math.IsNaN(mathutils.Correlation(x, []float64{1, 1, 1, 1})))
+	assert.True(t, math.IsNaN(mathutils.Covariance(x, []float64{1, 2})))
+}
@@ -0,0 +1,242 @@
+package optimize
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"math"
+	"slices"
+)
+
+// Group limits the overall weight of a group of assets, e.g. of an asset class or a region.
+type Group struct {
+	Name string
+	// Tag adds every asset with the tag to the group.
+	Tag string
+	// Assets adds the listed assets to the group.
+	Assets []*asset.Asset
+	Min    float64
+	Max    float64
+}
+
+// Constraints restrict the weights of the assets. Weights are always between zero and one and add up to one.
+type Constraints struct {
+	// MinWeight holds the minimum weight of an asset. Assets not listed have no minimum weight.
+	MinWeight map[*asset.Asset]float64
+	// MaxWeight holds the maximum weight of an asset. Assets not listed have no maximum weight.
+	MaxWeight map[*asset.Asset]float64
+	Groups    []Group
+}
+
+// tolerance is the maximum violation of a constraint accepted in a solution.
+const tolerance = 1e-7
+
+// slab restricts the weighted sum of the weights to an interval: lower ≤ Σ coefficients[i] * w[i] ≤ upper.
+type slab struct {
+	coefficients []float64
+	lower        float64
+	upper        float64
+}
+
+// feasibleSet is the set of weights satisfying the constraints: the weights are within their bounds, add up to one and satisfy every
+// slab.
+type feasibleSet struct {
+	lower  []float64
+	upper  []float64
+	slabs  []slab
+	labels []string
+}
+
+// newFeasibleSet converts the constraints of the assets to a feasibleSet and checks that they don't contradict each other obviously.
+func newFeasibleSet(assets []*asset.Asset, c Constraints) (*feasibleSet, error) {
+	n := len(assets)
+	s := &feasibleSet{lower: make([]float64, n), upper: make([]float64, n)}
+
+	minSum, maxSum := 0.0, 0.0
+	for i, a := range assets {
+		s.lower[i], s.upper[i] = 0, 1
+		if w, ok := c.MinWeight[a]; ok {
+			s.lower[i] = w
+		}
+		if w, ok := c.MaxWeight[a]; ok {
+			s.upper[i] = w
+		}
+
+		if s.lower[i] < 0 || s.upper[i] > 1 || s.lower[i] > s.upper[i] {
+			return nil, fmt.Errorf("invalid weight limits of %s", a.Id)
+		}
+
+		minSum += s.lower[i]
+		maxSum += s.upper[i]
+	}
+
+	if minSum > 1+tolerance || maxSum < 1-tolerance {
+		return nil, fmt.Errorf("the weight limits don't allow the weights to add up to one")
+	}
+
+	for _, g := range c.Groups {
+		if g.Min > g.Max {
+			return nil, fmt.Errorf("invalid weight limits of group %s", g.Name)
+		}
+
+		coefficients := make([]float64, n)
+		for i, a := range assets {
+			if slices.Contains(g.Assets, a) || (g.Tag != "" && slices.Contains(a.Tags, g.Tag)) {
+				coefficients[i] = 1
+			}
+		}
+
+		s.addSlab(fmt.Sprintf("group %s", g.Name), coefficients, g.Min, g.Max)
+	}
+
+	return s, nil
+}
+
+// addSlab adds a slab to the feasible set. The label is used in error messages.
+func (s *feasibleSet) addSlab(label string, coefficients []float64, lower float64, upper float64) {
+	s.slabs = append(s.slabs, slab{coefficients: coefficients, lower: lower, upper: upper})
+	s.labels = append(s.labels, label)
+}
+
+// project finds the feasible weights closest to v. The projection onto the intersection of the bounded simplex and the slabs is calculated
+// with Dykstra's alternating projection algorithm. An error is returned if the constraints cannot be satisfied.
+func (s *feasibleSet) project(v []float64) ([]float64, error) {
+	x := s.projectSimplex(v)
+	if len(s.slabs) == 0 {
+		return x, nil
+	}
+
+	increments := make([][]float64, len(s.slabs)+1)
+	for i := range increments {
+		increments[i] = make([]float64, len(v))
+	}
+
+	for range 10000 {
+		previous := slices.Clone(x)
+		for j := range increments {
+			y := make([]float64, len(x))
+			for i := range x {
+				y[i] = x[i] + increments[j][i]
+			}
+
+			var projected []float64
+			if j == 0 {
+				projected = s.projectSimplex(y)
+			} else {
+				projected = s.slabs[j-1].project(y)
+			}
+
+			for i := range x {
+				increments[j][i] = y[i] - projected[i]
+			}
+			x = projected
+		}
+
+		if distance(x, previous) < 1e-13 {
+			break
+		}
+	}
+
+	return x, s.check(x)
+}
+
+// projectSimplex finds the weights within their bounds adding up to one closest to v, by shifting every value by the same amount and
+// clamping it to its bounds. The shift is found by bisection.
+func (s *feasibleSet) projectSimplex(v []float64) []float64 {
+	low, high := math.Inf(1), math.Inf(-1)
+	for i := range v {
+		low = math.Min(low, v[i]-s.upper[i])
+		high = math.Max(high, v[i]-s.lower[i])
+	}
+
+	w := make([]float64, len(v))
+	for range 200 {
+		shift := (low + high) / 2
+		sum := 0.0
+		for i := range v {
+			w[i] = math.Min(math.Max(v[i]-shift, s.lower[i]), s.upper[i])
+			sum += w[i]
+		}
+
+		if sum > 1 {
+			low = shift
+		} else {
+			high = shift
+		}
+	}
+
+	return w
+}
+
+// project finds the point of the slab closest to v.
+func (s slab) project(v []float64) []float64 {
+	sum, norm := 0.0, 0.0
+	for i := range v {
+		sum += s.coefficients[i] * v[i]
+		norm += s.coefficients[i] * s.coefficients[i]
+	}
+
+	w := slices.Clone(v)
+	if norm == 0 {
+		return w
+	}
+
+	var diff float64
+	switch {
+	case sum < s.lower:
+		diff = s.lower - sum
+	case sum > s.upper:
+		diff = s.upper - sum
+	default:
+		return w
+	}
+
+	for i := range w {
+		w[i] += diff / norm * s.coefficients[i]
+	}
+
+	return w
+}
+
+// check verifies that the weights satisfy every constraint.
+func (s *feasibleSet) check(w []float64) error {
+	sum := 0.0
+	for i := range w {
+		if w[i] < s.lower[i]-tolerance || w[i] > s.upper[i]+tolerance {
+			return fmt.Errorf("the constraints cannot be satisfied")
+		}
+		sum += w[i]
+	}
+
+	if math.Abs(sum-1) > tolerance {
+		return fmt.Errorf("the constraints cannot be satisfied")
+	}
+
+	for j, sl := range s.slabs {
+		v := dot(sl.coefficients, w)
+		if v < sl.lower-tolerance || v > sl.upper+tolerance {
+			return fmt.Errorf("the constraints of %s cannot be satisfied", s.labels[j])
+		}
+	}
+
+	return nil
+}
+
+// dot calculates the dot product of two vectors.
+func dot(a []float64, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+
+	return sum
+}
+
+// distance calculates the Euclidean distance of two vectors.
+func distance(a []float64, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += (a[i] - b[i]) * (a[i] - b[i])
+	}
+
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,71 @@
+package optimize
+
+import (
+	"cmp"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"math"
+	"math/big"
+	"slices"
+)
+
+// precision is the denominator the weights of a distribution are rounded to.
+const precision = 1_000_000
+
+// toDistribution converts the weights of the assets to a distribution accepted by the distribution adjustment functions of the calculation
+// package. The weights are rounded to millionths with the largest remainder method, so the distribution adds up to exactly one: every
+// weight is rounded down, and the missing millionths go to the weights with the largest remainders. Only weights with room under their
+// maximum weight of the constraints are rounded up, and rounded weights never drop below their minimum weight.
+func toDistribution(assets []*asset.Asset, weights []float64, c Constraints) map[*asset.Asset]*big.Rat {
+	n := len(weights)
+	parts, lower, upper := make([]int64, n), make([]int64, n), make([]int64, n)
+	remainders := make([]float64, n)
+	order := make([]int, n)
+
+	sum := int64(0)
+	for i, w := range weights {
+		lower[i], upper[i] = 0, precision
+		if l, ok := c.MinWeight[assets[i]]; ok {
+			lower[i] = int64(math.Ceil(l*precision - tolerance))
+		}
+		if u, ok := c.MaxWeight[assets[i]]; ok {
+			upper[i] = int64(math.Floor(u*precision + tolerance))
+		}
+
+		scaled := math.Max(w, 0) * precision
+		parts[i] = min(max(int64(math.Floor(scaled)), lower[i]), upper[i])
+		remainders[i] = scaled - float64(parts[i])
+		sum += parts[i]
+		order[i] = i
+	}
+
+	/* Round up the largest remainders first, and round down the smallest ones first if the minimum weights exceed the total. */
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(remainders[b], remainders[a])
+	})
+
+	for changed := true; sum != precision && changed; {
+		changed = false
+		for k := range order {
+			if sum < precision {
+				if i := order[k]; parts[i] < upper[i] {
+					parts[i]++
+					sum++
+					changed = true
+				}
+			} else if sum > precision {
+				if i := order[n-1-k]; parts[i] > lower[i] {
+					parts[i]--
+					sum--
+					changed = true
+				}
+			}
+		}
+	}
+
+	m := map[*asset.Asset]*big.Rat{}
+	for i, a := range assets {
+		m[a] = big.NewRat(parts[i], precision)
+	}
+
+	return m
+}
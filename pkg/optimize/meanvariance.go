@@ -0,0 +1,333 @@
+package optimize
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/risk"
+	"math"
+	"math/big"
+	"slices"
+	"time"
+)
+
+// Optimizer finds the weights of assets in a portfolio based on the expected returns and covariances of the assets. Returns and
+// covariances refer to the same period, e.g. a year.
+type Optimizer struct {
+	// Assets defines the order of ExpectedReturns and of the rows and columns of Covariance.
+	Assets          []*asset.Asset
+	ExpectedReturns []float64
+	Covariance      [][]float64
+	Constraints     Constraints
+	RiskFreeRate    float64
+}
+
+// FrontierPoint is a portfolio on the efficient frontier.
+type FrontierPoint struct {
+	Return     float64
+	Volatility float64
+	// Weights holds the weights of the assets in the order of Optimizer.Assets.
+	Weights []float64
+}
+
+// NewOptimizer creates an Optimizer using the annualized mean returns and covariances of the historical prices of the assets between start
+// and end, as calculated by risk.Covariances. The risk-free rate is taken from the configuration.
+func NewOptimizer(h *asset.PriceHistory, assets []*asset.Asset, start time.Time, end time.Time, config risk.Config) (*Optimizer, error) {
+	ids := make([]string, len(assets))
+	for i, a := range assets {
+		ids[i] = a.Id
+	}
+
+	m, err := risk.Covariances(h, ids, start, end, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Optimizer{
+		Assets:          slices.Clone(assets),
+		ExpectedReturns: m.MeanReturns,
+		Covariance:      m.Covariance,
+		RiskFreeRate:    config.RiskFreeRate,
+	}, nil
+}
+
+// MinimumVariance finds the distribution with the lowest volatility satisfying the constraints.
+func (o *Optimizer) MinimumVariance() (map[*asset.Asset]*big.Rat, error) {
+	s, err := o.feasibleSet()
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := o.minimizeVariance(s, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDistribution(o.Assets, w, o.Constraints), nil
+}
+
+// TargetReturn finds the distribution with the lowest volatility satisfying the constraints with the given expected return.
+func (o *Optimizer) TargetReturn(r float64) (map[*asset.Asset]*big.Rat, error) {
+	w, err := o.targetReturn(r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDistribution(o.Assets, w, o.Constraints), nil
+}
+
+// MaxSharpe finds the distribution with the highest Sharpe ratio satisfying the constraints, i.e. the tangency portfolio on the efficient
+// frontier.
+func (o *Optimizer) MaxSharpe() (map[*asset.Asset]*big.Rat, error) {
+	low, high, err := o.returnRange()
+	if err != nil {
+		return nil, err
+	}
+
+	var best []float64
+	bestSharpe := math.Inf(-1)
+	sharpe := func(r float64) (float64, error) {
+		w, targetErr := o.targetReturn(r, best)
+		if targetErr != nil {
+			return 0, targetErr
+		}
+
+		volatility := math.Sqrt(o.variance(w))
+		sr := math.Inf(1)
+		if volatility > 0 {
+			sr = (dot(o.ExpectedReturns, w) - o.RiskFreeRate) / volatility
+		}
+
+		if sr > bestSharpe {
+			best, bestSharpe = w, sr
+		}
+
+		return sr, nil
+	}
+
+	/* The Sharpe ratio along the efficient frontier is unimodal, so the maximum can be found by golden-section search on the return. */
+	ratio := (math.Sqrt(5) - 1) / 2
+	a, b := low, high
+	c, d := b-ratio*(b-a), a+ratio*(b-a)
+	fc, err := sharpe(c)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := sharpe(d)
+	if err != nil {
+		return nil, err
+	}
+
+	for b-a > 1e-9*math.Max(1, math.Abs(b)) {
+		if fc >= fd {
+			b, d, fd = d, c, fc
+			c = b - ratio*(b-a)
+			if fc, err = sharpe(c); err != nil {
+				return nil, err
+			}
+		} else {
+			a, c, fc = c, d, fd
+			d = a + ratio*(b-a)
+			if fd, err = sharpe(d); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, r := range []float64{low, high} {
+		if _, err = sharpe(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return toDistribution(o.Assets, best, o.Constraints), nil
+}
+
+// Frontier calculates the given number of portfolios on the efficient frontier, evenly spaced between the return of the minimum variance
+// portfolio and the highest return possible.
+func (o *Optimizer) Frontier(points int) ([]FrontierPoint, error) {
+	if points < 2 {
+		return nil, fmt.Errorf("the frontier needs at least two points")
+	}
+
+	low, high, err := o.returnRange()
+	if err != nil {
+		return nil, err
+	}
+
+	frontier := make([]FrontierPoint, 0, points)
+	var previous []float64
+	for i := range points {
+		r := low + (high-low)*float64(i)/float64(points-1)
+		w, targetErr := o.targetReturn(r, previous)
+		if targetErr != nil {
+			return nil, targetErr
+		}
+
+		frontier = append(frontier, FrontierPoint{Return: dot(o.ExpectedReturns, w), Volatility: math.Sqrt(o.variance(w)), Weights: w})
+		previous = w
+	}
+
+	return frontier, nil
+}
+
+// validate checks that the dimensions of the expected returns and the covariance matrix match the assets.
+func (o *Optimizer) validate() error {
+	n := len(o.Assets)
+	if n == 0 {
+		return fmt.Errorf("no assets to optimize")
+	}
+
+	if len(o.ExpectedReturns) != n || len(o.Covariance) != n {
+		return fmt.Errorf("expected returns and covariance matrix don't match the %d assets", n)
+	}
+
+	for _, row := range o.Covariance {
+		if len(row) != n {
+			return fmt.Errorf("the covariance matrix should be %d×%d", n, n)
+		}
+	}
+
+	return nil
+}
+
+// feasibleSet validates the Optimizer and returns the weights satisfying the constraints.
+func (o *Optimizer) feasibleSet() (*feasibleSet, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	return newFeasibleSet(o.Assets, o.Constraints)
+}
+
+// returnRange calculates the return of the minimum variance portfolio and the highest return possible under the constraints.
+func (o *Optimizer) returnRange() (float64, float64, error) {
+	s, err := o.feasibleSet()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	w, err := o.minimizeVariance(s, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	low := dot(o.ExpectedReturns, w)
+
+	w, err = o.maximizeReturn(s)
+	if err != nil {
+		return 0, 0, err
+	}
+	high := dot(o.ExpectedReturns, w)
+
+	return low, math.Max(low, high), nil
+}
+
+// targetReturn finds the weights with the lowest variance and the given expected return, starting from the given weights if not nil.
+func (o *Optimizer) targetReturn(r float64, start []float64) ([]float64, error) {
+	s, err := o.feasibleSet()
+	if err != nil {
+		return nil, err
+	}
+
+	s.addSlab("the target return", o.ExpectedReturns, r, r)
+	w, err := o.minimizeVariance(s, start)
+	if err != nil {
+		return nil, fmt.Errorf("the target return %v cannot be reached: %w", r, err)
+	}
+
+	return w, nil
+}
+
+// minimizeVariance minimizes the variance of the portfolio within the feasible set with projected gradient descent, starting from the given
+// weights or from equal weights if nil.
+func (o *Optimizer) minimizeVariance(s *feasibleSet, start []float64) ([]float64, error) {
+	/* The trace bounds the largest eigenvalue of the covariance matrix, so 1 / (2 trace) is a safe step size. */
+	trace := 0.0
+	for i := range o.Covariance {
+		trace += o.Covariance[i][i]
+	}
+	step := 1 / math.Max(2*trace, 1e-12)
+
+	return descend(s, start, len(o.Assets), func(w []float64) []float64 {
+		g := make([]float64, len(w))
+		for i := range w {
+			for j := range w {
+				g[i] += 2 * o.Covariance[i][j] * w[j]
+			}
+			g[i] *= step
+		}
+
+		return g
+	})
+}
+
+// maximizeReturn maximizes the expected return of the portfolio within the feasible set with projected gradient ascent.
+func (o *Optimizer) maximizeReturn(s *feasibleSet) ([]float64, error) {
+	norm := math.Sqrt(dot(o.ExpectedReturns, o.ExpectedReturns))
+	if norm == 0 {
+		return s.project(equalWeights(len(o.Assets)))
+	}
+
+	return descend(s, nil, len(o.Assets), func(w []float64) []float64 {
+		g := make([]float64, len(w))
+		for i := range w {
+			g[i] = -o.ExpectedReturns[i] / norm
+		}
+
+		return g
+	})
+}
+
+// variance calculates the variance of the portfolio with the given weights.
+func (o *Optimizer) variance(w []float64) float64 {
+	variance := 0.0
+	for i := range w {
+		for j := range w {
+			variance += w[i] * w[j] * o.Covariance[i][j]
+		}
+	}
+
+	return variance
+}
+
+// descend runs projected gradient descent within the feasible set. The step function returns the gradient at the given weights multiplied
+// by the step size.
+func descend(s *feasibleSet, start []float64, n int, step func(w []float64) []float64) ([]float64, error) {
+	if start == nil {
+		start = equalWeights(n)
+	}
+
+	w, err := s.project(start)
+	if err != nil {
+		return nil, err
+	}
+
+	for range 20000 {
+		g := step(w)
+		v := make([]float64, n)
+		for i := range w {
+			v[i] = w[i] - g[i]
+		}
+
+		next, projectErr := s.project(v)
+		if projectErr != nil {
+			return nil, projectErr
+		}
+
+		if distance(next, w) < 1e-12 {
+			return next, nil
+		}
+		w = next
+	}
+
+	return w, nil
+}
+
+// equalWeights creates n equal weights adding up to one.
+func equalWeights(n int) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1 / float64(n)
+	}
+
+	return w
+}
@@ -0,0 +1,219 @@
+package optimize_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/optimize"
+	"github.com/wlachs/wstonks/pkg/risk"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// optimizer creates an Optimizer of two uncorrelated assets with 20% and 10% volatility and 10% and 5% expected return.
+func optimizer() (*optimize.Optimizer, *asset.Asset, *asset.Asset) {
+	a := &asset.Asset{Id: "A", UnitPrice: big.NewRat(100, 1), Tags: []string{"equity"}}
+	b := &asset.Asset{Id: "B", UnitPrice: big.NewRat(50, 1), Tags: []string{"bond"}}
+
+	return &optimize.Optimizer{
+		Assets:          []*asset.Asset{a, b},
+		ExpectedReturns: []float64{0.1, 0.05},
+		Covariance:      [][]float64{{0.04, 0}, {0, 0.01}},
+	}, a, b
+}
+
+// weight converts a weight of a distribution to float64.
+func weight(d map[*asset.Asset]*big.Rat, a *asset.Asset) float64 {
+	f, _ := d[a].Float64()
+	return f
+}
+
+// assertDistribution checks that the weights add up to exactly one and are within the weight limits of the constraints.
+func assertDistribution(t *testing.T, d map[*asset.Asset]*big.Rat, c optimize.Constraints) {
+	sum := big.NewRat(0, 1)
+	for a, w := range d {
+		sum.Add(sum, w)
+
+		assert.GreaterOrEqual(t, weight(d, a), 0.0, "weight of %s", a.Id)
+		if m, ok := c.MinWeight[a]; ok {
+			assert.GreaterOrEqual(t, weight(d, a), m, "minimum weight of %s", a.Id)
+		}
+		if m, ok := c.MaxWeight[a]; ok {
+			assert.LessOrEqual(t, weight(d, a), m, "maximum weight of %s", a.Id)
+		}
+	}
+
+	assert.Equal(t, big.NewRat(1, 1), sum)
+}
+
+// TestOptimizer_MinimumVariance checks the minimum variance distribution with and without a maximum weight.
+func TestOptimizer_MinimumVariance(t *testing.T) {
+	t.Parallel()
+
+	o, a, b := optimizer()
+	d, err := o.MinimumVariance()
+	assert.NoError(t, err)
+	assertDistribution(t, d, o.Constraints)
+	assert.InDelta(t, 0.2, weight(d, a), 1e-6)
+	assert.InDelta(t, 0.8, weight(d, b), 1e-6)
+
+	o.Constraints.MaxWeight = map[*asset.Asset]float64{b: 0.7}
+	d, err = o.MinimumVariance()
+	assert.NoError(t, err)
+	assertDistribution(t, d, o.Constraints)
+	assert.InDelta(t, 0.3, weight(d, a), 1e-6)
+}
+
+// TestOptimizer_Rounding checks that rounding the weights to millionths keeps them within their limits.
+func TestOptimizer_Rounding(t *testing.T) {
+	t.Parallel()
+
+	o, a, b := optimizer()
+	c := &asset.Asset{Id: "C", UnitPrice: big.NewRat(10, 1)}
+	o.Assets = append(o.Assets, c)
+	o.ExpectedReturns = append(o.ExpectedReturns, 0.05)
+	o.Covariance = [][]float64{{0.01, 0, 0}, {0, 0.01, 0}, {0, 0, 0.01}}
+
+	/* Every weight is 1/3, so one millionth is left over after rounding, which must not go to a weight at its maximum. */
+	o.Constraints.MaxWeight = map[*asset.Asset]float64{a: 1.0 / 3, b: 1.0 / 3}
+	d, err := o.MinimumVariance()
+	assert.NoError(t, err)
+	assertDistribution(t, d, o.Constraints)
+	assert.Equal(t, big.NewRat(333334, 1_000_000), d[c])
+
+	o.Constraints.MaxWeight = nil
+	o.Constraints.MinWeight = map[*asset.Asset]float64{b: 1.0 / 3, c: 1.0 / 3}
+	d, err = o.MinimumVariance()
+	assert.NoError(t, err)
+	assertDistribution(t, d, o.Constraints)
+}
+
+// TestOptimizer_MaxSharpe checks that the tangency portfolio is found.
+func TestOptimizer_MaxSharpe(t *testing.T) {
+	t.Parallel()
+
+	o, a, b := optimizer()
+	d, err := o.MaxSharpe()
+	assert.NoError(t, err)
+	assertDistribution(t, d, o.Constraints)
+	assert.InDelta(t, 1.0/3, weight(d, a), 1e-5)
+	assert.InDelta(t, 2.0/3, weight(d, b), 1e-5)
+
+	o.RiskFreeRate = 0.04
+	d, err = o.MaxSharpe()
+	assert.NoError(t, err)
+	// Σ⁻¹(μ - rf) = (1.5, 1), normalized to (0.6, 0.4)
+	assert.InDelta(t, 0.6, weight(d, a), 1e-5)
+}
+
+// TestOptimizer_TargetReturn checks the distribution reaching a target return, and that unreachable targets are rejected.
+func TestOptimizer_TargetReturn(t *testing.T) {
+	t.Parallel()
+
+	o, a, _ := optimizer()
+	d, err := o.TargetReturn(0.08)
+	assert.NoError(t, err)
+	assertDistribution(t, d, o.Constraints)
+	assert.InDelta(t, 0.6, weight(d, a), 1e-6)
+
+	_, err = o.TargetReturn(0.2)
+	assert.Error(t, err)
+}
+
+// TestOptimizer_Groups checks that the weight of a group is limited, with groups defined by tag and by assets.
+func TestOptimizer_Groups(t *testing.T) {
+	t.Parallel()
+
+	o, a, b := optimizer()
+	c := &asset.Asset{Id: "C", UnitPrice: big.NewRat(10, 1), Tags: []string{"bond"}}
+	o.Assets = append(o.Assets, c)
+	o.ExpectedReturns = append(o.ExpectedReturns, 0.05)
+	o.Covariance = [][]float64{{0.04, 0, 0}, {0, 0.01, 0}, {0, 0, 0.01}}
+
+	o.Constraints.Groups = []optimize.Group{{Name: "bonds", Tag: "bond", Max: 0.5}}
+	d, err := o.MinimumVariance()
+	assert.NoError(t, err)
+	assertDistribution(t, d, o.Constraints)
+	assert.InDelta(t, 0.5, weight(d, a), 1e-6)
+	assert.InDelta(t, 0.25, weight(d, b), 1e-6)
+	assert.InDelta(t, 0.25, weight(d, c), 1e-6)
+
+	o.Constraints.Groups = []optimize.Group{{Name: "B and C", Assets: []*asset.Asset{b, c}, Min: 0.95, Max: 1}}
+	d, err = o.MaxSharpe()
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.05, weight(d, a), 1e-5)
+}
+
+// TestOptimizer_Invalid checks that contradicting constraints and mismatching inputs are rejected.
+func TestOptimizer_Invalid(t *testing.T) {
+	t.Parallel()
+
+	o, a, b := optimizer()
+	o.Constraints.MinWeight = map[*asset.Asset]float64{a: 0.6, b: 0.6}
+	_, err := o.MinimumVariance()
+	assert.Error(t, err)
+
+	o, a, b = optimizer()
+	o.Constraints.Groups = []optimize.Group{{Name: "all", Assets: []*asset.Asset{a, b}, Min: 0, Max: 0.5}}
+	_, err = o.MinimumVariance()
+	assert.Error(t, err)
+
+	o, _, _ = optimizer()
+	o.ExpectedReturns = []float64{0.1}
+	_, err = o.MaxSharpe()
+	assert.Error(t, err)
+}
+
+// TestOptimizer_Frontier checks that the returns and volatilities along the efficient frontier increase.
+func TestOptimizer_Frontier(t *testing.T) {
+	t.Parallel()
+
+	o, _, _ := optimizer()
+	frontier, err := o.Frontier(5)
+	assert.NoError(t, err)
+	assert.Len(t, frontier, 5)
+	assert.InDelta(t, 0.06, frontier[0].Return, 1e-9)
+	assert.InDelta(t, 0.1, frontier[4].Return, 1e-9)
+	assert.InDelta(t, 0.2, frontier[4].Volatility, 1e-9)
+
+	for i := 1; i < len(frontier); i++ {
+		assert.Greater(t, frontier[i].Volatility, frontier[i-1].Volatility)
+	}
+}
+
+// TestNewOptimizer checks that an Optimizer based on historical prices produces a distribution accepted by the distribution adjustment.
+func TestNewOptimizer(t *testing.T) {
+	t.Parallel()
+
+	h := &asset.PriceHistory{}
+	prices := map[string][]int64{"A": {100, 110, 99, 121, 110, 115}, "B": {50, 49, 51, 50, 52, 51}}
+	for id, series := range prices {
+		for i, p := range series {
+			ts := time.Date(2024, 1, i+1, 0, 0, 0, 0, time.UTC)
+			assert.NoError(t, h.AddPrice(id, asset.PricePoint{Timestamp: ts, UnitPrice: big.NewRat(p, 1)}))
+		}
+	}
+
+	a := &asset.Asset{Id: "A", UnitPrice: big.NewRat(115, 1)}
+	b := &asset.Asset{Id: "B", UnitPrice: big.NewRat(51, 1)}
+	o, err := optimize.NewOptimizer(h, []*asset.Asset{a, b}, time.Time{}, time.Time{}, risk.Config{})
+	assert.NoError(t, err)
+
+	d, err := o.MinimumVariance()
+	assert.NoError(t, err)
+	assert.Greater(t, weight(d, b), weight(d, a))
+
+	txCtx := transaction.Context{}
+	assert.NoError(t, txCtx.AddTransaction(transaction.Tx{
+		Position: transaction.Position{Asset: &transaction.TxAsset{Id: "A"}, Quantity: big.NewRat(1, 1), UnitPrice: big.NewRat(100, 1)},
+		Type:     transaction.BUY,
+	}))
+	assetCtx := asset.Context{}
+	assert.NoError(t, assetCtx.AddAssets([]*asset.Asset{a, b}))
+	ctx := calculation.Context{AssetContext: &assetCtx, TransactionContext: &txCtx}
+
+	_, err = ctx.GetDistributionAdjustmentMapWithBudget(d, big.NewRat(100, 1))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,123 @@
+package optimize
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"math"
+	"math/big"
+)
+
+// RiskParity finds the distribution in which every asset contributes the same share to the volatility of the portfolio, or the share given
+// by the risk budget. The budget maps the assets to their relative contributions, which are normalized to add up to one; if it is nil,
+// every asset gets the same budget. Only the covariances are used, the expected returns and the constraints of the Optimizer are ignored.
+func (o *Optimizer) RiskParity(budget map[*asset.Asset]float64) (map[*asset.Asset]*big.Rat, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	b, err := o.riskBudget(budget)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, a := range o.Assets {
+		if o.Covariance[i][i] <= 0 {
+			return nil, fmt.Errorf("asset %s has no volatility", a.Id)
+		}
+	}
+
+	/* Cyclical coordinate descent on min ½ yᵀΣy - Σ b[i] ln y[i], whose solution normalized to one has the desired risk contributions. */
+	n := len(o.Assets)
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 1 / math.Sqrt(o.Covariance[i][i])
+	}
+
+	for range 10000 {
+		change := 0.0
+		for i := range y {
+			others := 0.0
+			for j := range y {
+				if j != i {
+					others += o.Covariance[i][j] * y[j]
+				}
+			}
+
+			next := (-others + math.Sqrt(others*others+4*o.Covariance[i][i]*b[i])) / (2 * o.Covariance[i][i])
+			change = math.Max(change, math.Abs(next-y[i])/y[i])
+			y[i] = next
+		}
+
+		if change < 1e-13 {
+			break
+		}
+	}
+
+	sum := 0.0
+	for _, v := range y {
+		sum += v
+	}
+
+	w := make([]float64, n)
+	for i := range y {
+		w[i] = y[i] / sum
+	}
+
+	return toDistribution(o.Assets, w, Constraints{}), nil
+}
+
+// RiskContributions calculates the share of every asset in the volatility of a portfolio with the given distribution. The shares add up to
+// one.
+func (o *Optimizer) RiskContributions(distribution map[*asset.Asset]*big.Rat) (map[*asset.Asset]float64, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	w := make([]float64, len(o.Assets))
+	for i, a := range o.Assets {
+		if d, ok := distribution[a]; ok {
+			w[i], _ = d.Float64()
+		}
+	}
+
+	variance := o.variance(w)
+	if variance == 0 {
+		return nil, fmt.Errorf("the portfolio has no volatility")
+	}
+
+	m := map[*asset.Asset]float64{}
+	for i, a := range o.Assets {
+		exposure := 0.0
+		for j := range w {
+			exposure += o.Covariance[i][j] * w[j]
+		}
+
+		m[a] = w[i] * exposure / variance
+	}
+
+	return m, nil
+}
+
+// riskBudget converts the risk budget of the assets to normalized shares in the order of the assets.
+func (o *Optimizer) riskBudget(budget map[*asset.Asset]float64) ([]float64, error) {
+	b := make([]float64, len(o.Assets))
+	sum := 0.0
+	for i, a := range o.Assets {
+		b[i] = 1
+		if budget != nil {
+			b[i] = budget[a]
+		}
+
+		if b[i] <= 0 {
+			return nil, fmt.Errorf("risk budget of %s should be positive", a.Id)
+		}
+
+		sum += b[i]
+	}
+
+	for i := range b {
+		b[i] /= sum
+	}
+
+	return b, nil
+}
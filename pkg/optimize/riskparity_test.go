@@ -0,0 +1,60 @@
+package optimize_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/optimize"
+	"math/big"
+	"testing"
+)
+
+// TestOptimizer_RiskParity checks that uncorrelated assets are weighted inversely to their volatility.
+func TestOptimizer_RiskParity(t *testing.T) {
+	t.Parallel()
+
+	o, a, b := optimizer()
+	d, err := o.RiskParity(nil)
+	assert.NoError(t, err)
+	assertDistribution(t, d, optimize.Constraints{})
+	assert.InDelta(t, 1.0/3, weight(d, a), 1e-6)
+	assert.InDelta(t, 2.0/3, weight(d, b), 1e-6)
+
+	contributions, err := o.RiskContributions(d)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.5, contributions[a], 1e-5)
+	assert.InDelta(t, 0.5, contributions[b], 1e-5)
+}
+
+// TestOptimizer_RiskParity_Budget checks the risk contributions of correlated assets with an uneven risk budget.
+func TestOptimizer_RiskParity_Budget(t *testing.T) {
+	t.Parallel()
+
+	o, a, b := optimizer()
+	c := &asset.Asset{Id: "C", UnitPrice: big.NewRat(10, 1)}
+	o.Assets = append(o.Assets, c)
+	o.ExpectedReturns = append(o.ExpectedReturns, 0.07)
+	o.Covariance = [][]float64{{0.04, 0.006, 0.012}, {0.006, 0.01, -0.002}, {0.012, -0.002, 0.0225}}
+
+	d, err := o.RiskParity(map[*asset.Asset]float64{a: 2, b: 1, c: 1})
+	assert.NoError(t, err)
+	assertDistribution(t, d, optimize.Constraints{})
+
+	contributions, err := o.RiskContributions(d)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.5, contributions[a], 1e-5)
+	assert.InDelta(t, 0.25, contributions[b], 1e-5)
+	assert.InDelta(t, 0.25, contributions[c], 1e-5)
+}
+
+// TestOptimizer_RiskParity_Invalid checks that invalid budgets and assets without volatility are rejected.
+func TestOptimizer_RiskParity_Invalid(t *testing.T) {
+	t.Parallel()
+
+	o, a, _ := optimizer()
+	_, err := o.RiskParity(map[*asset.Asset]float64{a: 1})
+	assert.Error(t, err)
+
+	o.Covariance[1][1] = 0
+	_, err = o.RiskParity(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package plan
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"time"
+)
+
+// BacktestResult summarizes the outcome of a savings plan executed against historical prices.
+type BacktestResult struct {
+	Plan       SavingsPlan
+	Executions int
+	// Invested is the total amount spent, including fees.
+	Invested *big.Rat
+	Fees     *big.Rat
+	Units    *big.Rat
+	// AveragePrice is the average unit price paid, excluding fees. It is nil if no units were bought.
+	AveragePrice *big.Rat
+	// Worth is the value of the units at the last price at or before the end of the backtest.
+	Worth *big.Rat
+	// Return is the worth minus the invested amount.
+	Return *big.Rat
+}
+
+// Backtest executes every savings plan against the historical prices up to until, so alternative plans can be compared with each other.
+func Backtest(plans []SavingsPlan, history *asset.PriceHistory, until time.Time) ([]BacktestResult, error) {
+	results := make([]BacktestResult, 0, len(plans))
+	for _, p := range plans {
+		r, err := p.Backtest(history, until)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// Backtest executes the savings plan against the historical prices up to until.
+func (p SavingsPlan) Backtest(history *asset.PriceHistory, until time.Time) (BacktestResult, error) {
+	txs, err := p.Generate(history, until)
+	if err != nil {
+		return BacktestResult{}, err
+	}
+
+	r := BacktestResult{
+		Plan:     p,
+		Invested: big.NewRat(0, 1),
+		Fees:     big.NewRat(0, 1),
+		Units:    big.NewRat(0, 1),
+		Worth:    big.NewRat(0, 1),
+	}
+
+	cost := big.NewRat(0, 1)
+	for _, tx := range txs {
+		switch tx.Type {
+		case transaction.BUY:
+			r.Executions++
+			r.Units.Add(r.Units, tx.Quantity)
+			cost.Add(cost, big.NewRat(0, 1).Mul(tx.Quantity, tx.UnitPrice))
+		case transaction.FEE:
+			r.Fees.Add(r.Fees, tx.UnitPrice)
+		}
+	}
+	r.Invested.Add(cost, r.Fees)
+
+	if r.Units.Sign() != 0 {
+		r.AveragePrice = big.NewRat(0, 1).Quo(cost, r.Units)
+
+		price, ok := history.PriceAt(p.AssetId, until)
+		if !ok {
+			return BacktestResult{}, fmt.Errorf("missing price of %s at %s", p.AssetId, until)
+		}
+		r.Worth.Mul(r.Units, price.UnitPrice)
+	}
+
+	r.Return = big.NewRat(0, 1).Sub(r.Worth, r.Invested)
+	return r, nil
+}
@@ -0,0 +1,44 @@
+package plan
+
+import (
+	"math/big"
+	"time"
+)
+
+// Frequency holds the different execution frequencies of a savings plan as a pseudo-enum.
+type Frequency = int
+
+const (
+	WEEKLY Frequency = iota
+	MONTHLY
+	QUARTERLY
+	YEARLY
+)
+
+// DayRule holds the different rules for executing a savings plan on a day without price as a pseudo-enum.
+type DayRule = int
+
+const (
+	// NEXT_AVAILABLE executes the plan at the first price on or after the execution day, like a broker does on non-trading days.
+	NEXT_AVAILABLE DayRule = iota
+	// PREVIOUS_AVAILABLE executes the plan at the last price on or before the execution day.
+	PREVIOUS_AVAILABLE
+)
+
+// SavingsPlan invests a fixed amount into an asset in regular intervals, also known as dollar-cost averaging.
+type SavingsPlan struct {
+	AssetId string
+	// Amount is the sum spent on every execution, including the fee.
+	Amount *big.Rat
+	// Fee is charged on every execution and reduces the amount invested. Nil means no fee.
+	Fee       *big.Rat
+	Frequency Frequency
+	// Day is the day of the month the plan is executed on, clamped to the length of the month. Zero means the day of Start. Weekly plans
+	// are executed on the weekday of Start.
+	Day  int
+	Rule DayRule
+	// Start is the first possible execution day. The time of day of Start is used for every execution.
+	Start time.Time
+	// End is the last possible execution day. The zero value means the plan runs indefinitely.
+	End time.Time
+}
@@ -0,0 +1,230 @@
+package plan_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/plan"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// date creates a UTC timestamp at midnight of the given day.
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// history creates a price history of asset A without prices on the last weekend of March 2024.
+func history(t *testing.T) *asset.PriceHistory {
+	h := &asset.PriceHistory{}
+	prices := []struct {
+		ts    time.Time
+		price int64
+	}{
+		{date(2024, 1, 31), 100},
+		{date(2024, 2, 29), 125},
+		{date(2024, 3, 29), 80},
+		{date(2024, 4, 1), 90},
+		{date(2024, 4, 30), 100},
+	}
+
+	for _, p := range prices {
+		assert.NoError(t, h.AddPrice("A", asset.PricePoint{Timestamp: p.ts, UnitPrice: big.NewRat(p.price, 1)}))
+	}
+
+	return h
+}
+
+// monthlyPlan creates a plan investing 100 into asset A at the end of every month for a fee of 1.
+func monthlyPlan() plan.SavingsPlan {
+	return plan.SavingsPlan{
+		AssetId:   "A",
+		Amount:    big.NewRat(101, 1),
+		Fee:       big.NewRat(1, 1),
+		Frequency: plan.MONTHLY,
+		Day:       31,
+		Start:     date(2024, 1, 1),
+	}
+}
+
+// TestSavingsPlan_Schedule checks that the execution day is clamped to the length of the month.
+func TestSavingsPlan_Schedule(t *testing.T) {
+	t.Parallel()
+
+	days := monthlyPlan().Schedule(date(2024, 4, 30))
+	assert.Equal(t, []time.Time{date(2024, 1, 31), date(2024, 2, 29), date(2024, 3, 31), date(2024, 4, 30)}, days)
+}
+
+// TestSavingsPlan_Schedule_Frequencies checks the schedules of weekly, quarterly and yearly plans with an end date.
+func TestSavingsPlan_Schedule_Frequencies(t *testing.T) {
+	t.Parallel()
+
+	p := plan.SavingsPlan{Frequency: plan.WEEKLY, Start: date(2024, 1, 3), End: date(2024, 1, 24)}
+	assert.Equal(t, []time.Time{date(2024, 1, 3), date(2024, 1, 10), date(2024, 1, 17), date(2024, 1, 24)}, p.Schedule(date(2025, 1, 1)))
+
+	p = plan.SavingsPlan{Frequency: plan.QUARTERLY, Day: 15, Start: date(2024, 1, 20)}
+	assert.Equal(t, []time.Time{date(2024, 4, 15), date(2024, 7, 15)}, p.Schedule(date(2024, 9, 30)))
+
+	p = plan.SavingsPlan{Frequency: plan.YEARLY, Start: date(2024, 2, 29)}
+	assert.Equal(t, []time.Time{date(2024, 2, 29), date(2025, 2, 28)}, p.Schedule(date(2025, 12, 31)))
+}
+
+// TestSavingsPlan_Generate checks the generated transactions of a plan executed on the next available day.
+func TestSavingsPlan_Generate(t *testing.T) {
+	t.Parallel()
+
+	txs, err := monthlyPlan().Generate(history(t), date(2024, 4, 30))
+	assert.NoError(t, err)
+	assert.Len(t, txs, 8)
+
+	assert.Equal(t, transaction.BUY, txs[0].Type)
+	assert.Equal(t, "A", txs[0].Asset.Id)
+	assert.Equal(t, big.NewRat(1, 1), txs[0].Quantity)
+	assert.Equal(t, transaction.FEE, txs[1].Type)
+	assert.Equal(t, big.NewRat(1, 1), txs[1].UnitPrice)
+
+	assert.Equal(t, big.NewRat(4, 5), txs[2].Quantity)
+	assert.Equal(t, date(2024, 4, 1), txs[4].Timestamp)
+	assert.Equal(t, big.NewRat(10, 9), txs[4].Quantity)
+	assert.Equal(t, big.NewRat(90, 1), txs[4].UnitPrice)
+}
+
+// TestSavingsPlan_Generate_Previous checks that a plan executed on the previous available day uses the last price before a weekend.
+func TestSavingsPlan_Generate_Previous(t *testing.T) {
+	t.Parallel()
+
+	p := monthlyPlan()
+	p.Rule = plan.PREVIOUS_AVAILABLE
+	p.Fee = nil
+	p.Amount = big.NewRat(100, 1)
+
+	txs, err := p.Generate(history(t), date(2024, 4, 30))
+	assert.NoError(t, err)
+	assert.Len(t, txs, 4)
+	assert.Equal(t, date(2024, 3, 29), txs[2].Timestamp)
+	assert.Equal(t, big.NewRat(5, 4), txs[2].Quantity)
+}
+
+// TestSavingsPlan_Generate_Gap checks that weekly executions within a gap of the price history are executed only once.
+func TestSavingsPlan_Generate_Gap(t *testing.T) {
+	t.Parallel()
+
+	p := plan.SavingsPlan{AssetId: "A", Amount: big.NewRat(100, 1), Frequency: plan.WEEKLY, Start: date(2024, 3, 1)}
+	txs, err := p.Generate(history(t), date(2024, 4, 30))
+	assert.NoError(t, err)
+	assert.Len(t, txs, 2)
+	assert.Equal(t, date(2024, 3, 29), txs[0].Timestamp)
+	assert.Equal(t, big.NewRat(5, 4), txs[0].Quantity)
+	assert.Equal(t, date(2024, 4, 30), txs[1].Timestamp)
+
+	p.Rule = plan.PREVIOUS_AVAILABLE
+	txs, err = p.Generate(history(t), date(2024, 4, 30))
+	assert.NoError(t, err)
+	assert.Len(t, txs, 3)
+	assert.Equal(t, []time.Time{date(2024, 2, 29), date(2024, 3, 29), date(2024, 4, 1)},
+		[]time.Time{txs[0].Timestamp, txs[1].Timestamp, txs[2].Timestamp})
+}
+
+// TestSavingsPlan_Generate_Invalid checks that invalid plans are rejected.
+func TestSavingsPlan_Generate_Invalid(t *testing.T) {
+	t.Parallel()
+
+	p := monthlyPlan()
+	p.Fee = big.NewRat(101, 1)
+	_, err := p.Generate(history(t), date(2024, 4, 30))
+	assert.Error(t, err)
+
+	p = monthlyPlan()
+	p.Start = time.Time{}
+	_, err = p.Generate(history(t), date(2024, 4, 30))
+	assert.Error(t, err)
+
+	p = monthlyPlan()
+	p.Frequency = 42
+	_, err = p.Generate(history(t), date(2024, 4, 30))
+	assert.Error(t, err)
+}
+
+// TestSavingsPlan_Reconcile checks that executions booked a day late are matched, and that missing and unexpected transactions are reported.
+func TestSavingsPlan_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	buy := func(ts time.Time, quantity *big.Rat, price int64) transaction.Tx {
+		return transaction.Tx{
+			Position: transaction.Position{Asset: &transaction.TxAsset{Id: "A"}, Timestamp: ts, Quantity: quantity, UnitPrice: big.NewRat(price, 1)},
+			Type:     transaction.BUY,
+		}
+	}
+	err := ctx.AddTransactions([]transaction.Tx{
+		buy(date(2024, 1, 31), big.NewRat(1, 1), 100),
+		buy(date(2024, 3, 1), big.NewRat(79, 100), 125),
+		buy(date(2024, 3, 15), big.NewRat(2, 1), 85),
+		buy(date(2024, 4, 1), big.NewRat(111, 100), 90),
+		buy(date(2024, 4, 30), big.NewRat(1, 1), 100),
+	})
+	assert.NoError(t, err)
+
+	p := monthlyPlan()
+	p.Fee = nil
+	p.Amount = big.NewRat(100, 1)
+
+	r, err := p.Reconcile(history(t), &ctx, date(2024, 4, 30), 48*time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, r.Matched, 4)
+	assert.Equal(t, date(2024, 3, 1), r.Matched[1].Actual.Timestamp)
+	assert.Equal(t, big.NewRat(-1, 100), r.Matched[1].QuantityDiff)
+	assert.Empty(t, r.Missing)
+	assert.Len(t, r.Unexpected, 1)
+	assert.Equal(t, date(2024, 3, 15), r.Unexpected[0].Timestamp)
+
+	r, err = p.Reconcile(history(t), &ctx, date(2024, 4, 30), time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, r.Matched, 3)
+	assert.Len(t, r.Missing, 1)
+	assert.Equal(t, date(2024, 2, 29), r.Missing[0].Timestamp)
+	assert.Len(t, r.Unexpected, 2)
+}
+
+// TestBacktest compares a plan executed on the next and on the previous available day.
+func TestBacktest(t *testing.T) {
+	t.Parallel()
+
+	next := monthlyPlan()
+	previous := monthlyPlan()
+	previous.Rule = plan.PREVIOUS_AVAILABLE
+
+	results, err := plan.Backtest([]plan.SavingsPlan{next, previous}, history(t), date(2024, 4, 30))
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	// units: 1 + 4/5 + 10/9 + 1 = 176/45
+	assert.Equal(t, 4, results[0].Executions)
+	assert.Equal(t, big.NewRat(404, 1), results[0].Invested)
+	assert.Equal(t, big.NewRat(4, 1), results[0].Fees)
+	assert.Equal(t, big.NewRat(176, 45), results[0].Units)
+	assert.Equal(t, big.NewRat(4500, 44), results[0].AveragePrice)
+	assert.Equal(t, big.NewRat(17600, 45), results[0].Worth)
+	assert.Equal(t, big.NewRat(0, 1).Sub(big.NewRat(17600, 45), big.NewRat(404, 1)), results[0].Return)
+
+	// units: 1 + 4/5 + 5/4 + 1 = 81/20
+	assert.Equal(t, big.NewRat(81, 20), results[1].Units)
+	assert.Equal(t, big.NewRat(405, 1), results[1].Worth)
+	assert.Equal(t, big.NewRat(1, 1), results[1].Return)
+}
+
+// TestBacktest_NoPrices checks that a plan without prices in the history doesn't invest anything.
+func TestBacktest_NoPrices(t *testing.T) {
+	t.Parallel()
+
+	p := monthlyPlan()
+	p.AssetId = "B"
+
+	r, err := p.Backtest(history(t), date(2024, 4, 30))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, r.Executions)
+	assert.Nil(t, r.AveragePrice)
+	assert.Equal(t, 0, r.Invested.Sign())
+	assert.Equal(t, 0, r.Return.Sign())
+}
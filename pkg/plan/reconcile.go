@@ -0,0 +1,109 @@
+package plan
+
+import (
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"time"
+)
+
+// Match pairs an expected transaction of a savings plan with the actual transaction recorded for it.
+type Match struct {
+	Expected transaction.Tx
+	Actual   transaction.Tx
+	// QuantityDiff is the actual minus the expected quantity, e.g. caused by rounding of the broker.
+	QuantityDiff *big.Rat
+}
+
+// Reconciliation is the result of comparing the expected transactions of savings plans with the actual transaction history.
+type Reconciliation struct {
+	Matched []Match
+	// Missing lists the expected transactions without a matching actual transaction.
+	Missing []transaction.Tx
+	// Unexpected lists the actual transactions of the plan assets which do not belong to any execution.
+	Unexpected []transaction.Tx
+}
+
+// Reconcile compares the expected transactions with the actual ones. An actual transaction matches an expected one if both have the same
+// asset and type, and their timestamps differ by at most the tolerance; the closest one is picked if there are several. Only actual
+// transactions of the assets and types of the expected transactions within the period of the expected transactions, extended by the
+// tolerance, are taken into account.
+func Reconcile(expected []transaction.Tx, actual []*transaction.Tx, tolerance time.Duration) Reconciliation {
+	r := Reconciliation{}
+	if len(expected) == 0 {
+		return r
+	}
+
+	first, last := expected[0].Timestamp, expected[0].Timestamp
+	kinds := map[string]map[transaction.TxType]bool{}
+	for _, e := range expected {
+		if e.Timestamp.Before(first) {
+			first = e.Timestamp
+		}
+		if e.Timestamp.After(last) {
+			last = e.Timestamp
+		}
+		if kinds[e.Asset.Id] == nil {
+			kinds[e.Asset.Id] = map[transaction.TxType]bool{}
+		}
+		kinds[e.Asset.Id][e.Type] = true
+	}
+
+	var candidates []*transaction.Tx
+	for _, a := range actual {
+		if a.Asset == nil || !kinds[a.Asset.Id][a.Type] {
+			continue
+		}
+		if a.Timestamp.Before(first.Add(-tolerance)) || a.Timestamp.After(last.Add(tolerance)) {
+			continue
+		}
+		candidates = append(candidates, a)
+	}
+
+	used := make([]bool, len(candidates))
+	for _, e := range expected {
+		best := -1
+		var bestDiff time.Duration
+		for i, c := range candidates {
+			if used[i] || c.Asset.Id != e.Asset.Id || c.Type != e.Type {
+				continue
+			}
+
+			diff := c.Timestamp.Sub(e.Timestamp).Abs()
+			if diff <= tolerance && (best == -1 || diff < bestDiff) {
+				best, bestDiff = i, diff
+			}
+		}
+
+		if best == -1 {
+			r.Missing = append(r.Missing, e)
+			continue
+		}
+
+		used[best] = true
+		r.Matched = append(r.Matched, Match{
+			Expected:     e,
+			Actual:       *candidates[best],
+			QuantityDiff: big.NewRat(0, 1).Sub(candidates[best].Quantity, e.Quantity),
+		})
+	}
+
+	for i, c := range candidates {
+		if !used[i] {
+			r.Unexpected = append(r.Unexpected, *c)
+		}
+	}
+
+	return r
+}
+
+// Reconcile generates the expected transactions of the savings plan up to until and compares them with the transaction history of the
+// Context.
+func (p SavingsPlan) Reconcile(history *asset.PriceHistory, ctx *transaction.Context, until time.Time, tolerance time.Duration) (Reconciliation, error) {
+	expected, err := p.Generate(history, until)
+	if err != nil {
+		return Reconciliation{}, err
+	}
+
+	return Reconcile(expected, ctx.GetTransactions(), tolerance), nil
+}
@@ -0,0 +1,145 @@
+package plan
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"time"
+)
+
+// Validate verifies that the savings plan can be executed.
+func (p SavingsPlan) Validate() error {
+	if p.AssetId == "" {
+		return fmt.Errorf("missing asset ID")
+	}
+
+	if p.Amount == nil || p.Amount.Sign() <= 0 {
+		return fmt.Errorf("amount of savings plan for %s should be positive", p.AssetId)
+	}
+
+	if p.Fee != nil && (p.Fee.Sign() < 0 || p.Fee.Cmp(p.Amount) >= 0) {
+		return fmt.Errorf("fee of savings plan for %s should be between zero and the amount", p.AssetId)
+	}
+
+	if p.Frequency < WEEKLY || p.Frequency > YEARLY {
+		return fmt.Errorf("unsupported frequency %d", p.Frequency)
+	}
+
+	if p.Rule != NEXT_AVAILABLE && p.Rule != PREVIOUS_AVAILABLE {
+		return fmt.Errorf("unsupported day rule %d", p.Rule)
+	}
+
+	if p.Day < 0 || p.Day > 31 {
+		return fmt.Errorf("invalid execution day %d", p.Day)
+	}
+
+	if p.Start.IsZero() {
+		return fmt.Errorf("missing start of savings plan for %s", p.AssetId)
+	}
+
+	return nil
+}
+
+// Schedule returns the nominal execution days of the savings plan from Start up to and including until or End, whichever is earlier.
+func (p SavingsPlan) Schedule(until time.Time) []time.Time {
+	if !p.End.IsZero() && p.End.Before(until) {
+		until = p.End
+	}
+
+	var days []time.Time
+	for k := 0; ; k++ {
+		day := p.executionDay(k)
+		if day.After(until) {
+			return days
+		}
+
+		if !day.Before(p.Start) {
+			days = append(days, day)
+		}
+	}
+}
+
+// executionDay calculates the k-th nominal execution day counted from the period of Start.
+func (p SavingsPlan) executionDay(k int) time.Time {
+	s := p.Start
+	if p.Frequency == WEEKLY {
+		return s.AddDate(0, 0, 7*k)
+	}
+
+	months := map[Frequency]int{MONTHLY: 1, QUARTERLY: 3, YEARLY: 12}[p.Frequency]
+	first := time.Date(s.Year(), s.Month()+time.Month(k*months), 1, s.Hour(), s.Minute(), s.Second(), s.Nanosecond(), s.Location())
+
+	day := p.Day
+	if day == 0 {
+		day = s.Day()
+	}
+
+	/* Clamp the day to the length of the month, e.g. the 31st is executed on the 30th of April. */
+	length := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, length)-1)
+}
+
+// Generate creates the transactions the savings plan is expected to have produced up to until, based on the historical prices of the
+// asset. Every execution results in a BUY transaction of the fractional quantity the invested amount buys, followed by a FEE transaction
+// if a fee is charged. Executions without a price according to the day rule are skipped, and so are executions falling on the same price
+// as the previous one, e.g. several weekly executions within a gap of the price history, since a plan is executed at most once per day.
+func (p SavingsPlan) Generate(history *asset.PriceHistory, until time.Time) ([]transaction.Tx, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	fee := big.NewRat(0, 1)
+	if p.Fee != nil {
+		fee.Set(p.Fee)
+	}
+	invested := big.NewRat(0, 1).Sub(p.Amount, fee)
+
+	var txs []transaction.Tx
+	var last time.Time
+	for _, day := range p.Schedule(until) {
+		price, ok := p.executionPrice(history, day)
+		if !ok || price.Timestamp.After(until) || price.Timestamp.Equal(last) {
+			continue
+		}
+		last = price.Timestamp
+
+		if price.UnitPrice.Sign() == 0 {
+			return nil, fmt.Errorf("zero price of %s at %s", p.AssetId, price.Timestamp)
+		}
+
+		quantity := big.NewRat(0, 1).Quo(invested, price.UnitPrice)
+		txs = append(txs, transaction.Tx{
+			Position: transaction.Position{
+				Asset:     &transaction.TxAsset{Id: p.AssetId},
+				Timestamp: price.Timestamp,
+				Quantity:  quantity,
+				UnitPrice: big.NewRat(0, 1).Set(price.UnitPrice),
+			},
+			Type: transaction.BUY,
+		})
+
+		if fee.Sign() != 0 {
+			txs = append(txs, transaction.Tx{
+				Position: transaction.Position{
+					Asset:     &transaction.TxAsset{Id: p.AssetId},
+					Timestamp: price.Timestamp,
+					Quantity:  big.NewRat(1, 1),
+					UnitPrice: big.NewRat(0, 1).Set(fee),
+				},
+				Type: transaction.FEE,
+			})
+		}
+	}
+
+	return txs, nil
+}
+
+// executionPrice looks up the price the plan is executed at on the given day according to the day rule.
+func (p SavingsPlan) executionPrice(history *asset.PriceHistory, day time.Time) (asset.PricePoint, bool) {
+	if p.Rule == PREVIOUS_AVAILABLE {
+		return history.PriceAt(p.AssetId, day)
+	}
+
+	return history.PriceOnOrAfter(p.AssetId, day)
+}
@@ -0,0 +1,91 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+)
+
+// Format holds the supported serialization formats of a Document as a pseudo-enum.
+type Format = int
+
+const (
+	JSON Format = iota
+	YAML
+)
+
+// Decode reads a Document in the given format from the reader and validates its version.
+func Decode(r io.Reader, format Format) (Document, error) {
+	var d Document
+	var err error
+
+	switch format {
+	case JSON:
+		err = json.NewDecoder(r).Decode(&d)
+	case YAML:
+		err = yaml.NewDecoder(r).Decode(&d)
+	default:
+		return Document{}, fmt.Errorf("unsupported portfolio format %d", format)
+	}
+
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to decode portfolio document: %w", err)
+	}
+
+	return d, d.Validate()
+}
+
+// Encode writes the Document in the given format to the writer.
+func Encode(w io.Writer, format Format, d Document) error {
+	switch format {
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(d)
+	case YAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(d); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unsupported portfolio format %d", format)
+	}
+}
+
+// ReadFile tries to open and decode the Document file in the given format on the given path.
+func ReadFile(path string, format Format) (Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to open file \"%s\"", path)
+	}
+
+	defer func(f *os.File) {
+		cerr := f.Close()
+		if cerr != nil {
+			err = cerr
+		}
+	}(f)
+
+	return Decode(f, format)
+}
+
+// WriteFile creates or truncates the file on the given path and encodes the Document into it in the given format.
+func WriteFile(path string, format Format, d Document) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file \"%s\"", path)
+	}
+
+	defer func(f *os.File) {
+		cerr := f.Close()
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+	}(f)
+
+	return Encode(f, format, d)
+}
@@ -0,0 +1,147 @@
+package portfolio
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"time"
+)
+
+// Version is the version of the portfolio document format written by this package.
+const Version = 1
+
+// Document is the serializable representation of a transaction.Context and an asset.Context. It is versioned, so that the format can
+// evolve without breaking existing files.
+type Document struct {
+	Version      int           `json:"version" yaml:"version"`
+	Transactions []Transaction `json:"transactions" yaml:"transactions"`
+	Assets       []Asset       `json:"assets" yaml:"assets"`
+}
+
+// Transaction is the serializable representation of a transaction.Tx.
+type Transaction struct {
+	Id        string    `json:"id,omitempty" yaml:"id,omitempty"`
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+	Asset     string    `json:"asset" yaml:"asset"`
+	Type      string    `json:"type" yaml:"type"`
+	Quantity  Rat       `json:"quantity" yaml:"quantity"`
+	UnitPrice Rat       `json:"unitPrice" yaml:"unitPrice"`
+	Currency  string    `json:"currency,omitempty" yaml:"currency,omitempty"`
+	// Gross, WithheldTax and Country describe the taxes withheld from a dividend and are omitted if unset.
+	Gross       *Rat   `json:"gross,omitempty" yaml:"gross,omitempty"`
+	WithheldTax *Rat   `json:"withheldTax,omitempty" yaml:"withheldTax,omitempty"`
+	Country     string `json:"country,omitempty" yaml:"country,omitempty"`
+}
+
+// Asset is the serializable representation of an asset.Asset including its metadata and live unit price.
+type Asset struct {
+	Id        string   `json:"id" yaml:"id"`
+	Name      string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Currency  string   `json:"currency,omitempty" yaml:"currency,omitempty"`
+	Tags      []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	UnitPrice Rat      `json:"unitPrice" yaml:"unitPrice"`
+}
+
+// NewDocument creates a Document from the given contexts. Either of the contexts may be nil.
+func NewDocument(txCtx *transaction.Context, assetCtx *asset.Context) (Document, error) {
+	d := Document{Version: Version, Transactions: []Transaction{}, Assets: []Asset{}}
+
+	if txCtx != nil {
+		for _, t := range txCtx.GetTransactions() {
+			txType, err := transaction.FormatTxType(t.Type)
+			if err != nil {
+				return Document{}, err
+			}
+
+			d.Transactions = append(d.Transactions, Transaction{
+				Id:          t.Id,
+				Timestamp:   t.Timestamp,
+				Asset:       t.Asset.Id,
+				Type:        txType,
+				Quantity:    NewRat(t.Quantity),
+				UnitPrice:   NewRat(t.UnitPrice),
+				Currency:    t.Currency,
+				Gross:       newOptionalRat(t.Gross),
+				WithheldTax: newOptionalRat(t.WithheldTax),
+				Country:     t.Country,
+			})
+		}
+	}
+
+	if assetCtx != nil {
+		for _, a := range assetCtx.GetAssets() {
+			d.Assets = append(d.Assets, Asset{
+				Id:        a.Id,
+				Name:      a.Name,
+				Currency:  a.Currency,
+				Tags:      a.Tags,
+				UnitPrice: NewRat(a.UnitPrice),
+			})
+		}
+	}
+
+	return d, nil
+}
+
+// Validate checks that the Document has a supported version.
+func (d Document) Validate() error {
+	if d.Version < 1 || d.Version > Version {
+		return fmt.Errorf("unsupported portfolio document version %d", d.Version)
+	}
+
+	return nil
+}
+
+// GetTransactions converts the transactions of the Document to a transaction.Tx slice.
+func (d Document) GetTransactions() ([]transaction.Tx, error) {
+	txs := make([]transaction.Tx, 0, len(d.Transactions))
+
+	for i, t := range d.Transactions {
+		txType, err := transaction.ParseTxType(t.Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse trade type of transaction %d", i)
+		}
+
+		if t.Quantity.Rat == nil || t.UnitPrice.Rat == nil {
+			return nil, fmt.Errorf("missing quantity or unit price of transaction %d", i)
+		}
+
+		txs = append(txs, transaction.Tx{
+			Position: transaction.Position{
+				Asset:     &transaction.TxAsset{Id: t.Asset},
+				Timestamp: t.Timestamp,
+				UnitPrice: t.UnitPrice.Rat,
+				Quantity:  t.Quantity.Rat,
+			},
+			Type:        txType,
+			Id:          t.Id,
+			Currency:    t.Currency,
+			Gross:       t.Gross.get(),
+			WithheldTax: t.WithheldTax.get(),
+			Country:     t.Country,
+		})
+	}
+
+	return txs, nil
+}
+
+// GetAssets converts the assets of the Document to an asset.Asset slice.
+func (d Document) GetAssets() ([]*asset.Asset, error) {
+	assets := make([]*asset.Asset, 0, len(d.Assets))
+
+	for i, a := range d.Assets {
+		if a.UnitPrice.Rat == nil {
+			return nil, fmt.Errorf("missing unit price of asset %d", i)
+		}
+
+		assets = append(assets, &asset.Asset{
+			Id:        a.Id,
+			UnitPrice: a.UnitPrice.Rat,
+			Name:      a.Name,
+			Currency:  a.Currency,
+			Tags:      a.Tags,
+		})
+	}
+
+	return assets, nil
+}
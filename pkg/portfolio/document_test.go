@@ -0,0 +1,191 @@
+package portfolio_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	assetio "github.com/wlachs/wstonks/pkg/asset/io"
+	"github.com/wlachs/wstonks/pkg/portfolio"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	txio "github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newContexts creates a transaction.Context and an asset.Context with values that cannot be represented as finite decimal numbers.
+func newContexts(t *testing.T) (*transaction.Context, *asset.Context) {
+	t.Helper()
+
+	txCtx := &transaction.Context{}
+	err := txCtx.AddTransactions([]transaction.Tx{
+		{
+			Position: transaction.Position{
+				Asset:     &transaction.TxAsset{Id: "A"},
+				Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC),
+				UnitPrice: big.NewRat(1, 3),
+				Quantity:  big.NewRat(30, 1),
+			},
+			Type:     transaction.BUY,
+			Id:       "T-1",
+			Currency: "EUR",
+		},
+		{
+			Position: transaction.Position{
+				Asset:     &transaction.TxAsset{Id: "A"},
+				Timestamp: time.Date(2024, 2, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)),
+				UnitPrice: big.NewRat(5, 7),
+				Quantity:  big.NewRat(21, 2),
+			},
+			Type: transaction.SELL,
+		},
+		{
+			Position: transaction.Position{
+				Asset:     &transaction.TxAsset{Id: "EUR"},
+				Timestamp: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				UnitPrice: big.NewRat(125, 100),
+				Quantity:  big.NewRat(1, 1),
+			},
+			Type: transaction.FEE,
+		},
+		{
+			Position: transaction.Position{
+				Asset:     &transaction.TxAsset{Id: "A"},
+				Timestamp: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+				UnitPrice: big.NewRat(17, 2),
+				Quantity:  big.NewRat(1, 1),
+			},
+			Type:        transaction.DIVIDEND,
+			Currency:    "USD",
+			Gross:       big.NewRat(10, 1),
+			WithheldTax: big.NewRat(3, 2),
+			Country:     "US",
+		},
+	})
+	assert.NoError(t, err)
+
+	assetCtx := &asset.Context{}
+	err = assetCtx.AddAssets([]*asset.Asset{
+		{Id: "A", UnitPrice: big.NewRat(2, 3), Name: "Asset A", Currency: "EUR", Tags: []string{"equity"}},
+		{Id: "B", UnitPrice: big.NewRat(12345, 100)},
+	})
+	assert.NoError(t, err)
+
+	return txCtx, assetCtx
+}
+
+// assertRoundTrip checks that the reloaded contexts equal the original ones.
+func assertRoundTrip(t *testing.T, txCtx *transaction.Context, assetCtx *asset.Context, loadedTx *transaction.Context, loadedAssets *asset.Context) {
+	t.Helper()
+
+	assert.Equal(t, len(txCtx.Transactions), len(loadedTx.Transactions))
+	for i, tx := range txCtx.Transactions {
+		loaded := loadedTx.Transactions[i]
+		assert.True(t, tx.Timestamp.Equal(loaded.Timestamp), "timestamp should match")
+		assert.Equal(t, tx.Asset.Id, loaded.Asset.Id)
+		assert.Equal(t, tx.Type, loaded.Type)
+		assert.Equal(t, tx.Id, loaded.Id)
+		assert.Equal(t, tx.Currency, loaded.Currency)
+		assert.Equal(t, 0, tx.Quantity.Cmp(loaded.Quantity), "quantity should match")
+		assert.Equal(t, 0, tx.UnitPrice.Cmp(loaded.UnitPrice), "unit price should match")
+		assert.Equal(t, tx.Gross, loaded.Gross, "gross amount should match")
+		assert.Equal(t, tx.WithheldTax, loaded.WithheldTax, "withheld tax should match")
+		assert.Equal(t, tx.Country, loaded.Country)
+	}
+
+	assert.Equal(t, assetCtx.Assets, loadedAssets.Assets)
+}
+
+// TestJsonWriter_Write tests saving and reloading the contexts as a JSON portfolio document.
+func TestJsonWriter_Write(t *testing.T) {
+	t.Parallel()
+
+	txCtx, assetCtx := newContexts(t)
+	path := filepath.Join(t.TempDir(), "portfolio.json")
+
+	err := portfolio.JsonWriter{Path: path}.Write(txCtx, assetCtx)
+	assert.NoError(t, err)
+
+	loadedTx := &transaction.Context{}
+	assert.NoError(t, txio.TxJsonLoader{Path: path}.Load(loadedTx))
+
+	loadedAssets := &asset.Context{}
+	assert.NoError(t, assetio.LiveAssetJsonLoader{Path: path}.Load(loadedAssets))
+
+	assertRoundTrip(t, txCtx, assetCtx, loadedTx, loadedAssets)
+}
+
+// TestYamlWriter_Write tests saving and reloading the contexts as a YAML portfolio document.
+func TestYamlWriter_Write(t *testing.T) {
+	t.Parallel()
+
+	txCtx, assetCtx := newContexts(t)
+	path := filepath.Join(t.TempDir(), "portfolio.yaml")
+
+	err := portfolio.YamlWriter{Path: path}.Write(txCtx, assetCtx)
+	assert.NoError(t, err)
+
+	loadedTx := &transaction.Context{}
+	assert.NoError(t, txio.TxYamlLoader{Path: path}.Load(loadedTx))
+
+	loadedAssets := &asset.Context{}
+	assert.NoError(t, assetio.LiveAssetYamlLoader{Path: path}.Load(loadedAssets))
+
+	assertRoundTrip(t, txCtx, assetCtx, loadedTx, loadedAssets)
+}
+
+// TestTxJsonWriter_Write tests saving the contexts through the TransactionWriter and LiveAssetWriter implementations.
+func TestTxJsonWriter_Write(t *testing.T) {
+	t.Parallel()
+
+	txCtx, assetCtx := newContexts(t)
+	path := filepath.Join(t.TempDir(), "portfolio.json")
+
+	var w txio.TransactionWriter = txio.TxJsonWriter{Path: path, Assets: assetCtx}
+	assert.NoError(t, w.Write(txCtx))
+
+	loadedTx := &transaction.Context{}
+	assert.NoError(t, txio.TxJsonLoader{Path: path}.Load(loadedTx))
+
+	loadedAssets := &asset.Context{}
+	assert.NoError(t, assetio.LiveAssetJsonLoader{Path: path}.Load(loadedAssets))
+
+	assertRoundTrip(t, txCtx, assetCtx, loadedTx, loadedAssets)
+
+	path = filepath.Join(t.TempDir(), "portfolio.yaml")
+	var aw assetio.LiveAssetWriter = assetio.LiveAssetYamlWriter{Path: path}
+	assert.NoError(t, aw.Write(assetCtx))
+
+	loadedTx = &transaction.Context{}
+	assert.NoError(t, txio.TxYamlLoader{Path: path}.Load(loadedTx))
+	assert.Empty(t, loadedTx.Transactions)
+
+	loadedAssets = &asset.Context{}
+	assert.NoError(t, assetio.LiveAssetYamlLoader{Path: path}.Load(loadedAssets))
+	assert.Equal(t, assetCtx.Assets, loadedAssets.Assets)
+}
+
+// TestReadFile tests loading the JSON and YAML portfolio documents.
+func TestReadFile(t *testing.T) {
+	t.Parallel()
+
+	jsonDocument, err := portfolio.ReadFile("../../test/data/io/portfolio/smoke.json", portfolio.JSON)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(jsonDocument.Transactions))
+	assert.Equal(t, []string{"equity", "europe"}, jsonDocument.Assets[0].Tags)
+	assert.Equal(t, big.NewRat(1, 3), jsonDocument.Transactions[4].UnitPrice.Rat)
+
+	yamlDocument, err := portfolio.ReadFile("../../test/data/io/portfolio/smoke.yaml", portfolio.YAML)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(yamlDocument.Transactions))
+	assert.Equal(t, big.NewRat(5121121, 100000), yamlDocument.Assets[1].UnitPrice.Rat)
+}
+
+// TestReadFile_Unsupported_Version tests loading a portfolio document written by a newer version.
+func TestReadFile_Unsupported_Version(t *testing.T) {
+	t.Parallel()
+
+	_, err := portfolio.ReadFile("../../test/data/io/portfolio/unsupported_version.json", portfolio.JSON)
+
+	assert.EqualError(t, err, "unsupported portfolio document version 99")
+}
@@ -0,0 +1,64 @@
+package portfolio
+
+import (
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"math/big"
+)
+
+// Rat wraps a big.Rat to serialize it as a string without losing precision, e.g. "12.5" or "1/3".
+type Rat struct {
+	*big.Rat
+}
+
+// NewRat creates a Rat holding a copy of the given big.Rat.
+func NewRat(r *big.Rat) Rat {
+	if r == nil {
+		return Rat{}
+	}
+
+	return Rat{big.NewRat(0, 1).Set(r)}
+}
+
+// newOptionalRat creates a *Rat holding a copy of the given big.Rat, or nil if it is nil, so optional values can be omitted.
+func newOptionalRat(r *big.Rat) *Rat {
+	if r == nil {
+		return nil
+	}
+
+	rat := NewRat(r)
+	return &rat
+}
+
+// get returns the wrapped big.Rat of an optional *Rat, or nil if it is unset.
+func (r *Rat) get() *big.Rat {
+	if r == nil {
+		return nil
+	}
+
+	return r.Rat
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (r Rat) MarshalText() ([]byte, error) {
+	if r.Rat == nil {
+		return []byte{}, nil
+	}
+
+	return []byte(ioutils.FormatRat(r.Rat)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (r *Rat) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		r.Rat = nil
+		return nil
+	}
+
+	rat, err := ioutils.ParseRat(string(text))
+	if err != nil {
+		return err
+	}
+
+	r.Rat = rat
+	return nil
+}
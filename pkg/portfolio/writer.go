@@ -0,0 +1,39 @@
+package portfolio
+
+import (
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/transaction"
+)
+
+// JsonWriter saves a transaction.Context and an asset.Context to a JSON portfolio document on Path. Since the document holds both
+// contexts, Write takes both of them; TxJsonWriter and LiveAssetJsonWriter of the io packages wrap it to implement the TransactionWriter
+// and LiveAssetWriter interfaces.
+type JsonWriter struct {
+	Path string
+}
+
+// YamlWriter saves a transaction.Context and an asset.Context to a YAML portfolio document on Path. Like JsonWriter, it is wrapped by
+// TxYamlWriter and LiveAssetYamlWriter of the io packages to implement the TransactionWriter and LiveAssetWriter interfaces.
+type YamlWriter struct {
+	Path string
+}
+
+// Write creates the JSON portfolio document from the given contexts. Either of the contexts may be nil.
+func (w JsonWriter) Write(txCtx *transaction.Context, assetCtx *asset.Context) error {
+	return writeDocument(w.Path, JSON, txCtx, assetCtx)
+}
+
+// Write creates the YAML portfolio document from the given contexts. Either of the contexts may be nil.
+func (w YamlWriter) Write(txCtx *transaction.Context, assetCtx *asset.Context) error {
+	return writeDocument(w.Path, YAML, txCtx, assetCtx)
+}
+
+// writeDocument converts the contexts to a Document and writes it in the given format to the given path.
+func writeDocument(path string, format Format, txCtx *transaction.Context, assetCtx *asset.Context) error {
+	d, err := NewDocument(txCtx, assetCtx)
+	if err != nil {
+		return err
+	}
+
+	return WriteFile(path, format, d)
+}
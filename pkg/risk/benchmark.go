@@ -0,0 +1,222 @@
+package risk
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/mathutils"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math"
+	"math/big"
+	"slices"
+)
+
+// BenchmarkComparison holds the performance of a series relative to a benchmark. Alpha, tracking error and information ratio are
+// annualized.
+type BenchmarkComparison struct {
+	// Beta is the sensitivity of the returns to the returns of the benchmark. It is NaN if the benchmark is constant.
+	Beta float64
+	// Alpha is the annualized excess return over the return expected from the beta and the risk-free rate (Jensen's alpha).
+	Alpha       float64
+	Correlation float64
+	// TrackingError is the annualized standard deviation of the returns in excess of the benchmark.
+	TrackingError float64
+	// InformationRatio is the annualized mean excess return over the benchmark per unit of tracking error.
+	InformationRatio float64
+}
+
+// Compare compares the returns of the series with the returns of the benchmark over the periods both series have in common. Periods one of
+// the series skips, e.g. because the portfolio held nothing at their start, are left out of the comparison.
+func Compare(s Series, benchmark Series, config Config) (*BenchmarkComparison, error) {
+	if config.PeriodsPerYear < 0 {
+		return nil, fmt.Errorf("periods per year shouldn't be negative")
+	}
+
+	aligned := Align(s, benchmark)
+	rp, rb := joinReturns(aligned[0].Returns(), aligned[1].Returns())
+	if len(rp) < 2 {
+		return nil, fmt.Errorf("at least three common values are needed to compare with the benchmark")
+	}
+
+	periods := config.periodsPerYear()
+	rf := config.RiskFreeRate / periods
+
+	active := make([]float64, len(rp))
+	for i := range rp {
+		active[i] = rp[i] - rb[i]
+	}
+
+	c := &BenchmarkComparison{
+		Beta:          ratio(mathutils.Covariance(rp, rb), mathutils.Covariance(rb, rb)),
+		Correlation:   mathutils.Correlation(rp, rb),
+		TrackingError: mathutils.StdDev(active) * math.Sqrt(periods),
+	}
+	c.Alpha = (mathutils.Mean(rp) - rf - c.Beta*(mathutils.Mean(rb)-rf)) * periods
+	c.InformationRatio = ratio(mathutils.Mean(active)*periods, c.TrackingError)
+
+	return c, nil
+}
+
+// joinReturns pairs the returns of the two series ending at the same time and returns their values. Returns without counterpart are
+// dropped.
+func joinReturns(a Series, b Series) ([]float64, []float64) {
+	var ra, rb []float64
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch c := a[i].Timestamp.Compare(b[j].Timestamp); {
+		case c < 0:
+			i++
+		case c > 0:
+			j++
+		default:
+			ra, rb = append(ra, a[i].Value), append(rb, b[j].Value)
+			i++
+			j++
+		}
+	}
+
+	return ra, rb
+}
+
+// PortfolioBenchmark compares the Valuation of the portfolio with the historical prices of the benchmark asset.
+func PortfolioBenchmark(txCtx *transaction.Context, h *asset.PriceHistory, benchmarkId string, config Config) (*BenchmarkComparison, error) {
+	benchmark, err := benchmarkSeries(h, benchmarkId)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := Valuation(txCtx, h)
+	if err != nil {
+		return nil, err
+	}
+
+	return Compare(s, benchmark, config)
+}
+
+// AssetBenchmark compares the historical prices of every asset of the transaction history with the historical prices of the benchmark
+// asset. Assets that were never held, e.g. the currencies of deposits, and assets without historical prices are skipped.
+func AssetBenchmark(txCtx *transaction.Context, h *asset.PriceHistory, benchmarkId string, config Config) (map[string]*BenchmarkComparison, error) {
+	benchmark, err := benchmarkSeries(h, benchmarkId)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]*BenchmarkComparison{}
+	for _, id := range heldAssets(txCtx, h) {
+		c, compareErr := Compare(PriceSeries(h, id), benchmark, config)
+		if compareErr != nil {
+			return nil, fmt.Errorf("failed to compare %s with the benchmark: %w", id, compareErr)
+		}
+
+		m[id] = c
+	}
+
+	return m, nil
+}
+
+// benchmarkSeries returns the price series of the benchmark asset.
+func benchmarkSeries(h *asset.PriceHistory, benchmarkId string) (Series, error) {
+	s := PriceSeries(h, benchmarkId)
+	if len(s) == 0 {
+		return nil, fmt.Errorf("missing price history of benchmark %s", benchmarkId)
+	}
+
+	return s, nil
+}
+
+// ShadowComparison compares the portfolio with its shadow portfolio.
+type ShadowComparison struct {
+	// Shadow holds the transactions of the shadow portfolio.
+	Shadow *transaction.Context
+	// Portfolio and Benchmark hold the valuations of the portfolio and of the shadow portfolio at their common timestamps.
+	Portfolio Series
+	Benchmark Series
+	// Difference is the final value of the portfolio minus the final value of the shadow portfolio.
+	Difference float64
+}
+
+// ShadowPortfolio creates a shadow portfolio which invests the cash flows of the transaction history into the benchmark asset instead:
+// every purchase buys the benchmark for the same amount and every sale sells the benchmark for the same proceeds, or all of it if the
+// shadow portfolio holds less. The benchmark is traded at its latest historical price at the time of the transaction, or at the next one
+// if there is none yet. Dividends, fees and taxes are not mirrored.
+func ShadowPortfolio(txCtx *transaction.Context, h *asset.PriceHistory, benchmarkId string) (*transaction.Context, error) {
+	transactions := txCtx.GetTransactions()
+	slices.SortStableFunc(transactions, func(a, b *transaction.Tx) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	held := big.NewRat(0, 1)
+	var shadow []transaction.Tx
+	for _, tx := range transactions {
+		if tx.Type != transaction.BUY && tx.Type != transaction.SELL {
+			continue
+		}
+
+		price, ok := h.PriceAt(benchmarkId, tx.Timestamp)
+		if !ok {
+			price, ok = h.PriceOnOrAfter(benchmarkId, tx.Timestamp)
+		}
+		if !ok || price.UnitPrice.Sign() == 0 {
+			return nil, fmt.Errorf("missing price of benchmark %s at %s", benchmarkId, tx.Timestamp)
+		}
+
+		quantity := big.NewRat(0, 1).Mul(tx.Quantity, tx.UnitPrice)
+		quantity.Quo(quantity, price.UnitPrice)
+
+		if tx.Type == transaction.BUY {
+			held.Add(held, quantity)
+		} else {
+			if quantity.Cmp(held) > 0 {
+				quantity.Set(held)
+			}
+			held.Sub(held, quantity)
+		}
+
+		if quantity.Sign() == 0 {
+			continue
+		}
+
+		shadow = append(shadow, transaction.Tx{
+			Position: transaction.Position{
+				Asset:     &transaction.TxAsset{Id: benchmarkId},
+				Timestamp: tx.Timestamp,
+				Quantity:  quantity,
+				UnitPrice: big.NewRat(0, 1).Set(price.UnitPrice),
+			},
+			Type:     tx.Type,
+			Id:       tx.Id,
+			Currency: tx.Currency,
+		})
+	}
+
+	ctx := &transaction.Context{}
+	if err := ctx.AddTransactions(shadow); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// CompareShadow values the portfolio and its ShadowPortfolio invested into the benchmark asset over time.
+func CompareShadow(txCtx *transaction.Context, h *asset.PriceHistory, benchmarkId string) (*ShadowComparison, error) {
+	shadow, err := ShadowPortfolio(txCtx, h, benchmarkId)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolio, err := Valuation(txCtx, h)
+	if err != nil {
+		return nil, err
+	}
+
+	benchmark, err := Valuation(shadow, h)
+	if err != nil {
+		return nil, err
+	}
+
+	aligned := Align(portfolio, benchmark)
+	c := &ShadowComparison{Shadow: shadow, Portfolio: aligned[0], Benchmark: aligned[1]}
+	if n := len(c.Portfolio); n > 0 {
+		c.Difference = c.Portfolio[n-1].Value - c.Benchmark[n-1].Value
+	}
+
+	return c, nil
+}
@@ -0,0 +1,154 @@
+package risk_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/risk"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math"
+	"math/big"
+	"testing"
+)
+
+// series creates a series with a value on every day from the 1st of January 2024 on.
+func series(values ...float64) risk.Series {
+	s := make(risk.Series, len(values))
+	for i, v := range values {
+		s[i] = risk.Point{Timestamp: day(i + 1), Value: v}
+	}
+
+	return s
+}
+
+// TestCompare checks the comparison of a series moving twice as much as the benchmark.
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	c, err := risk.Compare(series(100, 120, 96, 115.2), series(100, 110, 99, 108.9), risk.Config{PeriodsPerYear: 1})
+	assert.NoError(t, err)
+
+	assert.InDelta(t, 2, c.Beta, 1e-9)
+	assert.InDelta(t, 1, c.Correlation, 1e-9)
+	assert.InDelta(t, 0, c.Alpha, 1e-9)
+	assert.InDelta(t, 0.115470, c.TrackingError, 1e-6)
+	assert.InDelta(t, 0.288675, c.InformationRatio, 1e-6)
+}
+
+// TestCompare_Calendars checks that only the periods both series have in common are compared.
+func TestCompare_Calendars(t *testing.T) {
+	t.Parallel()
+
+	s := series(100, 120, 96, 115.2, 138.24)
+	benchmark := risk.Series{s[0], s[2], s[3], s[4]}
+	for i := range benchmark {
+		benchmark[i].Value /= 2
+	}
+
+	c, err := risk.Compare(s, benchmark, risk.Config{})
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, c.Beta, 1e-9)
+	assert.InDelta(t, 0, c.TrackingError, 1e-9)
+	assert.True(t, math.IsNaN(c.InformationRatio))
+
+	_, err = risk.Compare(s, series(1, 2), risk.Config{})
+	assert.Error(t, err)
+}
+
+// TestCompare_Skipped_Periods checks that periods starting at a zero value are left out of the comparison instead of shifting the returns
+// against the returns of the benchmark.
+func TestCompare_Skipped_Periods(t *testing.T) {
+	t.Parallel()
+
+	s := series(0, 100, 80, 0, 100, 80, 96)
+	s[1].Flow, s[3].Flow, s[4].Flow = 100, -96, 100
+	benchmark := series(100, 110, 99, 108.9, 98.01, 88.209, 97.0299)
+
+	c, err := risk.Compare(s, benchmark, risk.Config{PeriodsPerYear: 1})
+	assert.NoError(t, err)
+	assert.InDelta(t, 2, c.Beta, 1e-9, "the series moves twice as much as the benchmark in the periods it is invested")
+	assert.InDelta(t, 1, c.Correlation, 1e-9)
+
+	_, err = risk.Compare(series(0, 0, 0, 100, 110), series(100, 110, 99, 108.9, 98.01), risk.Config{})
+	assert.Error(t, err, "a single common return cannot be compared")
+}
+
+// TestPortfolioBenchmark checks a portfolio holding nothing but the benchmark.
+func TestPortfolioBenchmark(t *testing.T) {
+	t.Parallel()
+
+	h := history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}, "B": {50, 51, 52, 50, 49}})
+	ctx := transaction.Context{}
+	assert.NoError(t, ctx.AddTransactions([]transaction.Tx{newTx("A", transaction.BUY, 1, 3, 100)}))
+
+	c, err := risk.PortfolioBenchmark(&ctx, h, "A", risk.Config{})
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, c.Beta, 1e-9)
+	assert.InDelta(t, 0, c.Alpha, 1e-9)
+
+	c, err = risk.PortfolioBenchmark(&ctx, h, "B", risk.Config{})
+	assert.NoError(t, err)
+
+	m, err := risk.AssetBenchmark(&ctx, h, "B", risk.Config{})
+	assert.NoError(t, err)
+	assert.Len(t, m, 1)
+	assert.InDelta(t, c.Beta, m["A"].Beta, 1e-9)
+	assert.InDelta(t, c.TrackingError, m["A"].TrackingError, 1e-9)
+
+	_, err = risk.PortfolioBenchmark(&ctx, h, "C", risk.Config{})
+	assert.Error(t, err)
+}
+
+// TestAssetBenchmark_Cash checks that the currencies of deposits and interest, which have no historical prices, are skipped.
+func TestAssetBenchmark_Cash(t *testing.T) {
+	t.Parallel()
+
+	h := history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}, "B": {50, 51, 52, 50, 49}})
+	ctx := transaction.Context{}
+	assert.NoError(t, ctx.AddTransactions([]transaction.Tx{
+		newTx("EUR", transaction.DEPOSIT, 1, 1, 1000),
+		newTx("A", transaction.BUY, 1, 1, 100),
+		newTx("EUR", transaction.INTEREST, 3, 1, 2),
+	}))
+
+	m, err := risk.AssetBenchmark(&ctx, h, "B", risk.Config{})
+	assert.NoError(t, err)
+	assert.Len(t, m, 1)
+	assert.Contains(t, m, "A")
+}
+
+// TestShadowPortfolio checks that purchases and sales are mirrored with the benchmark and sales are limited to the shadow holdings.
+func TestShadowPortfolio(t *testing.T) {
+	t.Parallel()
+
+	h := history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}, "B": {50, 50, 55, 60, 40}})
+	ctx := transaction.Context{}
+	assert.NoError(t, ctx.AddTransactions([]transaction.Tx{
+		newTx("A", transaction.BUY, 1, 2, 100),
+		newTx("A", transaction.DIVIDEND, 2, 1, 5),
+		newTx("A", transaction.SELL, 4, 1, 121),
+	}))
+
+	shadow, err := risk.ShadowPortfolio(&ctx, h, "B")
+	assert.NoError(t, err)
+	txs := shadow.GetTransactions()
+	assert.Len(t, txs, 2)
+	assert.Equal(t, big.NewRat(4, 1), txs[0].Quantity)
+	assert.Equal(t, transaction.SELL, txs[1].Type)
+	assert.Equal(t, big.NewRat(121, 60), txs[1].Quantity)
+
+	c, err := risk.CompareShadow(&ctx, h, "B")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{200, 220, 198, 121, 110}, c.Portfolio.Values())
+	assert.InDelta(t, 110-119.0/60*40, c.Difference, 1e-9)
+
+	ctx = transaction.Context{}
+	assert.NoError(t, ctx.AddTransactions([]transaction.Tx{
+		newTx("A", transaction.BUY, 1, 1, 100),
+		newTx("A", transaction.BUY, 2, 1, 110),
+		newTx("A", transaction.SELL, 5, 2, 110),
+	}))
+
+	shadow, err = risk.ShadowPortfolio(&ctx, h, "B")
+	assert.NoError(t, err)
+	assert.Len(t, shadow.GetTransactions(), 3)
+	assert.Empty(t, shadow.GetAssetKeyMap())
+}
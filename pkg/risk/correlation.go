@@ -0,0 +1,140 @@
+package risk
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/mathutils"
+	"math"
+	"time"
+)
+
+// CovarianceMatrix holds the pairwise covariances and correlations of the returns of a set of assets.
+type CovarianceMatrix struct {
+	// AssetIds defines the order of the rows and columns of the matrices.
+	AssetIds []string
+	// Covariance holds the annualized covariances of the returns.
+	Covariance [][]float64
+	// Correlation holds the correlation coefficients of the returns. The correlation with a constant asset is NaN.
+	Correlation [][]float64
+	// MeanReturns holds the annualized arithmetic mean of the returns of every asset.
+	MeanReturns []float64
+	// Observations is the number of returns per asset the matrices are based on.
+	Observations int
+}
+
+// Covariances calculates the covariance and correlation matrices of the assets from their historical prices between start and end. A zero
+// start or end leaves the window open at that side. Only the timestamps all assets have a price at are used, so missing data points and
+// mismatched trading calendars don't distort the returns: the returns of every asset always refer to the same periods.
+func Covariances(h *asset.PriceHistory, assetIds []string, start time.Time, end time.Time, config Config) (*CovarianceMatrix, error) {
+	returns, err := alignedReturns(h, assetIds, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.PeriodsPerYear < 0 {
+		return nil, fmt.Errorf("periods per year shouldn't be negative")
+	}
+
+	periods := config.periodsPerYear()
+	n := len(assetIds)
+	m := &CovarianceMatrix{
+		AssetIds:     append([]string(nil), assetIds...),
+		Covariance:   make([][]float64, n),
+		Correlation:  make([][]float64, n),
+		MeanReturns:  make([]float64, n),
+		Observations: len(returns[0]),
+	}
+
+	for i := range n {
+		m.MeanReturns[i] = mathutils.Mean(returns[i]) * periods
+		m.Covariance[i] = make([]float64, n)
+		m.Correlation[i] = make([]float64, n)
+		for j := range n {
+			m.Covariance[i][j] = mathutils.Covariance(returns[i], returns[j]) * periods
+			m.Correlation[i][j] = mathutils.Correlation(returns[i], returns[j])
+		}
+	}
+
+	return m, nil
+}
+
+// Variance calculates the annualized variance of a portfolio holding the assets of the matrix with the given weights.
+func (m *CovarianceMatrix) Variance(weights []float64) float64 {
+	variance := 0.0
+	for i := range weights {
+		for j := range weights {
+			variance += weights[i] * weights[j] * m.Covariance[i][j]
+		}
+	}
+
+	return variance
+}
+
+// DiversificationRatio calculates the weighted average volatility of the assets divided by the volatility of the portfolio, with the
+// weights given by GetAssetRatio. A ratio of one means no diversification, e.g. a single asset or perfectly correlated assets; the less
+// correlated the assets, the higher the ratio. The volatilities are based on the historical prices between start and end.
+func DiversificationRatio(ctx *calculation.Context, h *asset.PriceHistory, assets []*asset.Asset, start time.Time, end time.Time, config Config) (float64, error) {
+	ratios, err := ctx.GetAssetRatio(assets)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := make([]string, len(assets))
+	weights := make([]float64, len(assets))
+	for i, a := range assets {
+		ids[i] = a.Id
+		weights[i], _ = ratios[a].Float64()
+	}
+
+	m, err := Covariances(h, ids, start, end, config)
+	if err != nil {
+		return 0, err
+	}
+
+	weighted := 0.0
+	for i, w := range weights {
+		weighted += w * math.Sqrt(m.Covariance[i][i])
+	}
+
+	volatility := math.Sqrt(m.Variance(weights))
+	if volatility == 0 {
+		return 0, fmt.Errorf("the portfolio has no volatility")
+	}
+
+	return weighted / volatility, nil
+}
+
+// alignedReturns calculates the returns of the historical prices of the assets between start and end at the timestamps all assets have a
+// price at.
+func alignedReturns(h *asset.PriceHistory, assetIds []string, start time.Time, end time.Time) ([][]float64, error) {
+	if len(assetIds) == 0 {
+		return nil, fmt.Errorf("no assets given")
+	}
+
+	series := make([]Series, len(assetIds))
+	for i, id := range assetIds {
+		series[i] = PriceSeries(h, id).Between(start, end)
+		if len(series[i]) == 0 {
+			return nil, fmt.Errorf("missing price history of %s", id)
+		}
+	}
+
+	aligned := Align(series...)
+	returns := make([][]float64, len(aligned))
+	for i, s := range aligned {
+		returns[i] = s.Returns().Values()
+	}
+
+	if len(returns[0]) < 2 {
+		return nil, fmt.Errorf("at least three common prices are needed, found %d", len(aligned[0]))
+	}
+
+	for i := range returns {
+		if len(returns[i]) != len(returns[0]) {
+			return nil, fmt.Errorf("zero price of %s", assetIds[i])
+		}
+	}
+
+	return returns, nil
+}
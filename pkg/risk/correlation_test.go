@@ -0,0 +1,111 @@
+package risk_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/risk"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// TestCovariances checks the matrices of perfectly correlated, uncorrelated and constant assets.
+func TestCovariances(t *testing.T) {
+	t.Parallel()
+
+	h := history(t, map[string][]int64{
+		"A": {100, 110, 99, 121, 110},
+		"B": {200, 220, 198, 242, 220},
+		"C": {50, 50, 50, 50, 50},
+	})
+
+	m, err := risk.Covariances(h, []string{"A", "B", "C"}, time.Time{}, time.Time{}, risk.Config{PeriodsPerYear: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A", "B", "C"}, m.AssetIds)
+	assert.Equal(t, 4, m.Observations)
+	assert.InDelta(t, 0.032828, m.MeanReturns[0], 1e-6)
+	assert.Equal(t, 0.0, m.MeanReturns[2])
+
+	assert.InDelta(t, 0.156350*0.156350, m.Covariance[0][0], 1e-6)
+	assert.InDelta(t, m.Covariance[0][0], m.Covariance[0][1], 1e-12)
+	assert.InDelta(t, 1, m.Correlation[0][1], 1e-12)
+	assert.Equal(t, 0.0, m.Covariance[2][2])
+	assert.True(t, math.IsNaN(m.Correlation[0][2]))
+}
+
+// TestCovariances_Window checks that only the prices within the window are used, and that too short windows are rejected.
+func TestCovariances_Window(t *testing.T) {
+	t.Parallel()
+
+	h := history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}, "B": {100, 90, 99, 121, 110}})
+
+	m, err := risk.Covariances(h, []string{"A", "B"}, day(3), time.Time{}, risk.Config{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, m.Observations)
+	assert.InDelta(t, 1, m.Correlation[0][1], 1e-12)
+
+	_, err = risk.Covariances(h, []string{"A", "B"}, day(4), time.Time{}, risk.Config{})
+	assert.Error(t, err)
+
+	_, err = risk.Covariances(h, []string{"A", "X"}, time.Time{}, time.Time{}, risk.Config{})
+	assert.Error(t, err)
+}
+
+// TestCovariances_Calendars checks that the returns of assets with different trading calendars refer to the same periods.
+func TestCovariances_Calendars(t *testing.T) {
+	t.Parallel()
+
+	h := history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}})
+	for _, d := range []int{1, 3, 4, 5, 6} {
+		p, ok := h.PriceAt("A", day(d))
+		assert.True(t, ok)
+		assert.NoError(t, h.AddPrice("B", asset.PricePoint{Timestamp: day(d), UnitPrice: big.NewRat(0, 1).Mul(p.UnitPrice, big.NewRat(3, 1))}))
+	}
+
+	m, err := risk.Covariances(h, []string{"A", "B"}, time.Time{}, time.Time{}, risk.Config{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, m.Observations)
+	assert.InDelta(t, 1, m.Correlation[0][1], 1e-12)
+}
+
+// TestDiversificationRatio checks the ratio of a portfolio of perfectly correlated assets and of a diversified portfolio.
+func TestDiversificationRatio(t *testing.T) {
+	t.Parallel()
+
+	h := history(t, map[string][]int64{
+		"A": {100, 110, 99, 121, 110},
+		"B": {200, 220, 198, 242, 220},
+		"C": {100, 95, 105, 100, 104},
+	})
+
+	txCtx := transaction.Context{}
+	assert.NoError(t, txCtx.AddTransactions([]transaction.Tx{
+		newTx("A", transaction.BUY, 1, 1, 100),
+		newTx("B", transaction.BUY, 1, 1, 200),
+		newTx("C", transaction.BUY, 1, 2, 100),
+	}))
+
+	assetCtx := asset.Context{}
+	a := &asset.Asset{Id: "A", UnitPrice: big.NewRat(110, 1)}
+	b := &asset.Asset{Id: "B", UnitPrice: big.NewRat(220, 1)}
+	c := &asset.Asset{Id: "C", UnitPrice: big.NewRat(110, 1)}
+	assert.NoError(t, assetCtx.AddAssets([]*asset.Asset{a, b, c}))
+	ctx := calculation.Context{AssetContext: &assetCtx, TransactionContext: &txCtx}
+
+	r, err := risk.DiversificationRatio(&ctx, h, []*asset.Asset{a, b}, time.Time{}, time.Time{}, risk.Config{})
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, r, 1e-9)
+
+	r, err = risk.DiversificationRatio(&ctx, h, []*asset.Asset{a, c}, time.Time{}, time.Time{}, risk.Config{})
+	assert.NoError(t, err)
+
+	m, err := risk.Covariances(h, []string{"A", "C"}, time.Time{}, time.Time{}, risk.Config{})
+	assert.NoError(t, err)
+	weights := []float64{110.0 / 330, 220.0 / 330}
+	expected := (weights[0]*math.Sqrt(m.Covariance[0][0]) + weights[1]*math.Sqrt(m.Covariance[1][1])) / math.Sqrt(m.Variance(weights))
+	assert.InDelta(t, expected, r, 1e-9)
+	assert.Greater(t, r, 1.0)
+}
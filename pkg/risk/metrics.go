@@ -0,0 +1,161 @@
+package risk
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/mathutils"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math"
+	"time"
+)
+
+// Config configures the calculation of risk metrics.
+type Config struct {
+	// RiskFreeRate is the annual risk-free rate, e.g. 0.03 for 3%.
+	RiskFreeRate float64
+	// PeriodsPerYear is the number of periods of the series per year, used to annualize the metrics, e.g. 252 for daily prices of
+	// trading days or 12 for monthly prices. Zero defaults to 252.
+	PeriodsPerYear float64
+}
+
+// periodsPerYear returns the configured number of periods per year or the default.
+func (c Config) periodsPerYear() float64 {
+	if c.PeriodsPerYear == 0 {
+		return 252
+	}
+
+	return c.PeriodsPerYear
+}
+
+// Drawdown describes a decline of the value from a peak to a trough.
+type Drawdown struct {
+	// Depth is the decline as a share of the peak value, e.g. 0.25 for a drop of 25%.
+	Depth  float64
+	Peak   time.Time
+	Trough time.Time
+	// Recovery is the time the value first reached the peak again. It is zero if the value has not recovered yet.
+	Recovery time.Time
+	// RecoveryTime is the time between the trough and the recovery. It is zero if the value has not recovered yet.
+	RecoveryTime time.Duration
+}
+
+// Metrics holds the risk metrics of a series. Returns and ratios are annualized.
+type Metrics struct {
+	// Return is the annualized arithmetic mean of the returns.
+	Return float64
+	// Volatility is the annualized standard deviation of the returns.
+	Volatility float64
+	// DownsideDeviation is the annualized root mean square of the returns below the risk-free rate.
+	DownsideDeviation float64
+	// Sharpe is the excess return over the risk-free rate per unit of volatility. It is NaN if there is no volatility.
+	Sharpe float64
+	// Sortino is the excess return over the risk-free rate per unit of downside deviation. It is NaN if there is no downside deviation.
+	Sortino     float64
+	MaxDrawdown Drawdown
+}
+
+// Calculate calculates the risk metrics of the series. Drawdowns are measured on the cumulated returns, so cash flows don't affect them.
+func Calculate(s Series, config Config) (*Metrics, error) {
+	if config.PeriodsPerYear < 0 {
+		return nil, fmt.Errorf("periods per year shouldn't be negative")
+	}
+
+	returns := s.Returns()
+	if len(returns) < 2 {
+		return nil, fmt.Errorf("at least three values are needed to calculate risk metrics")
+	}
+
+	periods := config.periodsPerYear()
+	rf := config.RiskFreeRate / periods
+	values := returns.Values()
+
+	m := &Metrics{
+		Return:            mathutils.Mean(values) * periods,
+		Volatility:        mathutils.StdDev(values) * math.Sqrt(periods),
+		DownsideDeviation: downsideDeviation(values, rf) * math.Sqrt(periods),
+		MaxDrawdown:       maxDrawdown(s[0].Timestamp, returns),
+	}
+
+	excess := m.Return - config.RiskFreeRate
+	m.Sharpe = ratio(excess, m.Volatility)
+	m.Sortino = ratio(excess, m.DownsideDeviation)
+
+	return m, nil
+}
+
+// PortfolioMetrics calculates the risk metrics of the portfolio based on its Valuation.
+func PortfolioMetrics(txCtx *transaction.Context, h *asset.PriceHistory, config Config) (*Metrics, error) {
+	s, err := Valuation(txCtx, h)
+	if err != nil {
+		return nil, err
+	}
+
+	return Calculate(s, config)
+}
+
+// AssetMetrics calculates the risk metrics of every asset of the transaction history based on its historical prices. Assets that were
+// never held, e.g. the currencies of deposits, and assets without historical prices are skipped.
+func AssetMetrics(txCtx *transaction.Context, h *asset.PriceHistory, config Config) (map[string]*Metrics, error) {
+	m := map[string]*Metrics{}
+	for _, id := range heldAssets(txCtx, h) {
+		metrics, err := Calculate(PriceSeries(h, id), config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate risk metrics of %s: %w", id, err)
+		}
+
+		m[id] = metrics
+	}
+
+	return m, nil
+}
+
+// downsideDeviation calculates the root mean square of the returns below the threshold.
+func downsideDeviation(returns []float64, threshold float64) float64 {
+	sum := 0.0
+	for _, r := range returns {
+		if d := math.Min(r-threshold, 0); d < 0 {
+			sum += d * d
+		}
+	}
+
+	return math.Sqrt(sum / float64(len(returns)))
+}
+
+// maxDrawdown finds the largest decline of the cumulated returns. The cumulated value is 1 at start, the timestamp of the first value.
+func maxDrawdown(start time.Time, returns Series) Drawdown {
+	wealth, peak := 1.0, 1.0
+	peakTime := start
+
+	var worst Drawdown
+	var worstPeak float64
+	for _, r := range returns {
+		wealth *= 1 + r.Value
+
+		if wealth >= peak {
+			peak, peakTime = wealth, r.Timestamp
+
+			if worst.Depth > 0 && worst.Recovery.IsZero() && wealth >= worstPeak {
+				worst.Recovery = r.Timestamp
+				worst.RecoveryTime = r.Timestamp.Sub(worst.Trough)
+			}
+
+			continue
+		}
+
+		if depth := 1 - wealth/peak; depth > worst.Depth {
+			worst = Drawdown{Depth: depth, Peak: peakTime, Trough: r.Timestamp}
+			worstPeak = peak
+		}
+	}
+
+	return worst
+}
+
+// ratio divides the excess return by the risk measure. NaN is returned if there is no risk.
+func ratio(excess float64, risk float64) float64 {
+	if risk == 0 {
+		return math.NaN()
+	}
+
+	return excess / risk
+}
@@ -0,0 +1,129 @@
+package risk_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/risk"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestCalculate checks the metrics of a price series with a drawdown which recovers.
+func TestCalculate(t *testing.T) {
+	t.Parallel()
+
+	s := risk.PriceSeries(history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}}), "A")
+	m, err := risk.Calculate(s, risk.Config{PeriodsPerYear: 1})
+	assert.NoError(t, err)
+
+	assert.InDelta(t, 0.032828, m.Return, 1e-6)
+	assert.InDelta(t, 0.156350, m.Volatility, 1e-6)
+	assert.InDelta(t, 0.067573, m.DownsideDeviation, 1e-6)
+	assert.InDelta(t, 0.209966, m.Sharpe, 1e-6)
+	assert.InDelta(t, 0.485819, m.Sortino, 1e-6)
+
+	assert.InDelta(t, 0.1, m.MaxDrawdown.Depth, 1e-12)
+	assert.Equal(t, day(2), m.MaxDrawdown.Peak)
+	assert.Equal(t, day(3), m.MaxDrawdown.Trough)
+	assert.Equal(t, day(4), m.MaxDrawdown.Recovery)
+	assert.Equal(t, 24*time.Hour, m.MaxDrawdown.RecoveryTime)
+}
+
+// TestCalculate_RiskFreeRate checks that the risk-free rate is subtracted from the returns.
+func TestCalculate_RiskFreeRate(t *testing.T) {
+	t.Parallel()
+
+	s := risk.PriceSeries(history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}}), "A")
+	m, err := risk.Calculate(s, risk.Config{RiskFreeRate: 0.01, PeriodsPerYear: 1})
+	assert.NoError(t, err)
+
+	assert.InDelta(t, 0.074637, m.DownsideDeviation, 1e-6)
+	assert.InDelta(t, 0.146007, m.Sharpe, 1e-6)
+	assert.InDelta(t, 0.305858, m.Sortino, 1e-6)
+}
+
+// TestCalculate_Annualized checks that daily metrics are annualized with 252 periods by default.
+func TestCalculate_Annualized(t *testing.T) {
+	t.Parallel()
+
+	s := risk.PriceSeries(history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}}), "A")
+	daily, err := risk.Calculate(s, risk.Config{PeriodsPerYear: 1})
+	assert.NoError(t, err)
+
+	annual, err := risk.Calculate(s, risk.Config{})
+	assert.NoError(t, err)
+	assert.InDelta(t, daily.Return*252, annual.Return, 1e-12)
+	assert.InDelta(t, daily.Volatility*math.Sqrt(252), annual.Volatility, 1e-12)
+}
+
+// TestCalculate_NoRecovery checks a drawdown which has not recovered yet and a series without losses.
+func TestCalculate_NoRecovery(t *testing.T) {
+	t.Parallel()
+
+	s := risk.PriceSeries(history(t, map[string][]int64{"A": {100, 120, 90, 60, 80}, "B": {100, 101, 102}}), "A")
+	m, err := risk.Calculate(s, risk.Config{})
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.5, m.MaxDrawdown.Depth, 1e-12)
+	assert.Equal(t, day(4), m.MaxDrawdown.Trough)
+	assert.True(t, m.MaxDrawdown.Recovery.IsZero())
+
+	s = risk.PriceSeries(history(t, map[string][]int64{"B": {100, 101, 102}}), "B")
+	m, err = risk.Calculate(s, risk.Config{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, m.MaxDrawdown.Depth)
+	assert.True(t, math.IsNaN(m.Sortino))
+}
+
+// TestCalculate_Invalid checks that too short series and invalid configurations are rejected.
+func TestCalculate_Invalid(t *testing.T) {
+	t.Parallel()
+
+	s := risk.PriceSeries(history(t, map[string][]int64{"A": {100, 110}}), "A")
+	_, err := risk.Calculate(s, risk.Config{})
+	assert.Error(t, err)
+
+	s = risk.PriceSeries(history(t, map[string][]int64{"A": {100, 110, 120}}), "A")
+	_, err = risk.Calculate(s, risk.Config{PeriodsPerYear: -1})
+	assert.Error(t, err)
+}
+
+// TestPortfolioMetrics checks that the metrics of the portfolio and of every asset are calculated.
+func TestPortfolioMetrics(t *testing.T) {
+	t.Parallel()
+
+	h := history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}, "B": {50, 51, 52, 50, 49}})
+	ctx := transaction.Context{}
+	assert.NoError(t, ctx.AddTransactions([]transaction.Tx{
+		newTx("A", transaction.BUY, 1, 1, 100),
+		newTx("B", transaction.BUY, 1, 2, 50),
+	}))
+
+	m, err := risk.PortfolioMetrics(&ctx, h, risk.Config{})
+	assert.NoError(t, err)
+	assert.Equal(t, day(4), m.MaxDrawdown.Peak)
+	assert.InDelta(t, 1-208.0/221, m.MaxDrawdown.Depth, 1e-12)
+
+	assets, err := risk.AssetMetrics(&ctx, h, risk.Config{})
+	assert.NoError(t, err)
+	assert.Len(t, assets, 2)
+	assert.InDelta(t, 0.1, assets["A"].MaxDrawdown.Depth, 1e-12)
+}
+
+// TestAssetMetrics_Cash checks that the currencies of deposits and interest, which have no historical prices, are skipped.
+func TestAssetMetrics_Cash(t *testing.T) {
+	t.Parallel()
+
+	h := history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}})
+	ctx := transaction.Context{}
+	assert.NoError(t, ctx.AddTransactions([]transaction.Tx{
+		newTx("EUR", transaction.DEPOSIT, 1, 1, 1000),
+		newTx("A", transaction.BUY, 1, 1, 100),
+		newTx("EUR", transaction.INTEREST, 3, 1, 2),
+	}))
+
+	assets, err := risk.AssetMetrics(&ctx, h, risk.Config{})
+	assert.NoError(t, err)
+	assert.Len(t, assets, 1)
+	assert.Contains(t, assets, "A")
+}
@@ -0,0 +1,245 @@
+package risk
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"slices"
+	"time"
+)
+
+// Point is a single observation of a Series.
+type Point struct {
+	Timestamp time.Time
+	Value     float64
+	// Flow is the net external cash flow into the portfolio since the previous point, e.g. the cost of purchases minus the proceeds of
+	// sales and the dividends received. It is zero for price series.
+	Flow float64
+}
+
+// Series is a chronologically ordered sequence of values, e.g. the prices of an asset or the valuation of a portfolio.
+type Series []Point
+
+// Values returns the values of the series.
+func (s Series) Values() []float64 {
+	values := make([]float64, len(s))
+	for i, p := range s {
+		values[i] = p.Value
+	}
+
+	return values
+}
+
+// Returns calculates the simple return of every period of the series. The return of a period is time-weighted, i.e. the cash flow of the
+// period is removed from the change of the value, so deposits and sales are not mistaken for performance. Periods starting at a zero value
+// are skipped. The timestamp of a return is the end of its period.
+func (s Series) Returns() Series {
+	var returns Series
+	for i := 1; i < len(s); i++ {
+		if s[i-1].Value == 0 {
+			continue
+		}
+
+		r := (s[i].Value-s[i].Flow)/s[i-1].Value - 1
+		returns = append(returns, Point{Timestamp: s[i].Timestamp, Value: r})
+	}
+
+	return returns
+}
+
+// Between returns the points of the series from start up to and including end. A zero start or end leaves the series open at that side.
+func (s Series) Between(start time.Time, end time.Time) Series {
+	var result Series
+	for _, p := range s {
+		if (start.IsZero() || !p.Timestamp.Before(start)) && (end.IsZero() || !p.Timestamp.After(end)) {
+			result = append(result, p)
+		}
+	}
+
+	return result
+}
+
+// PriceSeries converts the historical prices of the asset to a Series.
+func PriceSeries(h *asset.PriceHistory, assetId string) Series {
+	prices := h.GetPrices(assetId)
+	s := make(Series, len(prices))
+	for i, p := range prices {
+		v, _ := p.UnitPrice.Float64()
+		s[i] = Point{Timestamp: p.Timestamp, Value: v}
+	}
+
+	return s
+}
+
+// heldAssets returns the IDs of the assets of the transaction history that were held at some point and have historical prices, in the
+// order of the transaction history. Assets that were never held, e.g. the currencies of deposits and interest, and assets without
+// historical prices are skipped.
+func heldAssets(txCtx *transaction.Context, h *asset.PriceHistory) []string {
+	held := map[string]bool{}
+	for _, t := range txCtx.GetTransactions() {
+		if t.QuantityChange().Sign() != 0 {
+			held[t.Asset.Id] = true
+		}
+	}
+
+	var ids []string
+	for _, a := range txCtx.GetAssets() {
+		if held[a.Id] && len(h.GetPrices(a.Id)) > 0 {
+			ids = append(ids, a.Id)
+		}
+	}
+
+	return ids
+}
+
+// Valuation calculates the value of the holdings of the transaction history at every historical price from the first transaction on.
+// Holdings are valued at their latest known price, which is either a historical price or the unit price of the latest purchase or sale.
+// Purchases, fees and taxes are cash flows into the portfolio, sales, dividends and interest are cash flows out of it. Transactions after
+// the last historical price are ignored.
+func Valuation(txCtx *transaction.Context, h *asset.PriceHistory) (Series, error) {
+	transactions := txCtx.GetTransactions()
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("no transactions to value")
+	}
+
+	slices.SortStableFunc(transactions, func(a, b *transaction.Tx) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	quantities := map[string]*big.Rat{}
+	marks := map[string]asset.PricePoint{}
+	flow := big.NewRat(0, 1)
+	next := 0
+
+	var s Series
+	for _, t := range valuationTimeline(h, transactions) {
+		for ; next < len(transactions) && !transactions[next].Timestamp.After(t); next++ {
+			applyValuationTx(transactions[next], quantities, marks, flow)
+		}
+
+		value, err := holdingsValue(h, t, quantities, marks)
+		if err != nil {
+			return nil, err
+		}
+
+		v, _ := value.Float64()
+		f, _ := flow.Float64()
+		s = append(s, Point{Timestamp: t, Value: v, Flow: f})
+		flow.SetInt64(0)
+	}
+
+	return s, nil
+}
+
+// valuationTimeline collects the distinct timestamps of the historical prices of the traded assets from the first transaction on.
+func valuationTimeline(h *asset.PriceHistory, transactions []*transaction.Tx) []time.Time {
+	first := transactions[0].Timestamp
+	seen := map[string]bool{}
+
+	var timeline []time.Time
+	for _, tx := range transactions {
+		if seen[tx.Asset.Id] {
+			continue
+		}
+		seen[tx.Asset.Id] = true
+
+		for _, p := range h.GetPrices(tx.Asset.Id) {
+			if !p.Timestamp.Before(first) {
+				timeline = append(timeline, p.Timestamp)
+			}
+		}
+	}
+
+	slices.SortFunc(timeline, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+
+	return slices.CompactFunc(timeline, func(a, b time.Time) bool {
+		return a.Equal(b)
+	})
+}
+
+// applyValuationTx updates the held quantities, the latest transaction prices and the cash flow with the transaction.
+func applyValuationTx(tx *transaction.Tx, quantities map[string]*big.Rat, marks map[string]asset.PricePoint, flow *big.Rat) {
+	id := tx.Asset.Id
+	if quantities[id] == nil {
+		quantities[id] = big.NewRat(0, 1)
+	}
+
+	switch tx.Type {
+	case transaction.BUY:
+		quantities[id].Add(quantities[id], tx.Quantity)
+		flow.Add(flow, big.NewRat(0, 1).Mul(tx.Quantity, tx.UnitPrice))
+		marks[id] = asset.PricePoint{Timestamp: tx.Timestamp, UnitPrice: tx.UnitPrice}
+	case transaction.REINVEST:
+		/* The dividend is paid back into the asset, so the holdings grow without any cash flow. */
+		quantities[id].Add(quantities[id], tx.Quantity)
+		marks[id] = asset.PricePoint{Timestamp: tx.Timestamp, UnitPrice: tx.UnitPrice}
+	case transaction.SELL:
+		quantities[id].Sub(quantities[id], tx.Quantity)
+		flow.Sub(flow, big.NewRat(0, 1).Mul(tx.Quantity, tx.UnitPrice))
+		marks[id] = asset.PricePoint{Timestamp: tx.Timestamp, UnitPrice: tx.UnitPrice}
+	case transaction.SPLIT:
+		quantities[id].Add(quantities[id], tx.Quantity)
+		/* The price of the last trade refers to the units before the split, so it cannot be used anymore. */
+		delete(marks, id)
+	case transaction.DIVIDEND, transaction.INTEREST:
+		flow.Sub(flow, tx.UnitPrice)
+	case transaction.FEE, transaction.TAX:
+		flow.Add(flow, tx.UnitPrice)
+	default:
+		// not relevant
+	}
+}
+
+// holdingsValue calculates the value of the held quantities at the given time.
+func holdingsValue(h *asset.PriceHistory, t time.Time, quantities map[string]*big.Rat, marks map[string]asset.PricePoint) (*big.Rat, error) {
+	value := big.NewRat(0, 1)
+	for id, q := range quantities {
+		if q.Sign() == 0 {
+			continue
+		}
+
+		price, ok := h.PriceAt(id, t)
+		if mark, found := marks[id]; found && (!ok || mark.Timestamp.After(price.Timestamp)) {
+			price, ok = mark, true
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("missing price of %s at %s", id, t)
+		}
+
+		value.Add(value, big.NewRat(0, 1).Mul(q, price.UnitPrice))
+	}
+
+	return value, nil
+}
+
+// Align keeps the points of the series at the timestamps they have in common, so returns calculated from them refer to the same periods
+// even if the series follow different trading calendars. The cash flows of dropped points are added to the next point kept.
+func Align(series ...Series) []Series {
+	counts := map[int64]int{}
+	for _, s := range series {
+		for _, p := range s {
+			counts[p.Timestamp.UnixNano()]++
+		}
+	}
+
+	aligned := make([]Series, len(series))
+	for i, s := range series {
+		flow := 0.0
+		for _, p := range s {
+			flow += p.Flow
+			if counts[p.Timestamp.UnixNano()] != len(series) {
+				continue
+			}
+
+			p.Flow = flow
+			aligned[i] = append(aligned[i], p)
+			flow = 0
+		}
+	}
+
+	return aligned
+}
@@ -0,0 +1,138 @@
+package risk_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/risk"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// day creates a UTC timestamp at midnight of the given day of January 2024.
+func day(d int) time.Time {
+	return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+// history creates a price history of the assets with a price on every day from the 1st of January 2024 on.
+func history(t *testing.T, prices map[string][]int64) *asset.PriceHistory {
+	h := &asset.PriceHistory{}
+	for id, series := range prices {
+		for i, p := range series {
+			assert.NoError(t, h.AddPrice(id, asset.PricePoint{Timestamp: day(i + 1), UnitPrice: big.NewRat(p, 1)}))
+		}
+	}
+
+	return h
+}
+
+// newTx creates a transaction of the asset at the given day.
+func newTx(id string, txType transaction.TxType, d int, quantity int64, unitPrice int64) transaction.Tx {
+	return transaction.Tx{
+		Position: transaction.Position{
+			Asset:     &transaction.TxAsset{Id: id},
+			Timestamp: day(d),
+			Quantity:  big.NewRat(quantity, 1),
+			UnitPrice: big.NewRat(unitPrice, 1),
+		},
+		Type: txType,
+	}
+}
+
+// TestValuation checks the values and cash flows of a portfolio with purchases, a dividend and a sale.
+func TestValuation(t *testing.T) {
+	t.Parallel()
+
+	h := history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}, "B": {50, 50, 50, 50, 50}})
+	ctx := transaction.Context{}
+	assert.NoError(t, ctx.AddTransactions([]transaction.Tx{
+		newTx("A", transaction.BUY, 1, 1, 100),
+		newTx("A", transaction.INTEREST, 2, 1, 2),
+		newTx("B", transaction.BUY, 3, 2, 50),
+		newTx("B", transaction.DIVIDEND, 4, 1, 5),
+		newTx("A", transaction.SELL, 5, 1, 110),
+	}))
+
+	s, err := risk.Valuation(&ctx, h)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{100, 110, 199, 221, 100}, s.Values())
+	assert.Equal(t, []float64{100, -2, 100, -5, -110}, []float64{s[0].Flow, s[1].Flow, s[2].Flow, s[3].Flow, s[4].Flow})
+
+	returns := s.Returns().Values()
+	assert.Len(t, returns, 4)
+	assert.InDelta(t, 112.0/100-1, returns[0], 1e-12, "the interest paid out should count as return")
+	assert.InDelta(t, -0.1, returns[1], 1e-12)
+	assert.InDelta(t, 226.0/199-1, returns[2], 1e-12)
+	assert.InDelta(t, 210.0/221-1, returns[3], 1e-12)
+}
+
+// TestValuation_Interest checks that interest is a cash flow out of the portfolio, so it counts as return, while the deposited cash, which
+// has no historical prices, is not valued.
+func TestValuation_Interest(t *testing.T) {
+	t.Parallel()
+
+	h := history(t, map[string][]int64{"A": {100, 100, 100}})
+	ctx := transaction.Context{}
+	assert.NoError(t, ctx.AddTransactions([]transaction.Tx{
+		newTx("EUR", transaction.DEPOSIT, 1, 1, 1000),
+		newTx("A", transaction.BUY, 1, 1, 100),
+		newTx("EUR", transaction.INTEREST, 2, 1, 1),
+		newTx("EUR", transaction.INTEREST, 3, 1, 2),
+	}))
+
+	s, err := risk.Valuation(&ctx, h)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{100, 100, 100}, s.Values())
+	assert.Equal(t, []float64{100, -1, -2}, []float64{s[0].Flow, s[1].Flow, s[2].Flow})
+
+	returns := s.Returns().Values()
+	assert.InDelta(t, 0.01, returns[0], 1e-12)
+	assert.InDelta(t, 0.02, returns[1], 1e-12)
+}
+
+// TestValuation_TradePrice checks that a holding without historical prices is valued at the price of its latest trade.
+func TestValuation_TradePrice(t *testing.T) {
+	t.Parallel()
+
+	h := history(t, map[string][]int64{"A": {100, 110}})
+	ctx := transaction.Context{}
+	assert.NoError(t, ctx.AddTransactions([]transaction.Tx{
+		newTx("A", transaction.BUY, 1, 1, 100),
+		newTx("C", transaction.BUY, 1, 1, 40),
+	}))
+
+	s, err := risk.Valuation(&ctx, h)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{140, 150}, s.Values())
+}
+
+// TestValuation_Empty checks that an empty transaction history cannot be valued.
+func TestValuation_Empty(t *testing.T) {
+	t.Parallel()
+
+	_, err := risk.Valuation(&transaction.Context{}, &asset.PriceHistory{})
+	assert.Error(t, err)
+}
+
+// TestSeries_Between checks filtering a series by time.
+func TestSeries_Between(t *testing.T) {
+	t.Parallel()
+
+	s := risk.PriceSeries(history(t, map[string][]int64{"A": {1, 2, 3, 4}}), "A")
+	assert.Equal(t, []float64{2, 3}, s.Between(day(2), day(3)).Values())
+	assert.Equal(t, []float64{1, 2}, s.Between(time.Time{}, day(2)).Values())
+}
+
+// TestAlign checks that the cash flows of dropped points are carried over to the next common point.
+func TestAlign(t *testing.T) {
+	t.Parallel()
+
+	a := risk.Series{{Timestamp: day(1), Value: 1}, {Timestamp: day(2), Value: 2, Flow: 1}, {Timestamp: day(3), Value: 3, Flow: 1}}
+	b := risk.Series{{Timestamp: day(1), Value: 1}, {Timestamp: day(3), Value: 3}, {Timestamp: day(4), Value: 4}}
+
+	aligned := risk.Align(a, b)
+	assert.Equal(t, []float64{1, 3}, aligned[0].Values())
+	assert.Equal(t, 2.0, aligned[0][1].Flow)
+	assert.Equal(t, []float64{1, 3}, aligned[1].Values())
+}
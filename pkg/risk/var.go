@@ -0,0 +1,220 @@
+package risk
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/mathutils"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// VaRMethod holds the different methods of calculating the Value at Risk as a pseudo-enum.
+type VaRMethod = int
+
+const (
+	// HISTORICAL replays the historical returns of the assets on the current holdings.
+	HISTORICAL VaRMethod = iota
+	// PARAMETRIC assumes normally distributed returns with zero mean and the historical covariances of the assets (variance-covariance
+	// method).
+	PARAMETRIC
+)
+
+// VaRConfig configures the calculation of the Value at Risk.
+type VaRConfig struct {
+	Method VaRMethod
+	// Confidences lists the confidence levels to calculate the Value at Risk at, e.g. 0.95 and 0.99.
+	Confidences []float64
+	// Horizon is the number of periods of the price history the loss is estimated for, e.g. 1 or 10 for daily prices. One-period
+	// figures are scaled with the square root of the horizon. Zero defaults to one.
+	Horizon int
+	// Start and End limit the window of historical prices. A zero value leaves the window open at that side.
+	Start time.Time
+	End   time.Time
+}
+
+// VaRResult holds the Value at Risk of the portfolio at a confidence level. Losses are positive amounts in the currency of the asset
+// prices.
+type VaRResult struct {
+	Confidence float64
+	Horizon    int
+	// Worth is the current worth of the holdings.
+	Worth float64
+	// VaR is the loss which is not exceeded with the probability of the confidence level.
+	VaR float64
+	// ExpectedShortfall is the expected loss if the VaR is exceeded, also known as CVaR.
+	ExpectedShortfall float64
+	// Marginal holds the change of the VaR per unit of currency added to the holding of an asset.
+	Marginal map[*asset.Asset]float64
+	// Component holds the contribution of every asset to the VaR. The contributions add up to the VaR.
+	Component map[*asset.Asset]float64
+}
+
+// ValueAtRisk calculates the Value at Risk and the Expected Shortfall of the current holdings, weighted by GetAssetWorthMap, at every
+// configured confidence level based on the historical prices of the assets.
+func ValueAtRisk(ctx *calculation.Context, h *asset.PriceHistory, config VaRConfig) ([]VaRResult, error) {
+	if err := validateVaRConfig(config); err != nil {
+		return nil, err
+	}
+
+	worthMap, err := ctx.GetAssetWorthMap()
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []*asset.Asset
+	for a, w := range worthMap {
+		if w.Sign() != 0 {
+			assets = append(assets, a)
+		}
+	}
+
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("no holdings to calculate the Value at Risk of")
+	}
+
+	slices.SortFunc(assets, func(a, b *asset.Asset) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+
+	ids := make([]string, len(assets))
+	worth := make([]float64, len(assets))
+	total := 0.0
+	for i, a := range assets {
+		ids[i] = a.Id
+		worth[i], _ = worthMap[a].Float64()
+		total += worth[i]
+	}
+
+	returns, err := alignedReturns(h, ids, config.Start, config.End)
+	if err != nil {
+		return nil, err
+	}
+
+	horizon := max(config.Horizon, 1)
+	scale := math.Sqrt(float64(horizon))
+
+	results := make([]VaRResult, 0, len(config.Confidences))
+	for _, c := range config.Confidences {
+		var v, es float64
+		var components []float64
+		if config.Method == PARAMETRIC {
+			v, es, components = parametricVaR(worth, returns, c)
+		} else {
+			v, es, components = historicalVaR(worth, returns, c)
+		}
+
+		r := VaRResult{
+			Confidence:        c,
+			Horizon:           horizon,
+			Worth:             total,
+			VaR:               v * scale,
+			ExpectedShortfall: es * scale,
+			Marginal:          map[*asset.Asset]float64{},
+			Component:         map[*asset.Asset]float64{},
+		}
+
+		for i, a := range assets {
+			r.Component[a] = components[i] * scale
+			r.Marginal[a] = r.Component[a] / worth[i]
+		}
+
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// validateVaRConfig checks the method, the confidence levels and the horizon of the configuration.
+func validateVaRConfig(config VaRConfig) error {
+	if config.Method != HISTORICAL && config.Method != PARAMETRIC {
+		return fmt.Errorf("unsupported VaR method %d", config.Method)
+	}
+
+	if len(config.Confidences) == 0 {
+		return fmt.Errorf("no confidence levels given")
+	}
+
+	for _, c := range config.Confidences {
+		if c <= 0 || c >= 1 {
+			return fmt.Errorf("confidence level %v should be between zero and one", c)
+		}
+	}
+
+	if config.Horizon < 0 {
+		return fmt.Errorf("horizon shouldn't be negative")
+	}
+
+	return nil
+}
+
+// historicalVaR calculates the one-period VaR, Expected Shortfall and component VaR of the holdings from the historical returns. The VaR is
+// interpolated between the closest ranks of the simulated losses; the components are interpolated the same way between the losses of the
+// assets in the same scenarios, so they add up to the VaR.
+func historicalVaR(worth []float64, returns [][]float64, confidence float64) (float64, float64, []float64) {
+	n := len(returns[0])
+	losses := make([]float64, n)
+	for s := range n {
+		for i := range worth {
+			losses[s] -= worth[i] * returns[i][s]
+		}
+	}
+
+	scenarios := make([]int, n)
+	for s := range scenarios {
+		scenarios[s] = s
+	}
+	slices.SortStableFunc(scenarios, func(a, b int) int {
+		return cmp.Compare(losses[a], losses[b])
+	})
+
+	rank := confidence * float64(n-1)
+	lower, upper := scenarios[int(math.Floor(rank))], scenarios[int(math.Ceil(rank))]
+	fraction := rank - math.Floor(rank)
+
+	components := make([]float64, len(worth))
+	for i := range worth {
+		l, u := -worth[i]*returns[i][lower], -worth[i]*returns[i][upper]
+		components[i] = l + fraction*(u-l)
+	}
+	v := losses[lower] + fraction*(losses[upper]-losses[lower])
+
+	var tail []float64
+	for _, l := range losses {
+		if l >= v {
+			tail = append(tail, l)
+		}
+	}
+
+	return v, mathutils.Mean(tail), components
+}
+
+// parametricVaR calculates the one-period VaR, Expected Shortfall and component VaR of the holdings assuming normally distributed returns
+// with zero mean and the covariances of the historical returns.
+func parametricVaR(worth []float64, returns [][]float64, confidence float64) (float64, float64, []float64) {
+	/* Σx, the covariance matrix multiplied by the worth vector */
+	exposure := make([]float64, len(worth))
+	variance := 0.0
+	for i := range worth {
+		for j := range worth {
+			exposure[i] += mathutils.Covariance(returns[i], returns[j]) * worth[j]
+		}
+		variance += worth[i] * exposure[i]
+	}
+
+	sigma := math.Sqrt(variance)
+	z := math.Sqrt2 * math.Erfinv(2*confidence-1)
+	density := math.Exp(-z*z/2) / math.Sqrt(2*math.Pi)
+
+	components := make([]float64, len(worth))
+	for i := range worth {
+		if sigma != 0 {
+			components[i] = z * worth[i] * exposure[i] / sigma
+		}
+	}
+
+	return z * sigma, sigma * density / (1 - confidence), components
+}
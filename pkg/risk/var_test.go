@@ -0,0 +1,112 @@
+package risk_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/calculation"
+	"github.com/wlachs/wstonks/pkg/risk"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math"
+	"math/big"
+	"testing"
+)
+
+// varContext creates a portfolio holding one unit of A worth 100 and two units of B worth 50 each.
+func varContext(t *testing.T) (*calculation.Context, *asset.Asset, *asset.Asset) {
+	txCtx := transaction.Context{}
+	assert.NoError(t, txCtx.AddTransactions([]transaction.Tx{
+		newTx("A", transaction.BUY, 1, 1, 100),
+		newTx("B", transaction.BUY, 1, 2, 50),
+	}))
+
+	assetCtx := asset.Context{}
+	a := &asset.Asset{Id: "A", UnitPrice: big.NewRat(100, 1)}
+	b := &asset.Asset{Id: "B", UnitPrice: big.NewRat(50, 1)}
+	c := &asset.Asset{Id: "C", UnitPrice: big.NewRat(10, 1)}
+	assert.NoError(t, assetCtx.AddAssets([]*asset.Asset{a, b, c}))
+
+	return &calculation.Context{AssetContext: &assetCtx, TransactionContext: &txCtx}, a, b
+}
+
+// TestValueAtRisk_Historical checks the historical VaR and Expected Shortfall of a single holding and their scaling to ten periods.
+func TestValueAtRisk_Historical(t *testing.T) {
+	t.Parallel()
+
+	ctx, a, _ := varContext(t)
+	h := history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}, "B": {50, 50, 50, 50, 50}})
+
+	results, err := risk.ValueAtRisk(ctx, h, risk.VaRConfig{Confidences: []float64{0.99, 0.5}})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	r := results[0]
+	assert.Equal(t, 0.99, r.Confidence)
+	assert.Equal(t, 1, r.Horizon)
+	assert.Equal(t, 200.0, r.Worth)
+	assert.InDelta(t, 9.0909+0.97*(10-9.0909), r.VaR, 1e-4)
+	assert.InDelta(t, 10, r.ExpectedShortfall, 1e-9)
+	assert.InDelta(t, r.VaR, r.Component[a], 1e-9)
+	assert.InDelta(t, r.VaR/100, r.Marginal[a], 1e-9)
+	assert.Less(t, results[1].VaR, r.VaR)
+
+	ten, err := risk.ValueAtRisk(ctx, h, risk.VaRConfig{Confidences: []float64{0.99}, Horizon: 10})
+	assert.NoError(t, err)
+	assert.InDelta(t, r.VaR*math.Sqrt(10), ten[0].VaR, 1e-9)
+	assert.InDelta(t, r.ExpectedShortfall*math.Sqrt(10), ten[0].ExpectedShortfall, 1e-9)
+}
+
+// TestValueAtRisk_Parametric checks the parametric VaR and Expected Shortfall of a single holding.
+func TestValueAtRisk_Parametric(t *testing.T) {
+	t.Parallel()
+
+	ctx, a, b := varContext(t)
+	h := history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}, "B": {50, 50, 50, 50, 50}})
+
+	results, err := risk.ValueAtRisk(ctx, h, risk.VaRConfig{Method: risk.PARAMETRIC, Confidences: []float64{0.95}})
+	assert.NoError(t, err)
+
+	r := results[0]
+	assert.InDelta(t, 1.644854*15.635045, r.VaR, 1e-4)
+	assert.InDelta(t, 15.635045*0.103136/0.05, r.ExpectedShortfall, 1e-3)
+	assert.InDelta(t, r.VaR, r.Component[a], 1e-9)
+	assert.Equal(t, 0.0, r.Component[b])
+}
+
+// TestValueAtRisk_Components checks that the component VaR of several holdings adds up to the VaR with both methods.
+func TestValueAtRisk_Components(t *testing.T) {
+	t.Parallel()
+
+	ctx, a, b := varContext(t)
+	h := history(t, map[string][]int64{"A": {100, 110, 99, 121, 110, 105, 115}, "B": {50, 48, 53, 51, 47, 49, 50}})
+
+	for _, method := range []risk.VaRMethod{risk.HISTORICAL, risk.PARAMETRIC} {
+		results, err := risk.ValueAtRisk(ctx, h, risk.VaRConfig{Method: method, Confidences: []float64{0.9}, Horizon: 10})
+		assert.NoError(t, err)
+
+		r := results[0]
+		assert.Len(t, r.Component, 2)
+		assert.InDelta(t, r.VaR, r.Component[a]+r.Component[b], 1e-9)
+		assert.InDelta(t, r.Component[b], r.Marginal[b]*100, 1e-9)
+		assert.GreaterOrEqual(t, r.ExpectedShortfall, r.VaR)
+	}
+}
+
+// TestValueAtRisk_Invalid checks that invalid configurations and missing prices are rejected.
+func TestValueAtRisk_Invalid(t *testing.T) {
+	t.Parallel()
+
+	ctx, _, _ := varContext(t)
+	h := history(t, map[string][]int64{"A": {100, 110, 99, 121, 110}})
+
+	_, err := risk.ValueAtRisk(ctx, h, risk.VaRConfig{})
+	assert.Error(t, err)
+
+	_, err = risk.ValueAtRisk(ctx, h, risk.VaRConfig{Confidences: []float64{1}})
+	assert.Error(t, err)
+
+	_, err = risk.ValueAtRisk(ctx, h, risk.VaRConfig{Method: 42, Confidences: []float64{0.99}})
+	assert.Error(t, err)
+
+	_, err = risk.ValueAtRisk(ctx, h, risk.VaRConfig{Confidences: []float64{0.99}})
+	assert.Error(t, err, "B has no price history")
+}
@@ -0,0 +1,88 @@
+package transaction_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestContext_Concurrent_Reads_And_Writes adds transactions out of order while other goroutines read positions, quantities, initial worth
+// and realized profits. Run with -race to detect unsynchronized access.
+func TestContext_Concurrent_Reads_And_Writes(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	assert.Nil(t, ctx.AddTransactions(syntheticTransactions(100, 10)))
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for _, tx := range syntheticTransactions(50, 10) {
+				tx.Timestamp = tx.Timestamp.Add(-time.Duration(w+1) * time.Second)
+				assert.Nil(t, ctx.AddTransaction(tx))
+			}
+		}(w)
+	}
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				_ = ctx.GetAssetMap()
+				_ = ctx.GetAssetPositionSliceMap()
+				_ = ctx.GetAssetKeyInitialWorthMap()
+				_ = ctx.GetRealizedProfit()
+				_, _ = ctx.GetAssetKeyPositions("ASSET-1")
+				for _, a := range ctx.GetAssets() {
+					_ = ctx.GetAssetPositions(a)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 300, len(ctx.GetTransactions()))
+	assert.Equal(t, big.NewRat(30, 1), ctx.GetAssetKeyMap()["ASSET-1"])
+}
+
+// TestContext_Concurrent_Reads_After_Import imports transactions out of order and reads positions and quantities from several
+// goroutines afterwards. Reads must not reorder the transactions of an asset. Run with -race to detect unsynchronized access.
+func TestContext_Concurrent_Reads_After_Import(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	importer := ctx.NewImporter()
+	txs := syntheticTransactions(200, 4)
+	for i := len(txs) - 1; i >= 0; i-- {
+		assert.Nil(t, importer.Add(txs[i]))
+	}
+
+	var wg sync.WaitGroup
+	for r := 0; r < 8; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			for _, a := range ctx.GetAssets() {
+				if r%2 == 0 {
+					_ = ctx.GetAssetPositions(a)
+				} else {
+					_ = ctx.GetAssetQuantityAt(a, time.UnixMilli(100))
+				}
+			}
+		}(r)
+	}
+
+	wg.Wait()
+
+	assert.Nil(t, importer.Finish())
+	a, _ := ctx.GetAsset("ASSET-1")
+	assert.Equal(t, big.NewRat(25, 1), ctx.GetAssetQuantityAt(a, time.UnixMilli(100)))
+	assert.Equal(t, time.UnixMilli(1), a.Transactions[0].Timestamp)
+}
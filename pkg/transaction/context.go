@@ -2,41 +2,121 @@ package transaction
 
 import (
 	"fmt"
+	"github.com/wlachs/wstonks/pkg/internal/index"
 	"log"
 	"slices"
+	"sort"
+	"sync"
 )
 
 // Context holding historical trade and asset data.
+// The methods of the Context are safe for concurrent use. The exported slices are not guarded, so code sharing a Context between
+// goroutines should read them through GetTransactions and GetAssets instead of accessing them directly.
 type Context struct {
 	Transactions []*Tx
 	Assets       []*TxAsset
+	// mu guards the transactions and assets of the Context.
+	mu sync.RWMutex
+	// cacheMu guards ledgers, which are also updated by readers.
+	cacheMu sync.Mutex
+	// index maps asset IDs to their position in Assets.
+	index index.Index[*TxAsset]
+	// ledgers caches the open positions, quantities and realized profits and losses of the assets.
+	ledgers map[*TxAsset]*ledger
 }
 
 // AddTransactions adds a slice of Tx objects to the Context.
 // The TxAsset data is dynamically filled based on the other transactions in the Context.
 func (ctx *Context) AddTransactions(transactions []Tx) error {
-	var err error
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	importer := ctx.NewImporter()
 	for _, t := range transactions {
-		e := ctx.addTransactionInternal(t, false)
-		if e != nil {
-			log.Printf("failed to add transaction %v: %v\n", t, e)
-			err = e
-		}
+		_ = importer.add(t)
 	}
 
-	if err != nil {
-		return err
+	if importer.err != nil {
+		return importer.err
 	}
 
-	return ctx.Validate()
+	return ctx.validate()
 }
 
 // AddTransaction adds a Tx to the Context.
 // The TxAsset is automatically created the first time it is seen in a model.Tx.
 func (ctx *Context) AddTransaction(transaction Tx) error {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
 	return ctx.addTransactionInternal(transaction, true)
 }
 
+// GetTransactions returns a copy of the transaction history of the Context.
+func (ctx *Context) GetTransactions() []*Tx {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return append([]*Tx(nil), ctx.Transactions...)
+}
+
+// GetAssets returns a copy of the assets of the Context.
+func (ctx *Context) GetAssets() []*TxAsset {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return append([]*TxAsset(nil), ctx.Assets...)
+}
+
+// Importer adds transactions to a Context one at a time, without holding them in memory first and without validating the Context after
+// every transaction. It is meant for importing large transaction histories incrementally; the Context is validated once by Finish.
+type Importer struct {
+	ctx   *Context
+	count int
+	err   error
+}
+
+// NewImporter creates an Importer adding transactions to the Context.
+func (ctx *Context) NewImporter() *Importer {
+	return &Importer{ctx: ctx}
+}
+
+// Add adds a Tx to the Context. Like AddTransactions, a transaction that cannot be added is logged and skipped, and the error is
+// reported again by Finish.
+func (i *Importer) Add(transaction Tx) error {
+	i.ctx.mu.Lock()
+	defer i.ctx.mu.Unlock()
+
+	return i.add(transaction)
+}
+
+// add adds a Tx to the Context without locking it.
+func (i *Importer) add(transaction Tx) error {
+	err := i.ctx.addTransactionInternal(transaction, false)
+	if err != nil {
+		log.Printf("failed to add transaction %v: %v\n", transaction, err)
+		i.err = err
+		return err
+	}
+
+	i.count++
+	return nil
+}
+
+// Count returns the number of transactions added by the Importer so far.
+func (i *Importer) Count() int {
+	return i.count
+}
+
+// Finish returns the last error encountered while adding transactions. If there was none, the Context is validated.
+func (i *Importer) Finish() error {
+	if i.err != nil {
+		return i.err
+	}
+
+	return i.ctx.Validate()
+}
+
 // addTransactionInternal adds the transaction to the Context.
 // If the validate parameter is true, the Context will be validated after adding the transaction.
 func (ctx *Context) addTransactionInternal(transaction Tx, validate bool) error {
@@ -51,7 +131,7 @@ func (ctx *Context) addTransactionInternal(transaction Tx, validate bool) error
 	}
 
 	if validate {
-		return ctx.Validate()
+		return ctx.validate()
 	}
 
 	return nil
@@ -64,13 +144,9 @@ func updateAssets(ctx *Context, transaction *Tx) error {
 		return fmt.Errorf("missing asset for transaction %v", transaction)
 	}
 
-	i := slices.IndexFunc(ctx.Assets, func(a *TxAsset) bool {
-		return a.Id == asset.Id
-	})
-
 	// If the asset is not yet known, add it
-	if i == -1 {
-		ctx.Assets = append(ctx.Assets, asset)
+	if ctx.assetIndex(asset.Id) == -1 {
+		ctx.indexAsset(asset)
 	}
 
 	return nil
@@ -83,14 +159,20 @@ func updateTransactions(ctx *Context, transaction *Tx) error {
 	}
 
 	// update transaction asset pointer
-	i := slices.IndexFunc(ctx.Assets, func(a *TxAsset) bool {
-		return a.Id == transaction.Asset.Id
-	})
+	i := ctx.assetIndex(transaction.Asset.Id)
+	if i == -1 {
+		return fmt.Errorf("unknown asset for transaction %v", transaction)
+	}
 	transaction.Asset = ctx.Assets[i]
 
-	// add transaction to context transactions
-	j := len(ctx.Transactions)
+	// add transaction to context transactions and to the asset transactions, which are kept in chronological order, after any
+	// simultaneous transactions
 	ctx.Transactions = append(ctx.Transactions, transaction)
-	ctx.Transactions[j].Asset.Transactions = append(ctx.Transactions[j].Asset.Transactions, ctx.Transactions[j])
+	txs := transaction.Asset.Transactions
+	k := sort.Search(len(txs), func(k int) bool {
+		return txs[k].Timestamp.After(transaction.Timestamp)
+	})
+	transaction.Asset.Transactions = slices.Insert(txs, k, transaction)
+	ctx.updateLedger(transaction)
 	return nil
 }
@@ -0,0 +1,124 @@
+package transaction
+
+import (
+	"math/big"
+	"slices"
+	"time"
+)
+
+// YearMonth identifies a calendar month.
+type YearMonth struct {
+	Year  int
+	Month time.Month
+}
+
+// DividendPayment is a single dividend paid for an asset.
+type DividendPayment struct {
+	Asset     *TxAsset
+	Timestamp time.Time
+	Amount    *big.Rat
+	// Currency is the ISO 4217 code of the currency the dividend was paid in.
+	Currency string
+}
+
+// dividendAmount returns the amount of the dividend paid by the transaction, including reinvested dividends. The second return value is
+// false if the transaction is not a dividend.
+func dividendAmount(t *Tx) (*big.Rat, bool) {
+	switch t.Type {
+	case DIVIDEND:
+		return t.UnitPrice, true
+	case REINVEST:
+		return big.NewRat(0, 1).Mul(t.Quantity, t.UnitPrice), true
+	default:
+		return nil, false
+	}
+}
+
+// GetDividendCalendar lists the dividend payments between from and to in chronological order. A zero from or to leaves the calendar open
+// at that side.
+func (ctx *Context) GetDividendCalendar(from time.Time, to time.Time) []DividendPayment {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	var calendar []DividendPayment
+	for _, t := range ctx.Transactions {
+		amount, ok := dividendAmount(t)
+		if !ok || (!from.IsZero() && t.Timestamp.Before(from)) || (!to.IsZero() && t.Timestamp.After(to)) {
+			continue
+		}
+
+		calendar = append(calendar, DividendPayment{
+			Asset:     t.Asset,
+			Timestamp: t.Timestamp,
+			Amount:    big.NewRat(0, 1).Set(amount),
+			Currency:  t.Currency,
+		})
+	}
+
+	slices.SortStableFunc(calendar, func(a, b DividendPayment) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	return calendar
+}
+
+// GetDividendIncome sums up the dividends paid for the asset between from and to. A zero from or to leaves the period open at that side.
+func (ctx *Context) GetDividendIncome(a *TxAsset, from time.Time, to time.Time) *big.Rat {
+	income := big.NewRat(0, 1)
+	for _, p := range ctx.GetDividendCalendar(from, to) {
+		if p.Asset == a {
+			income.Add(income, p.Amount)
+		}
+	}
+
+	return income
+}
+
+// GetAssetDividendsByMonth sums up the dividends paid for every asset per calendar month. Assets without dividends are not listed.
+func (ctx *Context) GetAssetDividendsByMonth() map[*TxAsset]map[YearMonth]*big.Rat {
+	m := map[*TxAsset]map[YearMonth]*big.Rat{}
+	for _, p := range ctx.GetDividendCalendar(time.Time{}, time.Time{}) {
+		if m[p.Asset] == nil {
+			m[p.Asset] = map[YearMonth]*big.Rat{}
+		}
+
+		key := YearMonth{Year: p.Timestamp.Year(), Month: p.Timestamp.Month()}
+		if m[p.Asset][key] == nil {
+			m[p.Asset][key] = big.NewRat(0, 1)
+		}
+		m[p.Asset][key].Add(m[p.Asset][key], p.Amount)
+	}
+
+	return m
+}
+
+// GetAssetDividendsByYear sums up the dividends paid for every asset per calendar year. Assets without dividends are not listed.
+func (ctx *Context) GetAssetDividendsByYear() map[*TxAsset]map[int]*big.Rat {
+	m := map[*TxAsset]map[int]*big.Rat{}
+	for a, months := range ctx.GetAssetDividendsByMonth() {
+		m[a] = map[int]*big.Rat{}
+		for month, income := range months {
+			if m[a][month.Year] == nil {
+				m[a][month.Year] = big.NewRat(0, 1)
+			}
+			m[a][month.Year].Add(m[a][month.Year], income)
+		}
+	}
+
+	return m
+}
+
+// GetDividendsByMonth sums up the dividends paid for all assets per calendar month.
+func (ctx *Context) GetDividendsByMonth() map[YearMonth]*big.Rat {
+	m := map[YearMonth]*big.Rat{}
+	for _, months := range ctx.GetAssetDividendsByMonth() {
+		for month, income := range months {
+			if m[month] == nil {
+				m[month] = big.NewRat(0, 1)
+			}
+			m[month].Add(m[month], income)
+		}
+	}
+
+	return m
+}
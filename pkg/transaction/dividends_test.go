@@ -0,0 +1,89 @@
+package transaction_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// dividendContext creates a Context holding asset "A" with dividends paid in 2023 and 2024 and asset "B" with a single dividend.
+func dividendContext(t *testing.T) *transaction.Context {
+	tx := func(id string, txType transaction.TxType, ts time.Time, quantity int64, unitPrice int64) transaction.Tx {
+		return transaction.Tx{
+			Position: transaction.Position{
+				Asset:     &transaction.TxAsset{Id: id},
+				Timestamp: ts,
+				Quantity:  big.NewRat(quantity, 1),
+				UnitPrice: big.NewRat(unitPrice, 1),
+			},
+			Type:     txType,
+			Currency: "EUR",
+		}
+	}
+
+	ctx := &transaction.Context{}
+	assert.NoError(t, ctx.AddTransactions([]transaction.Tx{
+		tx("A", transaction.BUY, time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC), 10, 100),
+		tx("A", transaction.DIVIDEND, time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC), 1, 10),
+		tx("A", transaction.DIVIDEND, time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC), 1, 10),
+		tx("B", transaction.BUY, time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), 5, 20),
+		tx("B", transaction.DIVIDEND, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), 1, 3),
+		tx("A", transaction.DIVIDEND, time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC), 1, 12),
+		tx("A", transaction.DIVIDEND, time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC), 1, 1),
+	}))
+
+	return ctx
+}
+
+// TestContext_GetDividendCalendar checks that the dividend payments are listed in chronological order within the period.
+func TestContext_GetDividendCalendar(t *testing.T) {
+	t.Parallel()
+
+	ctx := dividendContext(t)
+	calendar := ctx.GetDividendCalendar(time.Time{}, time.Time{})
+	assert.Len(t, calendar, 5)
+	assert.Equal(t, "A", calendar[0].Asset.Id)
+	assert.Equal(t, "B", calendar[2].Asset.Id)
+	assert.Equal(t, big.NewRat(3, 1), calendar[2].Amount)
+	assert.Equal(t, "EUR", calendar[2].Currency)
+
+	calendar = ctx.GetDividendCalendar(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC))
+	assert.Len(t, calendar, 2)
+
+	a, _ := ctx.GetAsset("A")
+	assert.Equal(t, big.NewRat(33, 1), ctx.GetDividendIncome(a, time.Time{}, time.Time{}))
+}
+
+// TestContext_GetAssetDividendsByMonth checks the dividend income per asset per month and year, and of all assets per month.
+func TestContext_GetAssetDividendsByMonth(t *testing.T) {
+	t.Parallel()
+
+	ctx := dividendContext(t)
+	a, _ := ctx.GetAsset("A")
+	b, _ := ctx.GetAsset("B")
+
+	monthly := ctx.GetAssetDividendsByMonth()
+	assert.Len(t, monthly, 2)
+	assert.Equal(t, big.NewRat(13, 1), monthly[a][transaction.YearMonth{Year: 2024, Month: time.June}])
+	assert.Len(t, monthly[a], 3)
+
+	yearly := ctx.GetAssetDividendsByYear()
+	assert.Equal(t, map[int]*big.Rat{2023: big.NewRat(20, 1), 2024: big.NewRat(13, 1)}, yearly[a])
+	assert.Equal(t, map[int]*big.Rat{2024: big.NewRat(3, 1)}, yearly[b])
+
+	assert.Equal(t, big.NewRat(16, 1), ctx.GetDividendsByMonth()[transaction.YearMonth{Year: 2024, Month: time.June}])
+}
+
+// TestContext_GetAssetQuantityAt checks the quantity held before, at and after a purchase.
+func TestContext_GetAssetQuantityAt(t *testing.T) {
+	t.Parallel()
+
+	ctx := dividendContext(t)
+	a, _ := ctx.GetAsset("A")
+
+	assert.Equal(t, 0, ctx.GetAssetQuantityAt(a, time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC)).Sign())
+	assert.Equal(t, big.NewRat(10, 1), ctx.GetAssetQuantityAt(a, time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, big.NewRat(10, 1), ctx.GetAssetQuantityAt(a, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,29 @@
+package transaction
+
+// GetAsset returns the TxAsset with the given ID. The second return value is false if the Context holds no such asset.
+func (ctx *Context) GetAsset(assetId string) (*TxAsset, bool) {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	i := ctx.assetIndex(assetId)
+	if i == -1 {
+		return nil, false
+	}
+
+	return ctx.Assets[i], true
+}
+
+// assetIndex returns the position of the TxAsset with the given ID in the Assets slice or -1 if the Context holds no such asset.
+func (ctx *Context) assetIndex(assetId string) int {
+	return ctx.index.Lookup(ctx.Assets, txAssetId, assetId)
+}
+
+// indexAsset appends the TxAsset to the Assets slice and records its position in the index.
+func (ctx *Context) indexAsset(asset *TxAsset) {
+	ctx.Assets = ctx.index.Append(ctx.Assets, txAssetId, asset)
+}
+
+// txAssetId returns the ID of the TxAsset.
+func txAssetId(asset *TxAsset) string {
+	return asset.Id
+}
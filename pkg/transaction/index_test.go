@@ -0,0 +1,152 @@
+package transaction_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+)
+
+// syntheticTransactions generates n BUY transactions spread evenly over m assets.
+func syntheticTransactions(n int, m int) []transaction.Tx {
+	txs := make([]transaction.Tx, n)
+	for i := range txs {
+		txs[i] = transaction.Tx{
+			Position: transaction.Position{
+				Asset:     &transaction.TxAsset{Id: fmt.Sprintf("ASSET-%d", i%m)},
+				Timestamp: time.UnixMilli(int64(i)),
+				Quantity:  big.NewRat(1, 1),
+				UnitPrice: big.NewRat(int64(100+i%7), 1),
+			},
+			Type: transaction.BUY,
+		}
+	}
+
+	return txs
+}
+
+// TestContext_GetAsset tests the asset lookup of the Context.
+func TestContext_GetAsset(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	err := ctx.AddTransactions(syntheticTransactions(10, 5))
+	assert.Nil(t, err)
+
+	a, ok := ctx.GetAsset("ASSET-3")
+	assert.True(t, ok)
+	assert.Equal(t, ctx.Assets[3], a)
+	assert.Equal(t, 2, len(a.Transactions))
+
+	_, ok = ctx.GetAsset("ASSET-5")
+	assert.False(t, ok)
+}
+
+// TestContext_GetAsset_Modified_Assets tests that the asset lookup stays consistent if the Assets slice is modified directly.
+func TestContext_GetAsset_Modified_Assets(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	err := ctx.AddTransactions(syntheticTransactions(4, 2))
+	assert.Nil(t, err)
+
+	ctx.Assets = append(ctx.Assets, &transaction.TxAsset{Id: "B"})
+	a, ok := ctx.GetAsset("B")
+	assert.True(t, ok)
+	assert.Equal(t, "B", a.Id)
+
+	ctx.Assets = slices.Delete(ctx.Assets, 0, 1)
+	_, ok = ctx.GetAsset("ASSET-0")
+	assert.False(t, ok)
+
+	a, ok = ctx.GetAsset("ASSET-1")
+	assert.True(t, ok)
+	assert.Equal(t, ctx.Assets[0], a)
+
+	err = ctx.AddTransaction(syntheticTransactions(1, 1)[0])
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(ctx.Assets))
+}
+
+// TestContext_GetAsset_Replaced_Asset tests that the asset lookup notices an entry of the Assets slice replaced directly once the replaced
+// asset is looked up.
+func TestContext_GetAsset_Replaced_Asset(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	err := ctx.AddTransactions(syntheticTransactions(4, 2))
+	assert.Nil(t, err)
+
+	ctx.Assets[0] = &transaction.TxAsset{Id: "B"}
+	_, ok := ctx.GetAsset("ASSET-0")
+	assert.False(t, ok)
+
+	a, ok := ctx.GetAsset("B")
+	assert.True(t, ok)
+	assert.Equal(t, ctx.Assets[0], a)
+
+	err = ctx.AddTransaction(syntheticTransactions(1, 1)[0])
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(ctx.Assets))
+	assert.Equal(t, "ASSET-0", ctx.Assets[2].Id)
+}
+
+// TestContext_GetAsset_Duplicate_Ids tests that neither duplicate IDs in the Assets slice nor misses cause the index to be rebuilt on every
+// lookup.
+// The test does not run in parallel, since testing.AllocsPerRun doesn't allow it.
+func TestContext_GetAsset_Duplicate_Ids(t *testing.T) {
+	ctx := transaction.Context{}
+	err := ctx.AddTransactions(syntheticTransactions(4, 2))
+	assert.Nil(t, err)
+
+	ctx.Assets = append(ctx.Assets, &transaction.TxAsset{Id: "ASSET-1"})
+	a, ok := ctx.GetAsset("ASSET-1")
+	assert.True(t, ok)
+	assert.Equal(t, "ASSET-1", a.Id)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = ctx.GetAsset("ASSET-0")
+		_, _ = ctx.GetAsset("ASSET-1")
+		_, _ = ctx.GetAsset("MISSING")
+	})
+	assert.Zero(t, allocs)
+}
+
+// BenchmarkContext_AddTransactions measures loading transactions into a Context for different numbers of assets.
+func BenchmarkContext_AddTransactions(b *testing.B) {
+	for _, m := range []int{10, 100, 1000, 10000} {
+		txs := syntheticTransactions(100000, m)
+		b.Run(fmt.Sprintf("assets=%d", m), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ctx := transaction.Context{}
+				_ = ctx.AddTransactions(txs)
+			}
+		})
+	}
+}
+
+// BenchmarkContext_GetAsset measures the asset lookup of the Context against a linear scan of the Assets slice.
+func BenchmarkContext_GetAsset(b *testing.B) {
+	for _, m := range []int{10, 100, 1000, 10000} {
+		ctx := transaction.Context{}
+		_ = ctx.AddTransactions(syntheticTransactions(m, m))
+		id := fmt.Sprintf("ASSET-%d", m-1)
+
+		b.Run(fmt.Sprintf("index/assets=%d", m), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = ctx.GetAsset(id)
+			}
+		})
+
+		b.Run(fmt.Sprintf("scan/assets=%d", m), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = slices.IndexFunc(ctx.Assets, func(a *transaction.TxAsset) bool {
+					return a.Id == id
+				})
+			}
+		})
+	}
+}
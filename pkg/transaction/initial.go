@@ -6,10 +6,13 @@ import (
 
 // GetAssetInitialWorthMap calculates the initial worth of every asset
 func (ctx *Context) GetAssetInitialWorthMap() map[*TxAsset]*big.Rat {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
 	m := map[*TxAsset]*big.Rat{}
 
 	for _, a := range ctx.Assets {
-		m[a] = ctx.GetAssetInitialWorth(a)
+		m[a] = big.NewRat(0, 1).Set(ctx.assetLedger(a).worth)
 	}
 
 	return m
@@ -17,16 +20,10 @@ func (ctx *Context) GetAssetInitialWorthMap() map[*TxAsset]*big.Rat {
 
 // GetAssetInitialWorth calculates the initial worth of the given asset
 func (ctx *Context) GetAssetInitialWorth(a *TxAsset) *big.Rat {
-	positions := ctx.GetAssetPositions(a)
-	worth := big.NewRat(0, 1)
-
-	for _, p := range positions {
-		w := big.NewRat(0, 1)
-		w.Mul(p.Quantity, p.UnitPrice)
-		worth.Add(worth, w)
-	}
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
 
-	return worth
+	return big.NewRat(0, 1).Set(ctx.assetLedger(a).worth)
 }
 
 // GetAssetKeyInitialWorthMap calculates the initial worth of every asset
@@ -0,0 +1,181 @@
+package io
+
+import (
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// csvHeader maps the column names of a broker CSV export to their index. Column names are compared case-insensitively.
+type csvHeader map[string]int
+
+// newCsvHeader creates a csvHeader from the header row of a CSV export. If a column name occurs more than once, the first index is kept.
+func newCsvHeader(row []string) csvHeader {
+	h := csvHeader{}
+	for i, name := range row {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if _, ok := h[key]; !ok && key != "" {
+			h[key] = i
+		}
+	}
+
+	return h
+}
+
+// index returns the index of the first column matching one of the given names. If none of the names is found, -1 is returned.
+func (h csvHeader) index(names ...string) int {
+	for _, name := range names {
+		if i, ok := h[strings.ToLower(name)]; ok {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// has checks whether the header contains every given column.
+func (h csvHeader) has(names ...string) bool {
+	for _, name := range names {
+		if h.index(name) == -1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// get returns the trimmed value of the first column matching one of the given names. If the column does not exist in the header or in
+// the row, an empty string is returned.
+func (h csvHeader) get(row []string, names ...string) string {
+	return cell(row, h.index(names...))
+}
+
+// cell returns the trimmed value of the column at the given index. If the index is out of range, an empty string is returned.
+func cell(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+
+	return strings.TrimSpace(row[i])
+}
+
+// parseOptionalRat converts a localized number string to big.Rat. Empty strings are treated as zero.
+func parseOptionalRat(s string, decimalSeparator rune) (*big.Rat, error) {
+	if s == "" {
+		return big.NewRat(0, 1), nil
+	}
+
+	return ioutils.ParseLocalizedRat(s, decimalSeparator)
+}
+
+// newAmountTx creates a transaction.Tx whose amount is stored in the UnitPrice field, e.g. a DIVIDEND, FEE or DEPOSIT.
+func newAmountTx(ts time.Time, assetId string, txType transaction.TxType, amount *big.Rat, id string, currency string) transaction.Tx {
+	return transaction.Tx{
+		Position: transaction.Position{
+			Timestamp: ts,
+			Asset:     &transaction.TxAsset{Id: assetId},
+			Quantity:  big.NewRat(1, 1),
+			UnitPrice: amount,
+		},
+		Type:     txType,
+		Id:       id,
+		Currency: currency,
+	}
+}
+
+// newTradeTx creates a BUY, SELL or REINVEST transaction.Tx.
+func newTradeTx(ts time.Time, assetId string, txType transaction.TxType, quantity *big.Rat, unitPrice *big.Rat, id string, currency string) transaction.Tx {
+	return transaction.Tx{
+		Position: transaction.Position{
+			Timestamp: ts,
+			Asset:     &transaction.TxAsset{Id: assetId},
+			Quantity:  quantity,
+			UnitPrice: unitPrice,
+		},
+		Type:     txType,
+		Id:       id,
+		Currency: currency,
+	}
+}
+
+// newDividendTx creates a DIVIDEND transaction.Tx holding the net amount of a dividend with the given gross amount. If tax was withheld
+// at the source, the gross amount and the withheld tax are recorded as well. The country of the issuer, which withholds the tax, is taken
+// from the ISIN of the asset if known.
+func newDividendTx(ts time.Time, assetId string, gross *big.Rat, withheld *big.Rat, id string, currency string, isin string) transaction.Tx {
+	t := newAmountTx(ts, assetId, transaction.DIVIDEND, big.NewRat(0, 1).Sub(gross, withheld), id, currency)
+	t.Country = isinCountry(isin)
+	if withheld.Sign() != 0 {
+		t.Gross = big.NewRat(0, 1).Set(gross)
+		t.WithheldTax = big.NewRat(0, 1).Set(withheld)
+	}
+
+	return t
+}
+
+// mergeWithholdingTaxes books the taxes withheld at the source, which some brokers report as separate rows, on the DIVIDEND of the same
+// asset paid in the same currency on the same day: the dividend keeps its amount as gross amount, and its UnitPrice is reduced to the net
+// amount. The withholding function selects the TAX transactions to merge. Taxes without a matching dividend, e.g. refunds of taxes
+// withheld in an earlier year, are kept as TAX transactions.
+func mergeWithholdingTaxes(txs []transaction.Tx, withholding func(t *transaction.Tx) bool) []transaction.Tx {
+	type key struct {
+		assetId  string
+		currency string
+		year     int
+		day      int
+	}
+
+	keyOf := func(t *transaction.Tx) key {
+		return key{assetId: t.Asset.Id, currency: t.Currency, year: t.Timestamp.Year(), day: t.Timestamp.YearDay()}
+	}
+
+	dividends := map[key]int{}
+	for i := range txs {
+		if _, ok := dividends[keyOf(&txs[i])]; !ok && txs[i].Type == transaction.DIVIDEND {
+			dividends[keyOf(&txs[i])] = i
+		}
+	}
+
+	removed := make([]bool, len(txs))
+	for i := range txs {
+		t := &txs[i]
+		d, ok := dividends[keyOf(t)]
+		if t.Type != transaction.TAX || !ok || !withholding(t) {
+			continue
+		}
+
+		dividend := &txs[d]
+		if dividend.Gross == nil {
+			dividend.Gross = big.NewRat(0, 1).Set(dividend.UnitPrice)
+			dividend.WithheldTax = big.NewRat(0, 1)
+		}
+		dividend.WithheldTax.Add(dividend.WithheldTax, t.UnitPrice)
+		dividend.UnitPrice = big.NewRat(0, 1).Sub(dividend.Gross, dividend.WithheldTax)
+		removed[i] = true
+	}
+
+	merged := make([]transaction.Tx, 0, len(txs))
+	for i := range txs {
+		if !removed[i] {
+			merged = append(merged, txs[i])
+		}
+	}
+
+	return merged
+}
+
+// isinCountry returns the ISO 3166-1 alpha-2 country code an ISIN starts with, or an empty string if isin is not an ISIN.
+func isinCountry(isin string) string {
+	if len(isin) != 12 {
+		return ""
+	}
+
+	for _, c := range isin[:2] {
+		if c < 'A' || c > 'Z' {
+			return ""
+		}
+	}
+
+	return isin[:2]
+}
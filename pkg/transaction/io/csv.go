@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"github.com/wlachs/wstonks/pkg/ioutils"
 	"github.com/wlachs/wstonks/pkg/transaction"
+	"io"
+	"io/fs"
 	"log"
+	"math/big"
 	"strconv"
 	"time"
 )
@@ -14,30 +17,68 @@ type TxCsvLoader struct {
 	Path string
 }
 
+// TxCsvReaderLoader implements the TransactionLoader interface to allow importing context data from CSV content provided by a reader,
+// e.g. an HTTP response body or the standard input.
+type TxCsvReaderLoader struct {
+	Reader io.Reader
+}
+
+// TxCsvFSLoader implements the TransactionLoader interface to allow importing context data from a CSV file of a file system, e.g. an
+// embed.FS holding embedded test data.
+type TxCsvFSLoader struct {
+	FS   fs.FS
+	Path string
+}
+
 // Load tries to parse the CSV file at Path and loads the data to the context's transaction history.
 func (l TxCsvLoader) Load(ctx *transaction.Context) error {
-	t, err := parseCsv(l.Path)
+	fileContent, err := ioutils.ReadCsvFile(l.Path)
 	if err != nil {
 		return err
 	}
 
-	return ctx.AddTransactions(t)
+	return loadCsv(ctx, fileContent)
+}
+
+// Load tries to parse the CSV content of Reader and loads the data to the context's transaction history.
+func (l TxCsvReaderLoader) Load(ctx *transaction.Context) error {
+	content, err := ioutils.ReadCsv(l.Reader)
+	if err != nil {
+		return err
+	}
+
+	return loadCsv(ctx, content)
+}
+
+// Load tries to parse the CSV file at Path of FS and loads the data to the context's transaction history.
+func (l TxCsvFSLoader) Load(ctx *transaction.Context) error {
+	fileContent, err := ioutils.ReadCsvFS(l.FS, l.Path)
+	if err != nil {
+		return err
+	}
+
+	return loadCsv(ctx, fileContent)
 }
 
-// parseCsv reads the CSV file at the given path and tries to convert it to a transaction.Tx slice.
-func parseCsv(path string) ([]transaction.Tx, error) {
-	fileContent, err := ioutils.ReadCsvFile(path)
+// loadCsv converts the CSV rows and adds them to the context's transaction history.
+func loadCsv(ctx *transaction.Context, content [][]string) error {
+	t, err := parseCsv(content)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	tradeHistory := make([]transaction.Tx, 0, len(fileContent))
-	if len(fileContent) == 0 {
+	return ctx.AddTransactions(t)
+}
+
+// parseCsv tries to convert the CSV rows to a transaction.Tx slice.
+func parseCsv(content [][]string) ([]transaction.Tx, error) {
+	tradeHistory := make([]transaction.Tx, 0, len(content))
+	if len(content) == 0 {
 		log.Println("the CSV file is empty")
 		return tradeHistory, nil
 	}
 
-	for _, row := range fileContent {
+	for _, row := range content {
 		tradeEvent, rowErr := readCsvRow(row)
 		if rowErr != nil {
 			return nil, rowErr
@@ -50,7 +91,13 @@ func parseCsv(path string) ([]transaction.Tx, error) {
 }
 
 // readCsvRow converts a single entry of the CSV file to a transaction.Tx object.
+// Besides the five mandatory columns, a row may hold the gross amount, the withheld tax, the country and the currency of a dividend in the
+// optional columns six to nine. Empty optional columns are left unset.
 func readCsvRow(row []string) (transaction.Tx, error) {
+	if len(row) < 5 {
+		return transaction.Tx{}, fmt.Errorf("missing columns in row %v", row)
+	}
+
 	// Timestamp
 	ts, err := parseTimestamp(row[0])
 	if err != nil {
@@ -81,7 +128,7 @@ func readCsvRow(row []string) (transaction.Tx, error) {
 		return transaction.Tx{}, fmt.Errorf("failed to parse unit price of row %v", row)
 	}
 
-	return transaction.Tx{
+	tx := transaction.Tx{
 		Position: transaction.Position{
 			Timestamp: ts,
 			Asset:     &transaction.TxAsset{Id: assetId},
@@ -89,7 +136,33 @@ func readCsvRow(row []string) (transaction.Tx, error) {
 			UnitPrice: unitPrice,
 		},
 		Type: tradeType,
-	}, nil
+	}
+
+	// Gross amount
+	if tx.Gross, err = parseCsvRat(row, 5); err != nil {
+		return transaction.Tx{}, fmt.Errorf("failed to parse gross amount of row %v", row)
+	}
+
+	// Withheld tax
+	if tx.WithheldTax, err = parseCsvRat(row, 6); err != nil {
+		return transaction.Tx{}, fmt.Errorf("failed to parse withheld tax of row %v", row)
+	}
+
+	// Country and currency
+	tx.Country = cell(row, 7)
+	tx.Currency = cell(row, 8)
+
+	return tx, nil
+}
+
+// parseCsvRat parses the column at the given index as a rational number. If the column is missing or empty, nil is returned.
+func parseCsvRat(row []string, i int) (*big.Rat, error) {
+	s := cell(row, i)
+	if s == "" {
+		return nil, nil
+	}
+
+	return ioutils.ParseRat(s)
 }
 
 // parseTimestamp reads a raw timestamp string and converts it to time.Time.
@@ -114,14 +187,5 @@ func parseAssetId(s string) (string, error) {
 
 // parseTradeType converts the context type string to transaction.TxType
 func parseTradeType(tt string) (transaction.TxType, error) {
-	switch tt {
-	case "BUY":
-		return transaction.BUY, nil
-	case "SELL":
-		return transaction.SELL, nil
-	case "DIVIDEND":
-		return transaction.DIVIDEND, nil
-	default:
-		return -1, fmt.Errorf("unsupported trade type")
-	}
+	return transaction.ParseTxType(tt)
 }
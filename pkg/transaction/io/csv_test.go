@@ -5,7 +5,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/wlachs/wstonks/pkg/transaction"
 	"github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"os"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 // TestTxCsvLoader_Load is a smoke-test for a well-formatted transaction input CSV.
@@ -21,6 +25,46 @@ func TestTxCsvLoader_Load(t *testing.T) {
 	assert.Equal(t, 5, len(ctx.Transactions))
 }
 
+// TestTxCsvLoader_Load_Withholding tests loading the optional gross amount, withheld tax, country and currency columns of dividends.
+func TestTxCsvLoader_Load_Withholding(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxCsvLoader{Path: "../../../test/data/io/transactions/withholding.csv"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(ctx.Transactions))
+
+	tx := ctx.Transactions[1]
+	assert.Equal(t, big.NewRat(17, 2), tx.UnitPrice)
+	assert.Equal(t, big.NewRat(10, 1), tx.Gross)
+	assert.Equal(t, big.NewRat(3, 2), tx.WithheldTax)
+	assert.Equal(t, "US", tx.Country)
+	assert.Equal(t, "USD", tx.Currency)
+
+	tx = ctx.Transactions[3]
+	assert.Equal(t, big.NewRat(10, 1), tx.Gross)
+	assert.Nil(t, tx.WithheldTax)
+	assert.Equal(t, "CH", tx.Country)
+
+	tx = ctx.Transactions[5]
+	assert.Nil(t, tx.Gross)
+	assert.Nil(t, tx.WithheldTax)
+	assert.Empty(t, tx.Country)
+	assert.Empty(t, tx.Currency)
+}
+
+// TestTxCsvLoader_Load_Missing_Columns tests loading a row with less than the mandatory columns.
+func TestTxCsvLoader_Load_Missing_Columns(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	err := io.TxCsvReaderLoader{Reader: strings.NewReader("1712000000000,A,BUY,10\n")}.Load(&ctx)
+
+	assert.Equal(t, fmt.Errorf("missing columns in row [1712000000000 A BUY 10]"), err)
+}
+
 // TestTxCsvLoader_Load_Empty tests loading an empty CSV file.
 func TestTxCsvLoader_Load_Empty(t *testing.T) {
 	t.Parallel()
@@ -99,3 +143,51 @@ func TestTxCsvLoader_Load_No_UnitPrice(t *testing.T) {
 
 	assert.Equal(t, fmt.Errorf("failed to parse unit price of row [1712200000000 A BUY 1.23456789 ]"), err)
 }
+
+// TestTxCsvReaderLoader_Load tests loading CSV content from a reader.
+func TestTxCsvReaderLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxCsvReaderLoader{Reader: strings.NewReader("1712000000000,A,BUY,10,110\n1712100000000,A,SELL,4,120\n")}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ctx.Assets))
+	assert.Equal(t, 2, len(ctx.Transactions))
+}
+
+// TestTxCsvReaderLoader_Load_No_Type tests loading malformed CSV content from a reader.
+func TestTxCsvReaderLoader_Load_No_Type(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxCsvReaderLoader{Reader: strings.NewReader("1712200000000,A,,1.23456789,50.12345\n")}
+	err := loader.Load(&ctx)
+
+	assert.Equal(t, fmt.Errorf("failed to parse trade type of row [1712200000000 A  1.23456789 50.12345]"), err)
+}
+
+// TestTxCsvFSLoader_Load tests loading a CSV file of a file system.
+func TestTxCsvFSLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxCsvFSLoader{FS: os.DirFS("../../../test/data/io"), Path: "transactions/smoke.csv"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ctx.Assets))
+	assert.Equal(t, 5, len(ctx.Transactions))
+}
+
+// TestTxCsvFSLoader_Load_Missing_File tests loading a non-existing file of an in-memory file system.
+func TestTxCsvFSLoader_Load_Missing_File(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxCsvFSLoader{FS: fstest.MapFS{}, Path: "transactions.csv"}
+	err := loader.Load(&ctx)
+
+	assert.Equal(t, fmt.Errorf("failed to open file \"transactions.csv\""), err)
+}
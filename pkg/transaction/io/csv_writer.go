@@ -0,0 +1,76 @@
+package io
+
+import (
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"strconv"
+)
+
+// TxCsvWriter implements the TransactionWriter interface to allow exporting the context's transaction history to a CSV file that can be
+// read by TxCsvLoader. Quantities and unit prices are written with the given number of Decimals using the given Rounding mode. The zero
+// value of Rounding is ioutils.RoundExact, which writes the values without losing precision. Timestamps are written in milliseconds. The
+// optional gross amount, withheld tax, country and currency columns are only written for transactions that have any of them set.
+type TxCsvWriter struct {
+	Path     string
+	Decimals int
+	Rounding ioutils.Rounding
+}
+
+// Write saves the context's transaction history to the CSV file at Path.
+func (w TxCsvWriter) Write(ctx *transaction.Context) error {
+	rows, err := w.formatCsv(ctx)
+	if err != nil {
+		return err
+	}
+
+	return ioutils.WriteCsvFile(w.Path, rows)
+}
+
+// formatCsv converts the transactions of the context to CSV rows.
+func (w TxCsvWriter) formatCsv(ctx *transaction.Context) ([][]string, error) {
+	transactions := ctx.GetTransactions()
+	rows := make([][]string, 0, len(transactions))
+
+	for _, t := range transactions {
+		row, err := w.formatCsvRow(t)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// formatCsvRow converts a single transaction.Tx to an entry of the CSV file.
+func (w TxCsvWriter) formatCsvRow(t *transaction.Tx) ([]string, error) {
+	tradeType, err := transaction.FormatTxType(t.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	row := []string{
+		strconv.FormatInt(t.Timestamp.UnixMilli(), 10),
+		t.Asset.Id,
+		tradeType,
+		ioutils.FormatRatWithPrecision(t.Quantity, w.Decimals, w.Rounding),
+		ioutils.FormatRatWithPrecision(t.UnitPrice, w.Decimals, w.Rounding),
+	}
+
+	if t.Gross == nil && t.WithheldTax == nil && t.Country == "" && t.Currency == "" {
+		return row, nil
+	}
+
+	return append(row, w.formatOptionalRat(t.Gross), w.formatOptionalRat(t.WithheldTax), t.Country, t.Currency), nil
+}
+
+// formatOptionalRat formats the value like the mandatory columns or returns an empty string if it is nil.
+func (w TxCsvWriter) formatOptionalRat(r *big.Rat) string {
+	if r == nil {
+		return ""
+	}
+
+	return ioutils.FormatRatWithPrecision(r, w.Decimals, w.Rounding)
+}
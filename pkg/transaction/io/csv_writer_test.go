@@ -0,0 +1,130 @@
+package io_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestTxCsvWriter_Write tests that a written CSV file can be loaded again without losing data.
+func TestTxCsvWriter_Write(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	err := io.TxCsvLoader{Path: "../../../test/data/io/transactions/smoke_sales.csv"}.Load(&ctx)
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "transactions.csv")
+	err = io.TxCsvWriter{Path: path}.Write(&ctx)
+	assert.Nil(t, err)
+
+	loaded := transaction.Context{}
+	err = io.TxCsvLoader{Path: path}.Load(&loaded)
+	assert.Nil(t, err)
+
+	assert.Equal(t, len(ctx.Transactions), len(loaded.Transactions))
+	for i, tx := range ctx.Transactions {
+		assert.True(t, tx.Timestamp.Equal(loaded.Transactions[i].Timestamp), "timestamp should match")
+		assert.Equal(t, tx.Asset.Id, loaded.Transactions[i].Asset.Id, "asset should match")
+		assert.Equal(t, tx.Type, loaded.Transactions[i].Type, "type should match")
+		assert.Equal(t, tx.Quantity, loaded.Transactions[i].Quantity, "quantity should match")
+		assert.Equal(t, tx.UnitPrice, loaded.Transactions[i].UnitPrice, "unit price should match")
+	}
+}
+
+// TestTxCsvWriter_Write_Withholding tests that the optional withholding columns are only written for transactions that have them set.
+func TestTxCsvWriter_Write_Withholding(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	err := io.TxCsvLoader{Path: "../../../test/data/io/transactions/withholding.csv"}.Load(&ctx)
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "transactions.csv")
+	err = io.TxCsvWriter{Path: path}.Write(&ctx)
+	assert.Nil(t, err)
+
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "1712000000000,A,BUY,10,100\n"+
+		"1712100000000,A,DIVIDEND,1,8.5,10,1.5,US,USD\n"+
+		"1712200000000,B,BUY,5,20\n"+
+		"1712300000000,B,DIVIDEND,1,7.3625,10,,CH,CHF\n"+
+		"1712400000000,A,DIVIDEND,1,4.25,,0.75,US,USD\n"+
+		"1712500000000,B,DIVIDEND,1,2\n", string(content))
+}
+
+// TestTxCsvWriter_Write_Exact tests that values without a finite decimal representation are written as fractions.
+func TestTxCsvWriter_Write_Exact(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	err := ctx.AddTransaction(transaction.Tx{
+		Position: transaction.Position{
+			Asset:     &transaction.TxAsset{Id: "A"},
+			Timestamp: time.UnixMilli(1712000000000),
+			UnitPrice: big.NewRat(1, 3),
+			Quantity:  big.NewRat(5, 2),
+		},
+		Type: transaction.BUY,
+	})
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "transactions.csv")
+	err = io.TxCsvWriter{Path: path}.Write(&ctx)
+	assert.Nil(t, err)
+
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "1712000000000,A,BUY,2.5,1/3\n", string(content))
+
+	loaded := transaction.Context{}
+	err = io.TxCsvLoader{Path: path}.Load(&loaded)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewRat(1, 3), loaded.Transactions[0].UnitPrice)
+}
+
+// TestTxCsvWriter_Write_Rounding tests the supported rounding modes.
+func TestTxCsvWriter_Write_Rounding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		rounding ioutils.Rounding
+		decimals int
+		expected string
+	}{
+		{ioutils.RoundHalfUp, 0, "1712000000000,A,BUY,3,-3\n"},
+		{ioutils.RoundHalfEven, 0, "1712000000000,A,BUY,2,-2\n"},
+		{ioutils.RoundDown, 0, "1712000000000,A,BUY,2,-2\n"},
+		{ioutils.RoundUp, 0, "1712000000000,A,BUY,3,-3\n"},
+		{ioutils.RoundHalfUp, 2, "1712000000000,A,BUY,2.50,-2.50\n"},
+	}
+
+	ctx := transaction.Context{}
+	err := ctx.AddTransaction(transaction.Tx{
+		Position: transaction.Position{
+			Asset:     &transaction.TxAsset{Id: "A"},
+			Timestamp: time.UnixMilli(1712000000000),
+			UnitPrice: big.NewRat(-5, 2),
+			Quantity:  big.NewRat(5, 2),
+		},
+		Type: transaction.BUY,
+	})
+	assert.Nil(t, err)
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "transactions.csv")
+		err = io.TxCsvWriter{Path: path, Decimals: test.decimals, Rounding: test.rounding}.Write(&ctx)
+		assert.Nil(t, err)
+
+		content, readErr := os.ReadFile(path)
+		assert.Nil(t, readErr)
+		assert.Equal(t, test.expected, string(content))
+	}
+}
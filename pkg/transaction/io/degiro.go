@@ -0,0 +1,326 @@
+package io
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// TxDegiroCsvLoader implements the TransactionLoader interface to allow importing context data from a Degiro transactions CSV export.
+// English, German and Dutch exports are supported. Every order is imported together with the transaction fees charged on it.
+type TxDegiroCsvLoader struct {
+	Path string
+}
+
+// TxDegiroAccountCsvLoader implements the TransactionLoader interface to allow importing context data from a Degiro account statement CSV
+// export. Dividends, dividend tax, interest, deposits, withdrawals and fees are supported. Orders and their transaction fees are skipped,
+// since they are covered by the transactions export loaded by TxDegiroCsvLoader.
+type TxDegiroAccountCsvLoader struct {
+	Path string
+}
+
+// degiroLanguage holds the column names and number format of a localized Degiro export.
+type degiroLanguage struct {
+	decimalSeparator rune
+	date             string
+	time             string
+	isin             string
+	quantity         string
+	price            string
+	fees             []string
+	orderId          string
+	description      string
+	change           string
+	keywords         map[string]transaction.TxType
+}
+
+// degiroLanguages lists the supported localizations of the Degiro exports.
+var degiroLanguages = []degiroLanguage{
+	{
+		decimalSeparator: '.',
+		date:             "Date",
+		time:             "Time",
+		isin:             "ISIN",
+		quantity:         "Quantity",
+		price:            "Price",
+		fees:             []string{"Transaction and/or third party fees", "Transaction and/or third", "Transaction costs"},
+		orderId:          "Order ID",
+		description:      "Description",
+		change:           "Change",
+		keywords: map[string]transaction.TxType{
+			"dividend tax": transaction.TAX,
+			"dividend":     transaction.DIVIDEND,
+			"interest":     transaction.INTEREST,
+			"deposit":      transaction.DEPOSIT,
+			"withdrawal":   transaction.WITHDRAWAL,
+			"fee":          transaction.FEE,
+		},
+	},
+	{
+		decimalSeparator: ',',
+		date:             "Datum",
+		time:             "Uhrzeit",
+		isin:             "ISIN",
+		quantity:         "Anzahl",
+		price:            "Kurs",
+		fees:             []string{"Transaktionskosten und/oder Kosten Dritter", "Transaktionskosten und/oder", "Transaktionskosten"},
+		orderId:          "Order-ID",
+		description:      "Beschreibung",
+		change:           "Änderung",
+		keywords: map[string]transaction.TxType{
+			"dividendensteuer": transaction.TAX,
+			"dividende":        transaction.DIVIDEND,
+			"zinsen":           transaction.INTEREST,
+			"einzahlung":       transaction.DEPOSIT,
+			"auszahlung":       transaction.WITHDRAWAL,
+			"gebühr":           transaction.FEE,
+		},
+	},
+	{
+		decimalSeparator: ',',
+		date:             "Datum",
+		time:             "Tijd",
+		isin:             "ISIN",
+		quantity:         "Aantal",
+		price:            "Koers",
+		fees:             []string{"Transactiekosten en/of kosten van derden", "Transactiekosten en/of", "Transactiekosten"},
+		orderId:          "Order ID",
+		description:      "Omschrijving",
+		change:           "Mutatie",
+		keywords: map[string]transaction.TxType{
+			"dividendbelasting": transaction.TAX,
+			"dividend":          transaction.DIVIDEND,
+			"rente":             transaction.INTEREST,
+			"storting":          transaction.DEPOSIT,
+			"terugstorting":     transaction.WITHDRAWAL,
+			"kosten":            transaction.FEE,
+		},
+	},
+}
+
+// degiroTradeKeywords lists the description prefixes of account statement rows belonging to orders, which are skipped.
+var degiroTradeKeywords = []string{"buy", "sell", "kauf", "verkauf", "koop", "verkoop", "degiro transaction", "transaktionsgebühr",
+	"transactiekosten"}
+
+// Load tries to parse the Degiro transactions CSV file at Path and loads the data to the context's transaction history.
+func (l TxDegiroCsvLoader) Load(ctx *transaction.Context) error {
+	t, err := parseDegiroCsv(l.Path)
+	if err != nil {
+		return err
+	}
+
+	return ctx.AddTransactions(t)
+}
+
+// Load tries to parse the Degiro account statement CSV file at Path and loads the data to the context's transaction history.
+func (l TxDegiroAccountCsvLoader) Load(ctx *transaction.Context) error {
+	t, err := parseDegiroAccountCsv(l.Path)
+	if err != nil {
+		return err
+	}
+
+	return ctx.AddTransactions(t)
+}
+
+// readDegiroCsv reads the Degiro CSV file at the given path and detects the language of the export by checking which localization
+// contains every required column.
+func readDegiroCsv(path string, required func(language degiroLanguage) []string) ([][]string, csvHeader, degiroLanguage, error) {
+	fileContent, err := ioutils.ReadCsvFileWithComma(path, ',')
+	if err != nil {
+		return nil, nil, degiroLanguage{}, err
+	}
+
+	if len(fileContent) == 0 {
+		return fileContent, nil, degiroLanguage{}, nil
+	}
+
+	header := newCsvHeader(fileContent[0])
+	for _, language := range degiroLanguages {
+		if header.has(required(language)...) {
+			return fileContent[1:], header, language, nil
+		}
+	}
+
+	return nil, nil, degiroLanguage{}, fmt.Errorf("unsupported Degiro CSV header %v", fileContent[0])
+}
+
+// parseDegiroCsv reads the Degiro transactions CSV file at the given path and tries to convert it to a transaction.Tx slice.
+func parseDegiroCsv(path string) ([]transaction.Tx, error) {
+	rows, header, language, err := readDegiroCsv(path, func(l degiroLanguage) []string {
+		return []string{l.date, l.time, l.isin, l.quantity, l.price}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tradeHistory := make([]transaction.Tx, 0, len(rows))
+	if len(rows) == 0 {
+		log.Println("the CSV file is empty")
+		return tradeHistory, nil
+	}
+
+	for _, row := range rows {
+		t, rowErr := readDegiroCsvRow(header, language, row)
+		if rowErr != nil {
+			return nil, rowErr
+		}
+
+		tradeHistory = append(tradeHistory, t...)
+	}
+
+	return tradeHistory, nil
+}
+
+// readDegiroCsvRow converts a single entry of the Degiro transactions CSV file to a BUY or SELL transaction.Tx, followed by a FEE
+// transaction if fees were charged. The currency of a value is stored in the unnamed column following it.
+func readDegiroCsvRow(header csvHeader, language degiroLanguage, row []string) ([]transaction.Tx, error) {
+	ts, err := parseDegiroTimestamp(header.get(row, language.date), header.get(row, language.time))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TS of row %v", row)
+	}
+
+	assetId, err := parseAssetId(header.get(row, language.isin))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse asset ID of row %v", row)
+	}
+
+	quantity, err := ioutils.ParseLocalizedRat(header.get(row, language.quantity), language.decimalSeparator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse quantity of row %v", row)
+	}
+
+	priceIndex := header.index(language.price)
+	unitPrice, err := ioutils.ParseLocalizedRat(cell(row, priceIndex), language.decimalSeparator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse unit price of row %v", row)
+	}
+
+	txType := transaction.BUY
+	if quantity.Sign() < 0 {
+		txType = transaction.SELL
+	}
+
+	id := header.get(row, language.orderId)
+	txs := []transaction.Tx{newTradeTx(ts, assetId, txType, quantity.Abs(quantity), unitPrice, id, cell(row, priceIndex+1))}
+
+	feeIndex := header.index(language.fees...)
+	fee, err := parseOptionalRat(cell(row, feeIndex), language.decimalSeparator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fees of row %v", row)
+	}
+
+	if fee.Sign() != 0 {
+		txs = append(txs, newAmountTx(ts, assetId, transaction.FEE, fee.Neg(fee), id, cell(row, feeIndex+1)))
+	}
+
+	return txs, nil
+}
+
+// parseDegiroAccountCsv reads the Degiro account statement CSV file at the given path and tries to convert it to a transaction.Tx slice.
+func parseDegiroAccountCsv(path string) ([]transaction.Tx, error) {
+	rows, header, language, err := readDegiroCsv(path, func(l degiroLanguage) []string {
+		return []string{l.date, l.time, l.description, l.change}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tradeHistory := make([]transaction.Tx, 0, len(rows))
+	if len(rows) == 0 {
+		log.Println("the CSV file is empty")
+		return tradeHistory, nil
+	}
+
+	for _, row := range rows {
+		t, ok, rowErr := readDegiroAccountCsvRow(header, language, row)
+		if rowErr != nil {
+			return nil, rowErr
+		}
+
+		if ok {
+			tradeHistory = append(tradeHistory, t)
+		}
+	}
+
+	/* The only taxes of the account statement are dividend taxes, which are withheld from the dividends. */
+	return mergeWithholdingTaxes(tradeHistory, func(*transaction.Tx) bool { return true }), nil
+}
+
+// readDegiroAccountCsvRow converts a single entry of the Degiro account statement CSV file to a transaction.Tx object. Rows belonging to
+// orders and rows with unknown descriptions are skipped and the second return value is false. The "Change" column holds the currency, the
+// unnamed column following it the amount. Cash rows without an ISIN are booked on the currency.
+func readDegiroAccountCsvRow(header csvHeader, language degiroLanguage, row []string) (transaction.Tx, bool, error) {
+	description := strings.ToLower(header.get(row, language.description))
+	txType, ok := matchDegiroDescription(language, description)
+	if !ok {
+		log.Printf("skipping Degiro account statement row \"%s\"\n", header.get(row, language.description))
+		return transaction.Tx{}, false, nil
+	}
+
+	ts, err := parseDegiroTimestamp(header.get(row, language.date), header.get(row, language.time))
+	if err != nil {
+		return transaction.Tx{}, false, fmt.Errorf("failed to parse TS of row %v", row)
+	}
+
+	changeIndex := header.index(language.change)
+	currency := cell(row, changeIndex)
+	amount, err := ioutils.ParseLocalizedRat(cell(row, changeIndex+1), language.decimalSeparator)
+	if err != nil {
+		return transaction.Tx{}, false, fmt.Errorf("failed to parse amount of row %v", row)
+	}
+
+	assetId := header.get(row, language.isin)
+	if assetId == "" {
+		assetId = currency
+	}
+
+	assetId, err = parseAssetId(assetId)
+	if err != nil {
+		return transaction.Tx{}, false, fmt.Errorf("failed to parse asset ID of row %v", row)
+	}
+
+	/* Outgoing cash is reported as a negative change. */
+	switch txType {
+	case transaction.TAX, transaction.FEE, transaction.WITHDRAWAL:
+		amount.Neg(amount)
+	case transaction.DIVIDEND:
+		return newDividendTx(ts, assetId, amount, big.NewRat(0, 1), header.get(row, language.orderId), currency, assetId), true, nil
+	default:
+	}
+
+	return newAmountTx(ts, assetId, txType, amount, header.get(row, language.orderId), currency), true, nil
+}
+
+// matchDegiroDescription maps the description of an account statement row to a transaction.TxType. Longer keywords take precedence, so
+// e.g. "Dividend Tax" is not mistaken for "Dividend".
+func matchDegiroDescription(language degiroLanguage, description string) (transaction.TxType, bool) {
+	for _, keyword := range degiroTradeKeywords {
+		if strings.HasPrefix(description, keyword) {
+			return -1, false
+		}
+	}
+
+	match := ""
+	txType := transaction.TxType(-1)
+	for keyword, t := range language.keywords {
+		if strings.Contains(description, keyword) && len(keyword) > len(match) {
+			match = keyword
+			txType = t
+		}
+	}
+
+	return txType, match != ""
+}
+
+// parseDegiroTimestamp reads the date and time columns of a Degiro export and converts them to time.Time.
+func parseDegiroTimestamp(date string, tm string) (time.Time, error) {
+	if tm == "" {
+		return time.Parse("02-01-2006", date)
+	}
+
+	return time.Parse("02-01-2006 15:04", date+" "+tm)
+}
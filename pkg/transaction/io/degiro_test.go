@@ -0,0 +1,78 @@
+package io_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"testing"
+)
+
+// TestTxDegiroCsvLoader_Load is a smoke-test for an English Degiro transactions export.
+func TestTxDegiroCsvLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxDegiroCsvLoader{Path: "../../../test/data/io/transactions/degiro/transactions_en.csv"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ctx.Assets))
+	assert.Equal(t, 5, len(ctx.Transactions))
+	assert.Equal(t, big.NewRat(6, 1), ctx.GetAssetKeyMap()["IE00B4L5Y983"])
+	assert.Equal(t, "USD", ctx.Transactions[2].Currency)
+	assert.Equal(t, "d4e5f6", ctx.Transactions[2].Id)
+	assert.Equal(t, transaction.FEE, ctx.Transactions[3].Type)
+	assert.Equal(t, big.NewRat(2, 1), ctx.Transactions[3].UnitPrice)
+}
+
+// TestTxDegiroCsvLoader_Load_German tests loading a German Degiro transactions export with localized numbers.
+func TestTxDegiroCsvLoader_Load_German(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxDegiroCsvLoader{Path: "../../../test/data/io/transactions/degiro/transactions_de.csv"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(ctx.Transactions))
+	assert.Equal(t, big.NewRat(2128, 25), ctx.Transactions[0].UnitPrice)
+	assert.Equal(t, transaction.SELL, ctx.Transactions[2].Type)
+	assert.Equal(t, big.NewRat(1090, 1), ctx.Transactions[2].UnitPrice)
+}
+
+// TestTxDegiroAccountCsvLoader_Load tests loading the cash movements of a Degiro account statement. The dividend tax is booked on the
+// dividend.
+func TestTxDegiroAccountCsvLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxDegiroAccountCsvLoader{Path: "../../../test/data/io/transactions/degiro/account_en.csv"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ctx.Assets))
+
+	types := make([]transaction.TxType, 0, len(ctx.Transactions))
+	for _, tx := range ctx.Transactions {
+		types = append(types, tx.Type)
+	}
+
+	assert.Equal(t, []transaction.TxType{transaction.DEPOSIT, transaction.DIVIDEND, transaction.FEE}, types)
+	assert.Equal(t, big.NewRat(106, 100), ctx.Transactions[1].UnitPrice)
+	assert.Equal(t, big.NewRat(125, 100), ctx.Transactions[1].Gross)
+	assert.Equal(t, big.NewRat(19, 100), ctx.Transactions[1].WithheldTax)
+	assert.Equal(t, "US", ctx.Transactions[1].Country)
+	assert.Equal(t, big.NewRat(5, 2), ctx.Transactions[2].UnitPrice)
+}
+
+// TestTxDegiroCsvLoader_Load_Wrong_Format tests loading a CSV file of a different format.
+func TestTxDegiroCsvLoader_Load_Wrong_Format(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxDegiroCsvLoader{Path: "../../../test/data/io/transactions/degiro/account_en.csv"}
+	err := loader.Load(&ctx)
+
+	assert.NotNil(t, err)
+}
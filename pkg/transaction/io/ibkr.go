@@ -0,0 +1,354 @@
+package io
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// TxIbkrFlexLoader implements the TransactionLoader interface to allow importing context data from an Interactive Brokers Flex Query XML
+// statement. Trades, cash transactions (dividends, withholding tax and fees) and corporate actions (splits) are supported.
+type TxIbkrFlexLoader struct {
+	Path string
+}
+
+// flexQueryResponse is the root element of a Flex Query XML statement.
+type flexQueryResponse struct {
+	Statements []flexStatement `xml:"FlexStatements>FlexStatement"`
+}
+
+// flexStatement holds the sections of a single Flex statement.
+type flexStatement struct {
+	AccountId        string                `xml:"accountId,attr"`
+	Trades           []flexTrade           `xml:"Trades>Trade"`
+	CashTransactions []flexCashTransaction `xml:"CashTransactions>CashTransaction"`
+	CorporateActions []flexCorporateAction `xml:"CorporateActions>CorporateAction"`
+}
+
+// flexTrade is a single execution in the Trades section.
+type flexTrade struct {
+	TradeId              string `xml:"tradeID,attr"`
+	Symbol               string `xml:"symbol,attr"`
+	Currency             string `xml:"currency,attr"`
+	DateTime             string `xml:"dateTime,attr"`
+	TradeDate            string `xml:"tradeDate,attr"`
+	Quantity             string `xml:"quantity,attr"`
+	TradePrice           string `xml:"tradePrice,attr"`
+	BuySell              string `xml:"buySell,attr"`
+	IbCommission         string `xml:"ibCommission,attr"`
+	IbCommissionCurrency string `xml:"ibCommissionCurrency,attr"`
+}
+
+// flexCashTransaction is a single entry in the CashTransactions section.
+type flexCashTransaction struct {
+	TransactionId string `xml:"transactionID,attr"`
+	Symbol        string `xml:"symbol,attr"`
+	Isin          string `xml:"isin,attr"`
+	Currency      string `xml:"currency,attr"`
+	DateTime      string `xml:"dateTime,attr"`
+	Amount        string `xml:"amount,attr"`
+	Type          string `xml:"type,attr"`
+}
+
+// flexCorporateAction is a single entry in the CorporateActions section.
+type flexCorporateAction struct {
+	TransactionId string `xml:"transactionID,attr"`
+	Symbol        string `xml:"symbol,attr"`
+	Currency      string `xml:"currency,attr"`
+	DateTime      string `xml:"dateTime,attr"`
+	Quantity      string `xml:"quantity,attr"`
+	Type          string `xml:"type,attr"`
+}
+
+// flexTimestampLayouts lists the date and time formats that can be configured for Flex Query output.
+var flexTimestampLayouts = []string{
+	"20060102;150405",
+	"20060102,150405",
+	"20060102 150405",
+	"2006-01-02;15:04:05",
+	"2006-01-02, 15:04:05",
+	"2006-01-02 15:04:05",
+	"20060102",
+	"2006-01-02",
+}
+
+// Load tries to parse the Flex Query XML file at Path and loads the data to the context's transaction history.
+func (l TxIbkrFlexLoader) Load(ctx *transaction.Context) error {
+	t, err := parseIbkrFlex(l.Path)
+	if err != nil {
+		return err
+	}
+
+	return ctx.AddTransactions(t)
+}
+
+// parseIbkrFlex reads the Flex Query XML file at the given path and tries to convert it to a transaction.Tx slice.
+func parseIbkrFlex(path string) ([]transaction.Tx, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file \"%s\"", path)
+	}
+
+	var response flexQueryResponse
+	if err = xml.Unmarshal(content, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse Flex Query XML: %w", err)
+	}
+
+	var txs []transaction.Tx
+	for _, statement := range response.Statements {
+		t, statementErr := readFlexStatement(statement)
+		if statementErr != nil {
+			return nil, statementErr
+		}
+
+		txs = append(txs, t...)
+	}
+
+	if len(txs) == 0 {
+		log.Println("the Flex statement contains no transactions")
+	}
+
+	return txs, nil
+}
+
+// readFlexStatement converts every supported entry of a Flex statement to transaction.Tx objects.
+func readFlexStatement(statement flexStatement) ([]transaction.Tx, error) {
+	txs := make([]transaction.Tx, 0, len(statement.Trades)+len(statement.CashTransactions)+len(statement.CorporateActions))
+
+	for _, trade := range statement.Trades {
+		t, err := readFlexTrade(trade)
+		if err != nil {
+			return nil, err
+		}
+
+		txs = append(txs, t...)
+	}
+
+	cash := make([]transaction.Tx, 0, len(statement.CashTransactions))
+	for _, cashTransaction := range statement.CashTransactions {
+		t, ok, err := readFlexCashTransaction(cashTransaction)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			cash = append(cash, t)
+		}
+	}
+
+	/* Every tax among the cash transactions is withheld from a dividend. */
+	txs = append(txs, mergeWithholdingTaxes(cash, func(*transaction.Tx) bool { return true })...)
+
+	for _, corporateAction := range statement.CorporateActions {
+		t, ok, err := readFlexCorporateAction(corporateAction)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			txs = append(txs, t)
+		}
+	}
+
+	return txs, nil
+}
+
+// readFlexTrade converts a Flex trade to a BUY or SELL transaction.Tx. If a commission was charged, an additional FEE transaction with the
+// same ID is returned.
+func readFlexTrade(trade flexTrade) ([]transaction.Tx, error) {
+	ts, err := parseFlexTimestamp(trade.DateTime, trade.TradeDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TS of trade %s", trade.TradeId)
+	}
+
+	assetId, err := parseAssetId(trade.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse asset ID of trade %s", trade.TradeId)
+	}
+
+	quantity, err := ioutils.ParseRat(trade.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse quantity of trade %s", trade.TradeId)
+	}
+
+	unitPrice, err := ioutils.ParseRat(trade.TradePrice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse unit price of trade %s", trade.TradeId)
+	}
+
+	tradeType, err := parseFlexBuySell(trade.BuySell, quantity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse trade type of trade %s", trade.TradeId)
+	}
+
+	txs := []transaction.Tx{{
+		Position: transaction.Position{
+			Timestamp: ts,
+			Asset:     &transaction.TxAsset{Id: assetId},
+			Quantity:  quantity.Abs(quantity),
+			UnitPrice: unitPrice,
+		},
+		Type:     tradeType,
+		Id:       trade.TradeId,
+		Currency: trade.Currency,
+	}}
+
+	if trade.IbCommission == "" {
+		return txs, nil
+	}
+
+	commission, err := ioutils.ParseRat(trade.IbCommission)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse commission of trade %s", trade.TradeId)
+	}
+
+	if commission.Sign() == 0 {
+		return txs, nil
+	}
+
+	currency := trade.IbCommissionCurrency
+	if currency == "" {
+		currency = trade.Currency
+	}
+
+	return append(txs, transaction.Tx{
+		Position: transaction.Position{
+			Timestamp: ts,
+			Asset:     &transaction.TxAsset{Id: assetId},
+			Quantity:  big.NewRat(1, 1),
+			UnitPrice: commission.Neg(commission),
+		},
+		Type:     transaction.FEE,
+		Id:       trade.TradeId,
+		Currency: currency,
+	}), nil
+}
+
+// readFlexCashTransaction converts a Flex cash transaction to a DIVIDEND, TAX or FEE transaction.Tx. Cash transactions of other types,
+// e.g. deposits, are skipped and the second return value is false. Account level fees without a symbol are booked on the currency.
+// Withheld taxes are merged into their dividends by readFlexStatement.
+func readFlexCashTransaction(cashTransaction flexCashTransaction) (transaction.Tx, bool, error) {
+	var txType transaction.TxType
+	switch cashTransaction.Type {
+	case "Dividends", "Payment In Lieu Of Dividends":
+		txType = transaction.DIVIDEND
+	case "Withholding Tax":
+		txType = transaction.TAX
+	case "Other Fees", "Commission Adjustments", "Broker Fees":
+		txType = transaction.FEE
+	default:
+		log.Printf("skipping unsupported cash transaction type \"%s\"\n", cashTransaction.Type)
+		return transaction.Tx{}, false, nil
+	}
+
+	ts, err := parseFlexTimestamp(cashTransaction.DateTime, "")
+	if err != nil {
+		return transaction.Tx{}, false, fmt.Errorf("failed to parse TS of cash transaction %s", cashTransaction.TransactionId)
+	}
+
+	symbol := cashTransaction.Symbol
+	if symbol == "" && txType == transaction.FEE {
+		symbol = cashTransaction.Currency
+	}
+
+	assetId, err := parseAssetId(symbol)
+	if err != nil {
+		return transaction.Tx{}, false, fmt.Errorf("failed to parse asset ID of cash transaction %s", cashTransaction.TransactionId)
+	}
+
+	amount, err := ioutils.ParseRat(cashTransaction.Amount)
+	if err != nil {
+		return transaction.Tx{}, false, fmt.Errorf("failed to parse amount of cash transaction %s", cashTransaction.TransactionId)
+	}
+
+	/* Taxes and fees are reported as negative cash amounts, refunds as positive ones. */
+	if txType != transaction.DIVIDEND {
+		amount.Neg(amount)
+	}
+
+	if txType == transaction.DIVIDEND {
+		id, currency := cashTransaction.TransactionId, cashTransaction.Currency
+		return newDividendTx(ts, assetId, amount, big.NewRat(0, 1), id, currency, cashTransaction.Isin), true, nil
+	}
+
+	return newAmountTx(ts, assetId, txType, amount, cashTransaction.TransactionId, cashTransaction.Currency), true, nil
+}
+
+// readFlexCorporateAction converts a Flex corporate action to a SPLIT transaction.Tx. Forward splits, reverse splits and stock dividends are
+// supported, other corporate actions are skipped and the second return value is false.
+func readFlexCorporateAction(corporateAction flexCorporateAction) (transaction.Tx, bool, error) {
+	switch corporateAction.Type {
+	case "FS", "RS", "SD":
+	default:
+		log.Printf("skipping unsupported corporate action type \"%s\"\n", corporateAction.Type)
+		return transaction.Tx{}, false, nil
+	}
+
+	ts, err := parseFlexTimestamp(corporateAction.DateTime, "")
+	if err != nil {
+		return transaction.Tx{}, false, fmt.Errorf("failed to parse TS of corporate action %s", corporateAction.TransactionId)
+	}
+
+	assetId, err := parseAssetId(corporateAction.Symbol)
+	if err != nil {
+		return transaction.Tx{}, false, fmt.Errorf("failed to parse asset ID of corporate action %s", corporateAction.TransactionId)
+	}
+
+	quantity, err := ioutils.ParseRat(corporateAction.Quantity)
+	if err != nil {
+		return transaction.Tx{}, false, fmt.Errorf("failed to parse quantity of corporate action %s", corporateAction.TransactionId)
+	}
+
+	return transaction.Tx{
+		Position: transaction.Position{
+			Timestamp: ts,
+			Asset:     &transaction.TxAsset{Id: assetId},
+			Quantity:  quantity,
+			UnitPrice: big.NewRat(0, 1),
+		},
+		Type:     transaction.SPLIT,
+		Id:       corporateAction.TransactionId,
+		Currency: corporateAction.Currency,
+	}, true, nil
+}
+
+// parseFlexTimestamp reads a Flex date-time string and converts it to time.Time. If the date-time is empty, the fallback date is used.
+func parseFlexTimestamp(dateTime string, fallback string) (time.Time, error) {
+	s := strings.TrimSpace(dateTime)
+	if s == "" {
+		s = strings.TrimSpace(fallback)
+	}
+
+	for _, layout := range flexTimestampLayouts {
+		ts, err := time.Parse(layout, s)
+		if err == nil {
+			return ts, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unsupported timestamp format \"%s\"", s)
+}
+
+// parseFlexBuySell converts the buySell attribute of a Flex trade to transaction.TxType. If the attribute is missing, the sign of the
+// quantity decides.
+func parseFlexBuySell(buySell string, quantity *big.Rat) (transaction.TxType, error) {
+	switch strings.ToUpper(buySell) {
+	case "BUY":
+		return transaction.BUY, nil
+	case "SELL":
+		return transaction.SELL, nil
+	case "":
+		if quantity.Sign() < 0 {
+			return transaction.SELL, nil
+		}
+		return transaction.BUY, nil
+	default:
+		return -1, fmt.Errorf("unsupported trade type")
+	}
+}
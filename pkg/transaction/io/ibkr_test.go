@@ -0,0 +1,116 @@
+package io_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// TestTxIbkrFlexLoader_Load is a smoke-test for a well-formatted Flex Query XML statement.
+func TestTxIbkrFlexLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxIbkrFlexLoader{Path: "../../../test/data/io/transactions/ibkr/smoke.xml"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(ctx.Assets))
+	assert.Equal(t, 8, len(ctx.Transactions))
+
+	types := map[transaction.TxType]int{}
+	for _, tx := range ctx.Transactions {
+		types[tx.Type]++
+	}
+
+	assert.Equal(t, map[transaction.TxType]int{
+		transaction.BUY:      2,
+		transaction.SELL:     1,
+		transaction.DIVIDEND: 1,
+		transaction.FEE:      3,
+		transaction.SPLIT:    1,
+	}, types)
+}
+
+// TestTxIbkrFlexLoader_Load_Ids_And_Currencies tests that the trade IDs and currencies of the statement are kept.
+func TestTxIbkrFlexLoader_Load_Ids_And_Currencies(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxIbkrFlexLoader{Path: "../../../test/data/io/transactions/ibkr/smoke.xml"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1001", ctx.Transactions[0].Id)
+	assert.Equal(t, "USD", ctx.Transactions[0].Currency)
+	assert.Equal(t, "1002", ctx.Transactions[2].Id)
+	assert.Equal(t, "EUR", ctx.Transactions[2].Currency)
+}
+
+// TestTxIbkrFlexLoader_Load_Withholding tests that the withholding tax of a statement is booked on its dividend and reported by the
+// withholding report.
+func TestTxIbkrFlexLoader_Load_Withholding(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxIbkrFlexLoader{Path: "../../../test/data/io/transactions/ibkr/smoke.xml"}
+	err := loader.Load(&ctx)
+	assert.Nil(t, err)
+
+	report, err := ctx.GetWithholdingReport(map[string]*big.Rat{"US": big.NewRat(15, 100)}, time.Time{}, time.Time{})
+	assert.Nil(t, err)
+	assert.Equal(t, []transaction.WithholdingSummary{{
+		Country:     "US",
+		Currency:    "USD",
+		Gross:       big.NewRat(144, 100),
+		Withheld:    big.NewRat(22, 100),
+		Net:         big.NewRat(122, 100),
+		TreatyRate:  big.NewRat(15, 100),
+		Creditable:  big.NewRat(216, 1000),
+		Reclaimable: big.NewRat(4, 1000),
+	}}, report)
+}
+
+// TestTxIbkrFlexLoader_Load_Split tests that the forward split is applied to the open positions.
+func TestTxIbkrFlexLoader_Load_Split(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxIbkrFlexLoader{Path: "../../../test/data/io/transactions/ibkr/smoke.xml"}
+	err := loader.Load(&ctx)
+	assert.Nil(t, err)
+
+	positions, err := ctx.GetAssetKeyPositions("AAPL")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(positions))
+	assert.Equal(t, big.NewRat(24, 1), positions[0].Quantity)
+	assert.Equal(t, big.NewRat(371, 8), positions[0].UnitPrice)
+	assert.Equal(t, big.NewRat(24, 1), ctx.GetAssetKeyMap()["AAPL"])
+}
+
+// TestTxIbkrFlexLoader_Load_Missing_File tests loading a non-existing file.
+func TestTxIbkrFlexLoader_Load_Missing_File(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxIbkrFlexLoader{Path: "../../../test/data/io/###.xml"}
+	err := loader.Load(&ctx)
+
+	assert.Equal(t, fmt.Errorf("failed to open file \"../../../test/data/io/###.xml\""), err)
+}
+
+// TestTxIbkrFlexLoader_Load_Malformed tests loading a statement with an invalid trade quantity.
+func TestTxIbkrFlexLoader_Load_Malformed(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxIbkrFlexLoader{Path: "../../../test/data/io/transactions/ibkr/malformed.xml"}
+	err := loader.Load(&ctx)
+
+	assert.Equal(t, fmt.Errorf("failed to parse quantity of trade 1001"), err)
+}
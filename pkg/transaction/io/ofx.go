@@ -0,0 +1,314 @@
+package io
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"log"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TxOfxLoader implements the TransactionLoader interface to allow importing context data from an OFX or QFX investment statement.
+// Purchases (INVBUY), sales (INVSELL), income (INCOME), reinvestments (REINVEST) and splits (SPLIT) are supported. Securities are
+// identified by their ticker symbol if the statement contains a security list, otherwise by their unique ID, e.g. the CUSIP.
+type TxOfxLoader struct {
+	Path string
+}
+
+// ofxTransaction holds the data shared by every transaction of an OFX investment statement.
+type ofxTransaction struct {
+	element  *ioutils.OfxElement
+	ts       time.Time
+	assetId  string
+	id       string
+	currency string
+}
+
+// ofxCharges lists the data elements of OFX investment transactions holding fees and taxes.
+var ofxCharges = []struct {
+	name   string
+	txType transaction.TxType
+}{
+	{"COMMISSION", transaction.FEE},
+	{"FEES", transaction.FEE},
+	{"LOAD", transaction.FEE},
+	{"TAXES", transaction.TAX},
+	{"WITHHOLDING", transaction.TAX},
+}
+
+// Load tries to parse the OFX file at Path and loads the data to the context's transaction history.
+func (l TxOfxLoader) Load(ctx *transaction.Context) error {
+	t, err := parseOfx(l.Path)
+	if err != nil {
+		return err
+	}
+
+	return ctx.AddTransactions(t)
+}
+
+// parseOfx reads the OFX file at the given path and tries to convert the investment transactions to a transaction.Tx slice.
+func parseOfx(path string) ([]transaction.Tx, error) {
+	root, err := ioutils.ReadOfxFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := ioutils.OfxTickers(root)
+	var txs []transaction.Tx
+
+	for _, statement := range root.FindAll("INVSTMTRS") {
+		defaultCurrency := statement.Text("CURDEF")
+		list := statement.Child("INVTRANLIST")
+		if list == nil {
+			continue
+		}
+
+		for _, element := range list.Children {
+			t, txErr := readOfxTransaction(element, tickers, defaultCurrency)
+			if txErr != nil {
+				return nil, txErr
+			}
+
+			txs = append(txs, t...)
+		}
+	}
+
+	if len(txs) == 0 {
+		log.Println("the OFX statement contains no investment transactions")
+	}
+
+	return txs, nil
+}
+
+// readOfxTransaction converts a single element of the INVTRANLIST of an OFX statement to transaction.Tx objects. Unsupported transactions,
+// e.g. bank transactions, are skipped.
+func readOfxTransaction(element *ioutils.OfxElement, tickers map[string]string, defaultCurrency string) ([]transaction.Tx, error) {
+	switch {
+	case element.Name == "DTSTART" || element.Name == "DTEND":
+		return nil, nil
+	case strings.HasPrefix(element.Name, "BUY"):
+		return readOfxTrade(element, element.Child("INVBUY"), transaction.BUY, tickers, defaultCurrency)
+	case strings.HasPrefix(element.Name, "SELL"):
+		return readOfxTrade(element, element.Child("INVSELL"), transaction.SELL, tickers, defaultCurrency)
+	case element.Name == "INCOME":
+		return readOfxIncome(element, tickers, defaultCurrency)
+	case element.Name == "REINVEST":
+		return readOfxReinvest(element, tickers, defaultCurrency)
+	case element.Name == "SPLIT":
+		return readOfxSplit(element, tickers, defaultCurrency)
+	default:
+		log.Printf("skipping unsupported OFX transaction \"%s\"\n", element.Name)
+		return nil, nil
+	}
+}
+
+// newOfxTransaction reads the transaction ID, timestamp, security and currency of an OFX investment transaction aggregate.
+func newOfxTransaction(element *ioutils.OfxElement, tickers map[string]string, defaultCurrency string) (ofxTransaction, error) {
+	id := element.Text("INVTRAN", "FITID")
+
+	ts, err := ioutils.ParseOfxDate(element.Text("INVTRAN", "DTTRADE"))
+	if err != nil {
+		return ofxTransaction{}, fmt.Errorf("failed to parse TS of OFX transaction %s", id)
+	}
+
+	uniqueId := element.Text("SECID", "UNIQUEID")
+	if ticker, ok := tickers[uniqueId]; ok {
+		uniqueId = ticker
+	}
+
+	assetId, err := parseAssetId(uniqueId)
+	if err != nil {
+		return ofxTransaction{}, fmt.Errorf("failed to parse asset ID of OFX transaction %s", id)
+	}
+
+	currency := element.Text("CURRENCY", "CURSYM")
+	if currency == "" {
+		currency = element.Text("ORIGCURRENCY", "CURSYM")
+	}
+	if currency == "" {
+		currency = defaultCurrency
+	}
+
+	return ofxTransaction{element: element, ts: ts, assetId: assetId, id: id, currency: currency}, nil
+}
+
+// rat reads the numeric data element at the given path. Missing elements are treated as zero.
+func (t ofxTransaction) rat(path ...string) (*big.Rat, error) {
+	s := t.element.Text(path...)
+	if s == "" {
+		return big.NewRat(0, 1), nil
+	}
+
+	r, err := ioutils.ParseRat(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s of OFX transaction %s", strings.ToLower(path[len(path)-1]), t.id)
+	}
+
+	return r, nil
+}
+
+// charges converts the commission, fees and taxes of an OFX transaction to FEE and TAX transactions. The data elements listed in skip are
+// left out, e.g. because they were booked otherwise.
+func (t ofxTransaction) charges(skip ...string) ([]transaction.Tx, error) {
+	var txs []transaction.Tx
+	for _, charge := range ofxCharges {
+		if slices.Contains(skip, charge.name) {
+			continue
+		}
+
+		amount, err := t.rat(charge.name)
+		if err != nil {
+			return nil, err
+		}
+
+		if amount.Sign() != 0 {
+			txs = append(txs, newAmountTx(t.ts, t.assetId, charge.txType, amount, t.id, t.currency))
+		}
+	}
+
+	return txs, nil
+}
+
+// readOfxTrade converts an OFX purchase or sale to a BUY or SELL transaction.Tx, followed by the charges of the trade.
+func readOfxTrade(element *ioutils.OfxElement, trade *ioutils.OfxElement, txType transaction.TxType, tickers map[string]string, defaultCurrency string) ([]transaction.Tx, error) {
+	if trade == nil {
+		return nil, fmt.Errorf("missing trade details of OFX transaction %s", element.Name)
+	}
+
+	t, err := newOfxTransaction(trade, tickers, defaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := t.rat("UNITS")
+	if err != nil {
+		return nil, err
+	}
+
+	unitPrice, err := t.rat("UNITPRICE")
+	if err != nil {
+		return nil, err
+	}
+
+	charges, err := t.charges()
+	if err != nil {
+		return nil, err
+	}
+
+	txs := []transaction.Tx{newTradeTx(t.ts, t.assetId, txType, units.Abs(units), unitPrice, t.id, t.currency)}
+	return append(txs, charges...), nil
+}
+
+// readOfxIncome converts OFX income to a DIVIDEND or INTEREST transaction.Tx, followed by its charges. The tax withheld from a dividend is
+// booked on the DIVIDEND, whose UnitPrice holds the total less the withheld tax.
+func readOfxIncome(element *ioutils.OfxElement, tickers map[string]string, defaultCurrency string) ([]transaction.Tx, error) {
+	t, err := newOfxTransaction(element, tickers, defaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := t.rat("TOTAL")
+	if err != nil {
+		return nil, err
+	}
+
+	txType := transaction.DIVIDEND
+	if element.Text("INCOMETYPE") == "INTEREST" {
+		txType = transaction.INTEREST
+	}
+
+	if txType == transaction.INTEREST {
+		charges, chargesErr := t.charges()
+		if chargesErr != nil {
+			return nil, chargesErr
+		}
+
+		return append([]transaction.Tx{newAmountTx(t.ts, t.assetId, txType, total, t.id, t.currency)}, charges...), nil
+	}
+
+	withheld, err := t.rat("WITHHOLDING")
+	if err != nil {
+		return nil, err
+	}
+
+	charges, err := t.charges("WITHHOLDING")
+	if err != nil {
+		return nil, err
+	}
+
+	isin := ""
+	if element.Text("SECID", "UNIQUEIDTYPE") == "ISIN" {
+		isin = element.Text("SECID", "UNIQUEID")
+	}
+
+	txs := []transaction.Tx{newDividendTx(t.ts, t.assetId, total, withheld, t.id, t.currency, isin)}
+	return append(txs, charges...), nil
+}
+
+// readOfxReinvest converts an OFX reinvestment to a REINVEST transaction.Tx purchasing the new units, followed by the charges of the
+// purchase. If the total exceeds the price of the new units, e.g. because the charges were paid out of the dividend, the rest is added
+// as a DIVIDEND transaction.Tx.
+func readOfxReinvest(element *ioutils.OfxElement, tickers map[string]string, defaultCurrency string) ([]transaction.Tx, error) {
+	t, err := newOfxTransaction(element, tickers, defaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := t.rat("TOTAL")
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := t.rat("UNITS")
+	if err != nil {
+		return nil, err
+	}
+
+	unitPrice, err := t.rat("UNITPRICE")
+	if err != nil {
+		return nil, err
+	}
+
+	charges, err := t.charges()
+	if err != nil {
+		return nil, err
+	}
+
+	txs := []transaction.Tx{newTradeTx(t.ts, t.assetId, transaction.REINVEST, units, unitPrice, t.id, t.currency)}
+
+	rest := big.NewRat(0, 1).Mul(units, unitPrice)
+	rest.Sub(total.Abs(total), rest)
+	if rest.Sign() > 0 {
+		txs = append(txs, newAmountTx(t.ts, t.assetId, transaction.DIVIDEND, rest, t.id, t.currency))
+	}
+
+	return append(txs, charges...), nil
+}
+
+// readOfxSplit converts an OFX split to a SPLIT transaction.Tx holding the difference between the new and the old number of units.
+func readOfxSplit(element *ioutils.OfxElement, tickers map[string]string, defaultCurrency string) ([]transaction.Tx, error) {
+	t, err := newOfxTransaction(element, tickers, defaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	if element.Text("OLDUNITS") == "" || element.Text("NEWUNITS") == "" {
+		return nil, fmt.Errorf("missing units of OFX split %s", t.id)
+	}
+
+	oldUnits, err := t.rat("OLDUNITS")
+	if err != nil {
+		return nil, err
+	}
+
+	newUnits, err := t.rat("NEWUNITS")
+	if err != nil {
+		return nil, err
+	}
+
+	delta := big.NewRat(0, 1).Sub(newUnits, oldUnits)
+	return []transaction.Tx{newTradeTx(t.ts, t.assetId, transaction.SPLIT, delta, big.NewRat(0, 1), t.id, t.currency)}, nil
+}
@@ -0,0 +1,91 @@
+package io_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"testing"
+)
+
+// TestTxOfxLoader_Load is a smoke-test for an SGML based OFX 1.x investment statement.
+func TestTxOfxLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxOfxLoader{Path: "../../../test/data/io/ofx/smoke.ofx"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ctx.Assets))
+	assert.Equal(t, 8, len(ctx.Transactions))
+	assert.Equal(t, map[string]*big.Rat{"AAPL": big.NewRat(24, 1), "VOO": big.NewRat(201, 100)}, ctx.GetAssetKeyMap())
+	assert.Equal(t, "T-1", ctx.Transactions[0].Id)
+	assert.Equal(t, "USD", ctx.Transactions[0].Currency)
+}
+
+// TestTxOfxLoader_Load_Income tests that income, withholding and reinvestments are imported. The withheld tax is booked on the dividend.
+func TestTxOfxLoader_Load_Income(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxOfxLoader{Path: "../../../test/data/io/ofx/smoke.ofx"}
+	err := loader.Load(&ctx)
+	assert.Nil(t, err)
+
+	types := make([]transaction.TxType, 0, len(ctx.Transactions))
+	for _, tx := range ctx.Transactions {
+		types = append(types, tx.Type)
+	}
+
+	assert.Equal(t, []transaction.TxType{
+		transaction.BUY, transaction.FEE, transaction.BUY, transaction.DIVIDEND, transaction.REINVEST, transaction.SELL, transaction.FEE,
+		transaction.SPLIT,
+	}, types)
+	assert.Equal(t, big.NewRat(51, 25), ctx.Transactions[3].UnitPrice)
+	assert.Equal(t, big.NewRat(12, 5), ctx.Transactions[3].Gross)
+	assert.Equal(t, big.NewRat(9, 25), ctx.Transactions[3].WithheldTax)
+	assert.Equal(t, big.NewRat(1, 100), ctx.Transactions[4].Quantity)
+	assert.Equal(t, big.NewRat(440, 1), ctx.Transactions[4].UnitPrice)
+
+	voo, _ := ctx.GetAsset("VOO")
+	assert.Equal(t, big.NewRat(22, 5), ctx.GetDividendIncome(voo, ctx.Transactions[4].Timestamp, ctx.Transactions[4].Timestamp))
+}
+
+// TestTxOfxLoader_Load_V2 tests loading an XML based OFX 2.x investment statement without a security list.
+func TestTxOfxLoader_Load_V2(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxOfxLoader{Path: "../../../test/data/io/ofx/smoke_v2.ofx"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ctx.Transactions))
+	assert.Equal(t, "IE00B4L5Y983", ctx.Transactions[0].Asset.Id)
+	assert.Equal(t, "EUR", ctx.Transactions[0].Currency)
+	assert.Equal(t, big.NewRat(171, 2), ctx.Transactions[0].UnitPrice)
+}
+
+// TestTxOfxLoader_Load_Missing_File tests loading a non-existing file.
+func TestTxOfxLoader_Load_Missing_File(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxOfxLoader{Path: "../../../test/data/io/###.ofx"}
+	err := loader.Load(&ctx)
+
+	assert.Equal(t, fmt.Errorf("failed to open file \"../../../test/data/io/###.ofx\""), err)
+}
+
+// TestTxOfxLoader_Load_Not_Ofx tests loading a file that is not an OFX document.
+func TestTxOfxLoader_Load_Not_Ofx(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxOfxLoader{Path: "../../../test/data/io/transactions/smoke.csv"}
+	err := loader.Load(&ctx)
+
+	assert.EqualError(t, err, "missing OFX root element")
+}
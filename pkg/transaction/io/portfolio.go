@@ -0,0 +1,71 @@
+package io
+
+import (
+	"github.com/wlachs/wstonks/pkg/asset"
+	"github.com/wlachs/wstonks/pkg/portfolio"
+	"github.com/wlachs/wstonks/pkg/transaction"
+)
+
+var (
+	_ TransactionWriter = TxJsonWriter{}
+	_ TransactionWriter = TxYamlWriter{}
+)
+
+// TxJsonLoader implements the TransactionLoader interface to allow importing context data from a JSON portfolio document.
+type TxJsonLoader struct {
+	Path string
+}
+
+// TxYamlLoader implements the TransactionLoader interface to allow importing context data from a YAML portfolio document.
+type TxYamlLoader struct {
+	Path string
+}
+
+// TxJsonWriter implements the TransactionWriter interface to allow exporting the context data to a JSON portfolio document. Since a
+// portfolio document holds the live assets as well, they can be written to the same document by setting Assets.
+type TxJsonWriter struct {
+	Path   string
+	Assets *asset.Context
+}
+
+// TxYamlWriter implements the TransactionWriter interface to allow exporting the context data to a YAML portfolio document. Since a
+// portfolio document holds the live assets as well, they can be written to the same document by setting Assets.
+type TxYamlWriter struct {
+	Path   string
+	Assets *asset.Context
+}
+
+// Load tries to parse the JSON portfolio document at Path and loads the transactions to the context's transaction history.
+func (l TxJsonLoader) Load(ctx *transaction.Context) error {
+	return loadPortfolio(ctx, l.Path, portfolio.JSON)
+}
+
+// Load tries to parse the YAML portfolio document at Path and loads the transactions to the context's transaction history.
+func (l TxYamlLoader) Load(ctx *transaction.Context) error {
+	return loadPortfolio(ctx, l.Path, portfolio.YAML)
+}
+
+// loadPortfolio reads the portfolio document in the given format and adds its transactions to the context.
+func loadPortfolio(ctx *transaction.Context, path string, format portfolio.Format) error {
+	d, err := portfolio.ReadFile(path, format)
+	if err != nil {
+		return err
+	}
+
+	t, err := d.GetTransactions()
+	if err != nil {
+		return err
+	}
+
+	return ctx.AddTransactions(t)
+}
+
+// Write creates the JSON portfolio document at Path from the context and the optional Assets.
+func (w TxJsonWriter) Write(ctx *transaction.Context) error {
+	return portfolio.JsonWriter{Path: w.Path}.Write(ctx, w.Assets)
+}
+
+// Write creates the YAML portfolio document at Path from the context and the optional Assets.
+func (w TxYamlWriter) Write(ctx *transaction.Context) error {
+	return portfolio.YamlWriter{Path: w.Path}.Write(ctx, w.Assets)
+}
@@ -0,0 +1,156 @@
+package io
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// TxScalableCsvLoader implements the TransactionLoader interface to allow importing context data from a Scalable Capital broker
+// transactions CSV export. The export is semicolon separated and uses ',' as decimal separator. Orders, savings plan executions,
+// distributions, fees, taxes, interest, deposits and withdrawals are supported, rows that have not been executed are skipped.
+type TxScalableCsvLoader struct {
+	Path string
+}
+
+// Load tries to parse the Scalable Capital CSV file at Path and loads the data to the context's transaction history.
+func (l TxScalableCsvLoader) Load(ctx *transaction.Context) error {
+	t, err := parseScalableCsv(l.Path)
+	if err != nil {
+		return err
+	}
+
+	return ctx.AddTransactions(t)
+}
+
+// parseScalableCsv reads the Scalable Capital CSV file at the given path and tries to convert it to a transaction.Tx slice.
+func parseScalableCsv(path string) ([]transaction.Tx, error) {
+	fileContent, err := ioutils.ReadCsvFileWithComma(path, ';')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fileContent) == 0 {
+		log.Println("the CSV file is empty")
+		return []transaction.Tx{}, nil
+	}
+
+	header := newCsvHeader(fileContent[0])
+	if !header.has("date", "time", "status", "type", "isin", "shares", "price", "amount", "currency") {
+		return nil, fmt.Errorf("unsupported Scalable Capital CSV header %v", fileContent[0])
+	}
+
+	tradeHistory := make([]transaction.Tx, 0, len(fileContent))
+	for _, row := range fileContent[1:] {
+		t, rowErr := readScalableCsvRow(header, row)
+		if rowErr != nil {
+			return nil, rowErr
+		}
+
+		tradeHistory = append(tradeHistory, t...)
+	}
+
+	return tradeHistory, nil
+}
+
+// readScalableCsvRow converts a single entry of the Scalable Capital CSV file to transaction.Tx objects. Trades and distributions are
+// followed by FEE and TAX transactions if fees or taxes were charged on them.
+func readScalableCsvRow(header csvHeader, row []string) ([]transaction.Tx, error) {
+	if !strings.EqualFold(header.get(row, "status"), "Executed") {
+		return nil, nil
+	}
+
+	ts, err := time.Parse("2006-01-02 15:04:05", header.get(row, "date")+" "+header.get(row, "time"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TS of row %v", row)
+	}
+
+	amount, err := parseOptionalRat(header.get(row, "amount"), ',')
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse amount of row %v", row)
+	}
+
+	fee, err := parseOptionalRat(header.get(row, "fee"), ',')
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fee of row %v", row)
+	}
+
+	tax, err := parseOptionalRat(header.get(row, "tax"), ',')
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tax of row %v", row)
+	}
+
+	id := header.get(row, "reference")
+	currency := header.get(row, "currency")
+	assetId := header.get(row, "isin")
+	if assetId == "" {
+		assetId = currency
+	}
+
+	assetId, err = parseAssetId(assetId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse asset ID of row %v", row)
+	}
+
+	var txs []transaction.Tx
+	switch strings.ToLower(header.get(row, "type")) {
+	case "buy", "savings plan", "sell":
+		t, tradeErr := readScalableTrade(header, row, ts, assetId, id, currency)
+		if tradeErr != nil {
+			return nil, tradeErr
+		}
+		txs = append(txs, t)
+	case "distribution", "dividend":
+		/* The amount is credited after taxes, which were withheld from the dividend. */
+		withheld := big.NewRat(0, 1).Abs(tax)
+		txs = append(txs, newDividendTx(ts, assetId, big.NewRat(0, 1).Add(amount, withheld), withheld, id, currency, assetId))
+		tax = big.NewRat(0, 1)
+	case "interest":
+		txs = append(txs, newAmountTx(ts, assetId, transaction.INTEREST, amount, id, currency))
+	case "deposit":
+		txs = append(txs, newAmountTx(ts, assetId, transaction.DEPOSIT, amount.Abs(amount), id, currency))
+	case "withdrawal":
+		txs = append(txs, newAmountTx(ts, assetId, transaction.WITHDRAWAL, amount.Abs(amount), id, currency))
+	case "fee":
+		return []transaction.Tx{newAmountTx(ts, assetId, transaction.FEE, amount.Neg(amount), id, currency)}, nil
+	case "taxes", "tax":
+		return []transaction.Tx{newAmountTx(ts, assetId, transaction.TAX, amount.Neg(amount), id, currency)}, nil
+	default:
+		log.Printf("skipping unsupported Scalable Capital type \"%s\"\n", header.get(row, "type"))
+		return nil, nil
+	}
+
+	if fee.Sign() != 0 {
+		txs = append(txs, newAmountTx(ts, assetId, transaction.FEE, fee.Abs(fee), id, currency))
+	}
+
+	if tax.Sign() != 0 {
+		txs = append(txs, newAmountTx(ts, assetId, transaction.TAX, tax.Abs(tax), id, currency))
+	}
+
+	return txs, nil
+}
+
+// readScalableTrade converts a Scalable Capital order or savings plan row to a BUY or SELL transaction.Tx.
+func readScalableTrade(header csvHeader, row []string, ts time.Time, assetId string, id string, currency string) (transaction.Tx, error) {
+	quantity, err := ioutils.ParseLocalizedRat(header.get(row, "shares"), ',')
+	if err != nil {
+		return transaction.Tx{}, fmt.Errorf("failed to parse quantity of row %v", row)
+	}
+
+	unitPrice, err := ioutils.ParseLocalizedRat(header.get(row, "price"), ',')
+	if err != nil {
+		return transaction.Tx{}, fmt.Errorf("failed to parse unit price of row %v", row)
+	}
+
+	txType := transaction.BUY
+	if strings.EqualFold(header.get(row, "type"), "sell") {
+		txType = transaction.SELL
+	}
+
+	return newTradeTx(ts, assetId, txType, quantity.Abs(quantity), unitPrice, id, currency), nil
+}
@@ -0,0 +1,53 @@
+package io_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"testing"
+)
+
+// TestTxScalableCsvLoader_Load is a smoke-test for a Scalable Capital transactions export.
+func TestTxScalableCsvLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxScalableCsvLoader{Path: "../../../test/data/io/transactions/scalable/smoke.csv"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ctx.Assets))
+	assert.Equal(t, 9, len(ctx.Transactions))
+	assert.Equal(t, big.NewRat(8581395, 1000000), ctx.GetAssetKeyMap()["IE00B4L5Y983"])
+}
+
+// TestTxScalableCsvLoader_Load_Distribution tests that distributions are imported with their net amount, the gross amount and the
+// deducted tax.
+func TestTxScalableCsvLoader_Load_Distribution(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxScalableCsvLoader{Path: "../../../test/data/io/transactions/scalable/smoke.csv"}
+	err := loader.Load(&ctx)
+	assert.Nil(t, err)
+
+	dividend := ctx.Transactions[4]
+	assert.Equal(t, transaction.DIVIDEND, dividend.Type)
+	assert.Equal(t, big.NewRat(7, 2), dividend.UnitPrice)
+	assert.Equal(t, big.NewRat(221, 50), dividend.Gross)
+	assert.Equal(t, big.NewRat(23, 25), dividend.WithheldTax)
+	assert.Equal(t, "IE", dividend.Country)
+	assert.Equal(t, "DIV-1", dividend.Id)
+}
+
+// TestTxScalableCsvLoader_Load_Wrong_Format tests loading a CSV file of a different format.
+func TestTxScalableCsvLoader_Load_Wrong_Format(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxScalableCsvLoader{Path: "../../../test/data/io/transactions/trading212/smoke.csv"}
+	err := loader.Load(&ctx)
+
+	assert.NotNil(t, err)
+}
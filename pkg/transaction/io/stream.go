@@ -0,0 +1,76 @@
+package io
+
+import (
+	"context"
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"io"
+)
+
+// defaultProgressInterval is the number of rows between two progress reports if TxCsvStreamLoader.ProgressInterval is not set.
+const defaultProgressInterval = 10000
+
+// Progress describes how far a streaming import has got.
+type Progress struct {
+	// Rows is the number of CSV rows read so far.
+	Rows int
+	// Bytes is the number of bytes read from the input so far, e.g. to compare against the size of the imported file.
+	Bytes int64
+}
+
+// TxCsvStreamLoader implements the TransactionLoader interface to allow importing very large transaction histories in the format of
+// TxCsvLoader. Rows are parsed and added to the context one by one, so neither the CSV content nor the parsed transactions are held in
+// memory besides the context itself. If the import fails, the context holds the transactions of the rows read before the failure.
+type TxCsvStreamLoader struct {
+	Reader io.Reader
+	// Progress is called every ProgressInterval rows and once after the last row, if set. The final report is sent even if there were no
+	// rows at all, so it can be used to detect the end of the import.
+	Progress func(p Progress)
+	// ProgressInterval is the number of rows between two progress reports. If zero, a report is sent every 10000 rows.
+	ProgressInterval int
+}
+
+// Load reads the CSV content of Reader and adds the transactions to the context's transaction history row by row.
+func (l TxCsvStreamLoader) Load(ctx *transaction.Context) error {
+	return l.LoadContext(context.Background(), ctx)
+}
+
+// LoadContext reads the CSV content of Reader and adds the transactions to the context's transaction history row by row. The import
+// stops as soon as c is cancelled and the error of c is returned.
+func (l TxCsvStreamLoader) LoadContext(c context.Context, ctx *transaction.Context) error {
+	interval := l.ProgressInterval
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+
+	importer := ctx.NewImporter()
+	progress := Progress{}
+
+	err := ioutils.ReadCsvStream(c, l.Reader, func(row []string, offset int64) error {
+		t, err := readCsvRow(row)
+		if err != nil {
+			return err
+		}
+
+		if err = importer.Add(t); err != nil {
+			return err
+		}
+
+		progress = Progress{Rows: progress.Rows + 1, Bytes: offset}
+		if l.Progress != nil && progress.Rows%interval == 0 {
+			l.Progress(progress)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	/* The final report is skipped only if the last row was reported already. */
+	if l.Progress != nil && (progress.Rows == 0 || progress.Rows%interval != 0) {
+		l.Progress(progress)
+	}
+
+	return importer.Finish()
+}
@@ -0,0 +1,153 @@
+package io_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"github.com/wlachs/wstonks/pkg/transaction/io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// syntheticCsv generates a transaction CSV with the given number of alternating BUY and SELL rows over three assets.
+func syntheticCsv(rows int) string {
+	var sb strings.Builder
+	for i := 0; i < rows; i++ {
+		txType := "BUY"
+		if i%2 == 1 {
+			txType = "SELL"
+		}
+		_, _ = fmt.Fprintf(&sb, "%d,%c,%s,1,%d\n", 1712000000000+int64(i), 'A'+rune(i/2%3), txType, 100+i%7)
+	}
+
+	return sb.String()
+}
+
+// TestTxCsvStreamLoader_Load tests that streaming the smoke-test CSV yields the same context as TxCsvLoader.
+func TestTxCsvStreamLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("../../../test/data/io/transactions/smoke.csv")
+	assert.Nil(t, err)
+	defer func() { _ = f.Close() }()
+
+	ctx := transaction.Context{}
+	loader := io.TxCsvStreamLoader{Reader: f}
+	err = loader.Load(&ctx)
+	assert.Nil(t, err)
+
+	expected := transaction.Context{}
+	err = io.TxCsvLoader{Path: "../../../test/data/io/transactions/smoke.csv"}.Load(&expected)
+	assert.Nil(t, err)
+
+	assert.Equal(t, expected.GetAssetKeyMap(), ctx.GetAssetKeyMap())
+	assert.Equal(t, len(expected.Transactions), len(ctx.Transactions))
+}
+
+// TestTxCsvStreamLoader_Load_Progress tests that progress is reported every ProgressInterval rows and after the last row.
+func TestTxCsvStreamLoader_Load_Progress(t *testing.T) {
+	t.Parallel()
+
+	content := syntheticCsv(2500)
+	var reports []io.Progress
+
+	ctx := transaction.Context{}
+	loader := io.TxCsvStreamLoader{
+		Reader:           strings.NewReader(content),
+		Progress:         func(p io.Progress) { reports = append(reports, p) },
+		ProgressInterval: 1000,
+	}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2500, len(ctx.Transactions))
+	assert.Equal(t, 3, len(ctx.Assets))
+	assert.Equal(t, []int{1000, 2000, 2500}, []int{reports[0].Rows, reports[1].Rows, reports[2].Rows})
+	assert.Equal(t, int64(len(content)), reports[2].Bytes)
+	assert.Empty(t, ctx.GetAssetKeyMap())
+}
+
+// TestTxCsvStreamLoader_Load_Progress_Empty tests that the final progress is reported for an empty file as well.
+func TestTxCsvStreamLoader_Load_Progress_Empty(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("../../../test/data/io/empty.csv")
+	assert.Nil(t, err)
+	defer func() { _ = f.Close() }()
+
+	var reports []io.Progress
+
+	ctx := transaction.Context{}
+	loader := io.TxCsvStreamLoader{
+		Reader:   f,
+		Progress: func(p io.Progress) { reports = append(reports, p) },
+	}
+	err = loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []io.Progress{{}}, reports)
+	assert.Empty(t, ctx.Transactions)
+}
+
+// TestTxCsvStreamLoader_Load_Progress_Interval tests that the last row is reported exactly once if it completes an interval.
+func TestTxCsvStreamLoader_Load_Progress_Interval(t *testing.T) {
+	t.Parallel()
+
+	var reports []io.Progress
+
+	ctx := transaction.Context{}
+	loader := io.TxCsvStreamLoader{
+		Reader:           strings.NewReader(syntheticCsv(200)),
+		Progress:         func(p io.Progress) { reports = append(reports, p) },
+		ProgressInterval: 100,
+	}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Len(t, reports, 2)
+	assert.Equal(t, 200, reports[1].Rows)
+}
+
+// TestTxCsvStreamLoader_LoadContext_Cancel tests that the import stops once the context is cancelled.
+func TestTxCsvStreamLoader_LoadContext_Cancel(t *testing.T) {
+	t.Parallel()
+
+	c, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx := transaction.Context{}
+	loader := io.TxCsvStreamLoader{
+		Reader:           strings.NewReader(syntheticCsv(1000)),
+		Progress:         func(p io.Progress) { cancel() },
+		ProgressInterval: 100,
+	}
+	err := loader.LoadContext(c, &ctx)
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 100, len(ctx.Transactions))
+}
+
+// TestTxCsvStreamLoader_Load_No_Type tests streaming malformed CSV content.
+func TestTxCsvStreamLoader_Load_No_Type(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxCsvStreamLoader{Reader: strings.NewReader("1712000000000,A,BUY,10,110\n1712200000000,A,,1.23456789,50.12345\n")}
+	err := loader.Load(&ctx)
+
+	assert.Equal(t, fmt.Errorf("failed to parse trade type of row [1712200000000 A  1.23456789 50.12345]"), err)
+	assert.Equal(t, 1, len(ctx.Transactions))
+}
+
+// TestTxCsvStreamLoader_Load_Negative_Quantity tests that the context is validated after the import.
+func TestTxCsvStreamLoader_Load_Negative_Quantity(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxCsvStreamLoader{Reader: strings.NewReader("1712000000000,A,BUY,1,110\n1712100000000,A,SELL,2,120\n")}
+	err := loader.Load(&ctx)
+
+	assert.Equal(t, fmt.Errorf("negative asset quantity A: -1.000000 < 0"), err)
+}
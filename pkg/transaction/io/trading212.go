@@ -0,0 +1,216 @@
+package io
+
+import (
+	"fmt"
+	"github.com/wlachs/wstonks/pkg/ioutils"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// TxTrading212CsvLoader implements the TransactionLoader interface to allow importing context data from a Trading 212 history CSV export.
+// Orders, dividends, interest, deposits, withdrawals and the fees and taxes charged on orders are supported.
+type TxTrading212CsvLoader struct {
+	Path string
+}
+
+// trading212Charges lists the columns of the Trading 212 export holding fees and taxes charged on orders.
+var trading212Charges = []struct {
+	column string
+	txType transaction.TxType
+}{
+	{"Currency conversion fee", transaction.FEE},
+	{"Finra fee", transaction.FEE},
+	{"Transaction fee", transaction.FEE},
+	{"Stamp duty reserve tax", transaction.TAX},
+	{"Stamp duty", transaction.TAX},
+	{"French transaction tax", transaction.TAX},
+	{"PTM levy", transaction.TAX},
+}
+
+// Load tries to parse the Trading 212 CSV file at Path and loads the data to the context's transaction history.
+func (l TxTrading212CsvLoader) Load(ctx *transaction.Context) error {
+	t, err := parseTrading212Csv(l.Path)
+	if err != nil {
+		return err
+	}
+
+	return ctx.AddTransactions(t)
+}
+
+// parseTrading212Csv reads the Trading 212 CSV file at the given path and tries to convert it to a transaction.Tx slice.
+func parseTrading212Csv(path string) ([]transaction.Tx, error) {
+	fileContent, err := ioutils.ReadCsvFileWithComma(path, ',')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fileContent) == 0 {
+		log.Println("the CSV file is empty")
+		return []transaction.Tx{}, nil
+	}
+
+	header := newCsvHeader(fileContent[0])
+	if !header.has("Action", "Time", "Total") {
+		return nil, fmt.Errorf("unsupported Trading 212 CSV header %v", fileContent[0])
+	}
+
+	tradeHistory := make([]transaction.Tx, 0, len(fileContent))
+	for _, row := range fileContent[1:] {
+		t, rowErr := readTrading212CsvRow(header, row)
+		if rowErr != nil {
+			return nil, rowErr
+		}
+
+		tradeHistory = append(tradeHistory, t...)
+	}
+
+	return tradeHistory, nil
+}
+
+// readTrading212CsvRow converts a single entry of the Trading 212 CSV file to transaction.Tx objects. Rows of unsupported actions, e.g.
+// currency conversions, are skipped.
+func readTrading212CsvRow(header csvHeader, row []string) ([]transaction.Tx, error) {
+	ts, err := time.Parse("2006-01-02 15:04:05", header.get(row, "Time"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TS of row %v", row)
+	}
+
+	id := header.get(row, "ID")
+	total, err := parseOptionalRat(header.get(row, "Total"), '.')
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse total of row %v", row)
+	}
+
+	totalCurrency := header.get(row, "Currency (Total)")
+	action := strings.ToLower(header.get(row, "Action"))
+
+	switch {
+	case strings.HasSuffix(action, "buy"), strings.HasSuffix(action, "sell"):
+		return readTrading212Order(header, row, ts, id, action)
+	case strings.HasPrefix(action, "dividend"):
+		return readTrading212Dividend(header, row, ts, id, total, totalCurrency)
+	case action == "deposit":
+		return []transaction.Tx{newAmountTx(ts, totalCurrency, transaction.DEPOSIT, total, id, totalCurrency)}, nil
+	case action == "withdrawal":
+		return []transaction.Tx{newAmountTx(ts, totalCurrency, transaction.WITHDRAWAL, total.Abs(total), id, totalCurrency)}, nil
+	case strings.HasPrefix(action, "interest"), action == "lending interest":
+		return []transaction.Tx{newAmountTx(ts, totalCurrency, transaction.INTEREST, total, id, totalCurrency)}, nil
+	default:
+		log.Printf("skipping unsupported Trading 212 action \"%s\"\n", header.get(row, "Action"))
+		return nil, nil
+	}
+}
+
+// readTrading212Order converts a Trading 212 order row to a BUY or SELL transaction.Tx, followed by the fees and taxes charged on it.
+func readTrading212Order(header csvHeader, row []string, ts time.Time, id string, action string) ([]transaction.Tx, error) {
+	assetId, err := parseAssetId(trading212AssetId(header, row))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse asset ID of row %v", row)
+	}
+
+	quantity, err := ioutils.ParseLocalizedRat(header.get(row, "No. of shares"), '.')
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse quantity of row %v", row)
+	}
+
+	unitPrice, err := ioutils.ParseLocalizedRat(header.get(row, "Price / share"), '.')
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse unit price of row %v", row)
+	}
+
+	txType := transaction.BUY
+	if strings.HasSuffix(action, "sell") {
+		txType = transaction.SELL
+	}
+
+	txs := []transaction.Tx{newTradeTx(ts, assetId, txType, quantity, unitPrice, id, header.get(row, "Currency (Price / share)"))}
+
+	charges, err := readTrading212Charges(header, row, ts, assetId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(txs, charges...), nil
+}
+
+// readTrading212Charges converts the non-zero fee and tax columns of a Trading 212 row to FEE and TAX transactions.
+func readTrading212Charges(header csvHeader, row []string, ts time.Time, assetId string, id string) ([]transaction.Tx, error) {
+	var txs []transaction.Tx
+	for _, charge := range trading212Charges {
+		amount, err := parseOptionalRat(header.get(row, charge.column), '.')
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s of row %v", strings.ToLower(charge.column), row)
+		}
+
+		if amount.Sign() != 0 {
+			currency := header.get(row, fmt.Sprintf("Currency (%s)", charge.column))
+			txs = append(txs, newAmountTx(ts, assetId, charge.txType, amount.Abs(amount), id, currency))
+		}
+	}
+
+	return txs, nil
+}
+
+// readTrading212Dividend converts a Trading 212 dividend row to a DIVIDEND transaction.Tx holding the net amount, the gross amount and the
+// withheld tax. The withheld tax is given in the currency of the instrument, while the total is the net amount in the
+// currency of the account, so the gross amount is calculated in the currency of the instrument from the number of shares and the dividend
+// per share. If those are missing, the total is converted at the exchange rate of the row instead.
+func readTrading212Dividend(header csvHeader, row []string, ts time.Time, id string, net *big.Rat, currency string) ([]transaction.Tx, error) {
+	assetId, err := parseAssetId(trading212AssetId(header, row))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse asset ID of row %v", row)
+	}
+
+	withheld, err := parseOptionalRat(header.get(row, "Withholding tax"), '.')
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse withholding tax of row %v", row)
+	}
+	withheld.Abs(withheld)
+
+	gross, grossCurrency, err := trading212GrossDividend(header, row, net, currency, withheld)
+	if err != nil {
+		return nil, err
+	}
+
+	return []transaction.Tx{newDividendTx(ts, assetId, gross, withheld, id, grossCurrency, header.get(row, "ISIN"))}, nil
+}
+
+// trading212GrossDividend calculates the gross amount of a Trading 212 dividend row and returns it with its currency. The gross amount is
+// the number of shares times the dividend per share, or the net total converted to the currency of the instrument plus the withheld tax.
+func trading212GrossDividend(header csvHeader, row []string, net *big.Rat, currency string, withheld *big.Rat) (*big.Rat, string, error) {
+	instrumentCurrency := header.get(row, "Currency (Price / share)")
+	if instrumentCurrency == "" {
+		instrumentCurrency = header.get(row, "Currency (Withholding tax)")
+	}
+
+	quantity, quantityErr := ioutils.ParseLocalizedRat(header.get(row, "No. of shares"), '.')
+	perShare, perShareErr := ioutils.ParseLocalizedRat(header.get(row, "Price / share"), '.')
+	if quantityErr == nil && perShareErr == nil && instrumentCurrency != "" {
+		return big.NewRat(0, 1).Mul(quantity, perShare), instrumentCurrency, nil
+	}
+
+	if withheld.Sign() == 0 || instrumentCurrency == "" || instrumentCurrency == currency {
+		return big.NewRat(0, 1).Add(net, withheld), currency, nil
+	}
+
+	/* The exchange rate is given in units of the instrument currency per unit of the account currency. */
+	rate, err := ioutils.ParseLocalizedRat(header.get(row, "Exchange rate"), '.')
+	if err != nil || rate.Sign() <= 0 {
+		return nil, "", fmt.Errorf("failed to convert the dividend of row %v to %s", row, instrumentCurrency)
+	}
+
+	gross := big.NewRat(0, 1).Mul(net, rate)
+	return gross.Add(gross, withheld), instrumentCurrency, nil
+}
+
+// trading212AssetId returns the ticker of the row. If the ticker is missing, the ISIN is used instead.
+func trading212AssetId(header csvHeader, row []string) string {
+	if ticker := header.get(row, "Ticker"); ticker != "" {
+		return ticker
+	}
+
+	return header.get(row, "ISIN")
+}
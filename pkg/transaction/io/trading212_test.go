@@ -0,0 +1,82 @@
+package io_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"testing"
+)
+
+// TestTxTrading212CsvLoader_Load is a smoke-test for a Trading 212 history export.
+func TestTxTrading212CsvLoader_Load(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxTrading212CsvLoader{Path: "../../../test/data/io/transactions/trading212/smoke.csv"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(ctx.Assets))
+	assert.Equal(t, 10, len(ctx.Transactions))
+	assert.Equal(t, map[string]*big.Rat{"AAPL": big.NewRat(3, 2), "DGE": big.NewRat(10, 1)}, ctx.GetAssetKeyMap())
+}
+
+// TestTxTrading212CsvLoader_Load_Dividend tests that dividends are imported with their net amount, the gross amount and the withheld tax
+// in the currency of the instrument.
+func TestTxTrading212CsvLoader_Load_Dividend(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxTrading212CsvLoader{Path: "../../../test/data/io/transactions/trading212/smoke.csv"}
+	err := loader.Load(&ctx)
+	assert.Nil(t, err)
+
+	dividend := ctx.Transactions[5]
+
+	assert.Equal(t, transaction.DIVIDEND, dividend.Type)
+	assert.Equal(t, big.NewRat(51, 100), dividend.Gross, "2.5 shares times 0.204 USD")
+	assert.Equal(t, big.NewRat(9, 100), dividend.WithheldTax)
+	assert.Equal(t, big.NewRat(42, 100), dividend.UnitPrice)
+	assert.Equal(t, "USD", dividend.Currency)
+	assert.Equal(t, "US", dividend.Country)
+}
+
+// TestTxTrading212CsvLoader_Load_Dividend_Exchange_Rate tests that the net total of a dividend without dividend per share is converted to
+// the currency of the withheld tax before adding it up.
+func TestTxTrading212CsvLoader_Load_Dividend_Exchange_Rate(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxTrading212CsvLoader{Path: "../../../test/data/io/transactions/trading212/dividend_rate.csv"}
+	err := loader.Load(&ctx)
+	assert.Nil(t, err)
+
+	dividend := ctx.Transactions[0]
+	assert.Equal(t, big.NewRat(103, 10), dividend.Gross, "8 EUR at 1.10 USD/EUR plus 1.50 USD")
+	assert.Equal(t, big.NewRat(88, 10), dividend.UnitPrice)
+	assert.Equal(t, "USD", dividend.Currency)
+}
+
+// TestTxTrading212CsvLoader_Load_Empty tests loading an empty CSV file.
+func TestTxTrading212CsvLoader_Load_Empty(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxTrading212CsvLoader{Path: "../../../test/data/io/empty.csv"}
+	err := loader.Load(&ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ctx.Transactions))
+}
+
+// TestTxTrading212CsvLoader_Load_Wrong_Format tests loading a CSV file of a different format.
+func TestTxTrading212CsvLoader_Load_Wrong_Format(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	loader := io.TxTrading212CsvLoader{Path: "../../../test/data/io/transactions/scalable/smoke.csv"}
+	err := loader.Load(&ctx)
+
+	assert.NotNil(t, err)
+}
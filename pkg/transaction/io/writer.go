@@ -0,0 +1,11 @@
+package io
+
+import (
+	"github.com/wlachs/wstonks/pkg/transaction"
+)
+
+// TransactionWriter interface to allow exporting the context data of transaction.Context.
+type TransactionWriter interface {
+	// Write saves the context data to an arbitrary destination.
+	Write(ctx *transaction.Context) error
+}
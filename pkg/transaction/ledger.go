@@ -0,0 +1,133 @@
+package transaction
+
+import (
+	"math/big"
+	"slices"
+	"time"
+)
+
+// ledger holds the state of an asset after replaying its transactions in chronological order: the open positions, the owned quantity
+// and the realized profits and losses of sales and dividends. It is updated incrementally as long as transactions are added in
+// chronological order.
+type ledger struct {
+	// count is the number of transactions of the asset applied to the ledger.
+	count int
+	// last is the timestamp of the latest transaction applied to the ledger.
+	last      time.Time
+	positions []Position
+	quantity  *big.Rat
+	realized  []*big.Rat
+	// sales holds the realized profits and losses of the SELL transactions only.
+	sales []*big.Rat
+	// worth is the initial worth of the open positions.
+	worth *big.Rat
+}
+
+// newLedger creates an empty ledger.
+func newLedger() *ledger {
+	return &ledger{quantity: big.NewRat(0, 1), worth: big.NewRat(0, 1)}
+}
+
+// apply updates the ledger with the next transaction of the asset.
+func (l *ledger) apply(transaction *Tx) {
+	switch transaction.Type {
+	case BUY:
+		l.positions = append(l.positions, transaction.Clone())
+		l.quantity.Add(l.quantity, transaction.Quantity)
+		l.worth.Add(l.worth, big.NewRat(0, 1).Mul(transaction.Quantity, transaction.UnitPrice))
+	case REINVEST:
+		/* The reinvested units are a new position like a BUY, while the reinvested amount is income like a DIVIDEND. */
+		amount := big.NewRat(0, 1).Mul(transaction.Quantity, transaction.UnitPrice)
+		l.positions = append(l.positions, transaction.Clone())
+		l.quantity.Add(l.quantity, transaction.Quantity)
+		l.worth.Add(l.worth, amount)
+		l.realized = append(l.realized, amount)
+	case SELL:
+		var diffs []*big.Rat
+		l.positions, diffs = subtractAssetPosition(l.positions, transaction.Clone())
+		l.realized = append(l.realized, diffs...)
+		l.sales = append(l.sales, diffs...)
+		l.quantity.Sub(l.quantity, transaction.Quantity)
+		l.worth = initialWorth(l.positions)
+	case SPLIT:
+		/* A split keeps the cost basis of the positions, so the initial worth does not change. */
+		l.positions = splitAssetPositions(l.positions, transaction.Quantity)
+		l.quantity.Add(l.quantity, transaction.Quantity)
+	case DIVIDEND:
+		l.realized = append(l.realized, transaction.UnitPrice)
+	default:
+		// not relevant
+	}
+
+	l.count++
+	l.last = transaction.Timestamp
+}
+
+// initialWorth calculates the initial worth of the positions.
+func initialWorth(positions []Position) *big.Rat {
+	worth := big.NewRat(0, 1)
+	for _, p := range positions {
+		w := big.NewRat(0, 1)
+		w.Mul(p.Quantity, p.UnitPrice)
+		worth.Add(worth, w)
+	}
+
+	return worth
+}
+
+// assetLedger returns the up-to-date ledger of the asset. If there is none yet, or the transactions of the asset were modified without
+// the Context noticing, the ledger is rebuilt from scratch. The transactions of the asset are kept in chronological order when they are
+// added; if they were reordered directly, a sorted copy is replayed, since readers must not modify them. The caller must hold at least a
+// read lock on the Context and must not modify the ledger.
+func (ctx *Context) assetLedger(a *TxAsset) *ledger {
+	ctx.cacheMu.Lock()
+	defer ctx.cacheMu.Unlock()
+
+	if l, ok := ctx.ledgers[a]; ok && l.count == len(a.Transactions) {
+		return l
+	}
+
+	transactions := a.Transactions
+	if !slices.IsSortedFunc(transactions, compareTx) {
+		// sort a copy according to timestamp, keeping the insertion order of simultaneous transactions
+		transactions = slices.Clone(transactions)
+		slices.SortStableFunc(transactions, compareTx)
+	}
+
+	l := newLedger()
+	for _, transaction := range transactions {
+		l.apply(transaction)
+	}
+
+	if ctx.ledgers == nil {
+		ctx.ledgers = map[*TxAsset]*ledger{}
+	}
+
+	ctx.ledgers[a] = l
+	return l
+}
+
+// compareTx orders transactions chronologically.
+func compareTx(a, b *Tx) int {
+	return a.Timestamp.Compare(b.Timestamp)
+}
+
+// updateLedger applies the transaction just added to its asset to the asset's ledger. If the transaction is older than the latest one
+// already applied, the ledger is dropped and rebuilt on the next read.
+func (ctx *Context) updateLedger(transaction *Tx) {
+	ctx.cacheMu.Lock()
+	defer ctx.cacheMu.Unlock()
+
+	a := transaction.Asset
+	l, ok := ctx.ledgers[a]
+	if !ok {
+		return
+	}
+
+	if l.count != len(a.Transactions)-1 || transaction.Timestamp.Before(l.last) {
+		delete(ctx.ledgers, a)
+		return
+	}
+
+	l.apply(transaction)
+}
@@ -0,0 +1,131 @@
+package transaction_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// newTx creates a transaction of asset "A" at the given Unix millisecond timestamp.
+func newTx(ts int64, txType transaction.TxType, quantity int64, unitPrice int64) transaction.Tx {
+	return transaction.Tx{
+		Position: transaction.Position{
+			Asset:     &transaction.TxAsset{Id: "A"},
+			Timestamp: time.UnixMilli(ts),
+			Quantity:  big.NewRat(quantity, 1),
+			UnitPrice: big.NewRat(unitPrice, 1),
+		},
+		Type: txType,
+	}
+}
+
+// TestContext_Ledger_Incremental tests that positions, quantities, initial worth and realized profits are kept up to date while
+// transactions are added one by one.
+func TestContext_Ledger_Incremental(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	assert.Nil(t, ctx.AddTransaction(newTx(1, transaction.BUY, 10, 100)))
+	assert.Nil(t, ctx.AddTransaction(newTx(2, transaction.BUY, 10, 110)))
+	assert.Equal(t, big.NewRat(2100, 1), ctx.GetAssetKeyInitialWorthMap()["A"])
+
+	assert.Nil(t, ctx.AddTransaction(newTx(3, transaction.SELL, 15, 120)))
+	assert.Equal(t, big.NewRat(5, 1), ctx.GetAssetKeyMap()["A"])
+	assert.Equal(t, big.NewRat(550, 1), ctx.GetAssetKeyInitialWorthMap()["A"])
+	assert.Equal(t, big.NewRat(250, 1), ctx.GetRealizedProfit())
+
+	assert.Nil(t, ctx.AddTransaction(newTx(4, transaction.SPLIT, 5, 0)))
+	p, err := ctx.GetAssetKeyPositions("A")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(p))
+	assert.Equal(t, big.NewRat(10, 1), p[0].Quantity)
+	assert.Equal(t, big.NewRat(55, 1), p[0].UnitPrice)
+}
+
+// TestContext_Ledger_Reinvest tests that a reinvested dividend adds a new position at its unit price and counts as realized income.
+func TestContext_Ledger_Reinvest(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	assert.Nil(t, ctx.AddTransactions([]transaction.Tx{
+		newTx(1, transaction.BUY, 10, 100),
+		newTx(2, transaction.REINVEST, 2, 120),
+		newTx(3, transaction.SELL, 11, 130),
+	}))
+
+	assert.Equal(t, big.NewRat(1, 1), ctx.GetAssetKeyMap()["A"])
+	assert.Equal(t, big.NewRat(120, 1), ctx.GetAssetKeyInitialWorthMap()["A"])
+
+	p, err := ctx.GetAssetKeyPositions("A")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(p))
+	assert.Equal(t, big.NewRat(120, 1), p[0].UnitPrice)
+
+	/* 240 of reinvested income, 300 of profit on the initial lot and 10 on the reinvested one. */
+	assert.Equal(t, big.NewRat(550, 1), ctx.GetRealizedProfit())
+
+	a, _ := ctx.GetAsset("A")
+	assert.Equal(t, big.NewRat(240, 1), ctx.GetDividendIncome(a, time.Time{}, time.Time{}))
+	assert.Equal(t, big.NewRat(12, 1), ctx.GetAssetQuantityAt(a, time.UnixMilli(2)))
+}
+
+// TestContext_Ledger_Out_Of_Order tests that adding a transaction older than the latest one yields the same result as adding the
+// transactions in chronological order.
+func TestContext_Ledger_Out_Of_Order(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	assert.Nil(t, ctx.AddTransaction(newTx(1, transaction.BUY, 10, 100)))
+	assert.Nil(t, ctx.AddTransaction(newTx(3, transaction.SELL, 5, 120)))
+	assert.Equal(t, big.NewRat(100, 1), ctx.GetRealizedProfit())
+
+	assert.Nil(t, ctx.AddTransaction(newTx(2, transaction.BUY, 10, 80)))
+	assert.Equal(t, big.NewRat(100, 1), ctx.GetRealizedProfit())
+
+	p, err := ctx.GetAssetKeyPositions("A")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(p))
+	assert.Equal(t, big.NewRat(5, 1), p[0].Quantity)
+	assert.Equal(t, big.NewRat(80, 1), p[1].UnitPrice)
+	assert.Equal(t, time.UnixMilli(3), ctx.Assets[0].Transactions[2].Timestamp)
+}
+
+// TestContext_Ledger_Modified_Transactions tests that the ledger is rebuilt if the transactions of an asset are modified directly.
+func TestContext_Ledger_Modified_Transactions(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	assert.Nil(t, ctx.AddTransaction(newTx(1, transaction.BUY, 10, 100)))
+	assert.Equal(t, big.NewRat(10, 1), ctx.GetAssetKeyMap()["A"])
+
+	tx := newTx(2, transaction.SELL, 4, 100)
+	tx.Asset = ctx.Assets[0]
+	ctx.Assets[0].Transactions = append(ctx.Assets[0].Transactions, &tx)
+	assert.Equal(t, big.NewRat(6, 1), ctx.GetAssetKeyMap()["A"])
+}
+
+// TestContext_GetAssetPositions_Copy tests that modifying the returned positions does not affect the Context.
+func TestContext_GetAssetPositions_Copy(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	assert.Nil(t, ctx.AddTransaction(newTx(1, transaction.BUY, 10, 100)))
+
+	p := ctx.GetAssetPositions(ctx.Assets[0])
+	p[0].Quantity.SetInt64(1)
+
+	assert.Equal(t, big.NewRat(10, 1), ctx.GetAssetPositions(ctx.Assets[0])[0].Quantity)
+}
+
+// BenchmarkContext_GetAssetInitialWorthMap measures repeated reads of a large transaction history.
+func BenchmarkContext_GetAssetInitialWorthMap(b *testing.B) {
+	ctx := transaction.Context{}
+	_ = ctx.AddTransactions(syntheticTransactions(100000, 100))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = ctx.GetAssetInitialWorthMap()
+	}
+}
@@ -1,13 +1,15 @@
 package transaction
 
 import (
+	"fmt"
 	"math/big"
 	"time"
 )
 
 // TxAsset represents an asset used in a transaction
 type TxAsset struct {
-	Id           string
+	Id string
+	// Transactions holds the transactions of the asset in chronological order.
 	Transactions []*Tx
 }
 
@@ -17,7 +19,27 @@ type TxType = int
 const (
 	BUY TxType = iota
 	SELL
+	// DIVIDEND is a dividend paid in cash. The net amount credited after taxes withheld at the source is stored in the UnitPrice field,
+	// the withheld taxes in the Gross and WithheldTax fields.
 	DIVIDEND
+	// FEE is a cost charged by the broker. The amount is stored in the UnitPrice field, the same way as for DIVIDEND.
+	FEE
+	// TAX is a tax deducted by the broker, e.g. a stamp duty. The amount is stored in the UnitPrice field. Taxes withheld from dividends
+	// are stored on the DIVIDEND instead.
+	TAX
+	// SPLIT changes the number of units held without changing the cost basis. The Quantity field holds the number of units added by the
+	// split, or removed in case of a reverse split.
+	SPLIT
+	// DEPOSIT is cash paid into the account. The amount is stored in the UnitPrice field, the asset is the currency of the cash.
+	DEPOSIT
+	// WITHDRAWAL is cash paid out of the account. The amount is stored in the UnitPrice field, the asset is the currency of the cash.
+	WITHDRAWAL
+	// INTEREST is interest earned on cash. The amount is stored in the UnitPrice field, the asset is the currency of the cash.
+	INTEREST
+	// REINVEST is a dividend that is reinvested in the asset paying it. The Quantity field holds the number of units bought and the
+	// UnitPrice field the price paid per unit, so the dividend amount is their product. The units are added as a new position like a BUY,
+	// while the amount counts as dividend income.
+	REINVEST
 )
 
 // Position depicts a certain quantity of an asset at a given time at a given unit price.
@@ -32,4 +54,52 @@ type Position struct {
 type Tx struct {
 	Position
 	Type TxType
+	// Id is the identifier of the transaction at the source it was imported from, e.g. the trade ID of the broker.
+	Id string
+	// Currency is the ISO 4217 code of the currency the transaction was settled in.
+	Currency string
+	// Gross is the amount of a DIVIDEND or REINVEST before taxes were withheld at the source. The net amount is the amount of the
+	// dividend itself. It is nil if unknown.
+	Gross *big.Rat
+	// WithheldTax is the tax withheld from a DIVIDEND or REINVEST at the source. If nil, it is the difference between Gross and the net
+	// amount.
+	WithheldTax *big.Rat
+	// Country is the ISO 3166-1 alpha-2 code of the country the tax of a DIVIDEND or REINVEST was withheld by, e.g. "US". The broker
+	// loaders take it from the ISIN of the asset.
+	Country string
+}
+
+// txTypeNames maps every TxType to its textual representation.
+var txTypeNames = map[TxType]string{
+	BUY:        "BUY",
+	SELL:       "SELL",
+	DIVIDEND:   "DIVIDEND",
+	FEE:        "FEE",
+	TAX:        "TAX",
+	SPLIT:      "SPLIT",
+	DEPOSIT:    "DEPOSIT",
+	WITHDRAWAL: "WITHDRAWAL",
+	INTEREST:   "INTEREST",
+	REINVEST:   "REINVEST",
+}
+
+// FormatTxType converts the TxType to its textual representation, e.g. "BUY".
+func FormatTxType(t TxType) (string, error) {
+	name, ok := txTypeNames[t]
+	if !ok {
+		return "", fmt.Errorf("unsupported trade type %d", t)
+	}
+
+	return name, nil
+}
+
+// ParseTxType converts the textual representation of a transaction type to TxType.
+func ParseTxType(s string) (TxType, error) {
+	for t, name := range txTypeNames {
+		if name == s {
+			return t, nil
+		}
+	}
+
+	return -1, fmt.Errorf("unsupported trade type")
 }
@@ -3,7 +3,6 @@ package transaction
 import (
 	"fmt"
 	"math/big"
-	"slices"
 )
 
 // GetReturnForUnitPrice calculates the difference between the initial value of the position and its current value.
@@ -26,16 +25,20 @@ func (p Position) Clone() Position {
 }
 
 // GetAssetPositionSliceMap maps quantities to a chronologically ordered slice of positions.
-// Transactions are used as a basis: There are two scenarios, BUY and SELL. In case of a BUY transaction, the position is simply added to
-// the end of the position slice. In case of a SELL transaction however, the position quantity is subtracted from the oldest position.
+// Transactions are used as a basis: There are two scenarios, BUY and SELL. In case of a BUY or REINVEST transaction, the position is
+// simply added to the end of the position slice. In case of a SELL transaction however, the position quantity is subtracted from the
+// oldest position.
 // If the transaction value is higher than the first position, remove the first position, subtract the quantity from the transaction
 // quantity and try again.
 func (ctx *Context) GetAssetPositionSliceMap() map[*TxAsset][]Position {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
 	m := map[*TxAsset][]Position{}
 	for i := range ctx.Assets {
 		asset := ctx.Assets[i]
 
-		m[asset] = ctx.GetAssetPositions(asset)
+		m[asset] = ctx.getAssetPositions(asset)
 
 		if len(m[asset]) == 0 {
 			delete(m, asset)
@@ -45,23 +48,52 @@ func (ctx *Context) GetAssetPositionSliceMap() map[*TxAsset][]Position {
 	return m
 }
 
-// GetAssetPositions calculates the open positions for the given TxAsset.
+// GetAssetPositions calculates the open positions for the given TxAsset. The positions are served from the asset's ledger, which is
+// maintained incrementally while transactions are added. The returned positions are copies and may be modified freely.
 func (ctx *Context) GetAssetPositions(a *TxAsset) []Position {
-	var p []Position
-
-	// sort transactions according to timestamp
-	slices.SortFunc(a.Transactions, func(a, b *Tx) int {
-		return a.Timestamp.Compare(b.Timestamp)
-	})
-
-	for _, transaction := range a.Transactions {
-		switch transaction.Type {
-		case BUY:
-			p = append(p, transaction.Clone())
-		case SELL:
-			p, _ = subtractAssetPosition(p, transaction.Clone())
-		default:
-		}
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.getAssetPositions(a)
+}
+
+// getAssetPositions copies the open positions of the TxAsset from its ledger without locking the Context.
+func (ctx *Context) getAssetPositions(a *TxAsset) []Position {
+	l := ctx.assetLedger(a)
+	if len(l.positions) == 0 {
+		return nil
+	}
+
+	p := make([]Position, len(l.positions))
+	for i, position := range l.positions {
+		p[i] = position.Clone()
+	}
+
+	return p
+}
+
+// splitAssetPositions distributes the units added by a split proportionally among the open positions. The unit price of every position is
+// adjusted such that the cost basis of the position stays the same.
+func splitAssetPositions(p []Position, delta *big.Rat) []Position {
+	held := big.NewRat(0, 1)
+	for _, position := range p {
+		held.Add(held, position.Quantity)
+	}
+
+	if held.Sign() == 0 {
+		return p
+	}
+
+	factor := big.NewRat(0, 1).Add(held, delta)
+	factor.Quo(factor, held)
+
+	if factor.Sign() <= 0 {
+		return p
+	}
+
+	for _, position := range p {
+		position.Quantity.Mul(position.Quantity, factor)
+		position.UnitPrice.Quo(position.UnitPrice, factor)
 	}
 
 	return p
@@ -95,10 +127,10 @@ func subtractAssetPosition(p []Position, position Position) ([]Position, []*big.
 
 // GetAssetKeyPositions calculates the open positions for the given TxAsset key.
 func (ctx *Context) GetAssetKeyPositions(assetId string) ([]Position, error) {
-	for _, asset := range ctx.Assets {
-		if asset.Id == assetId {
-			return ctx.GetAssetPositions(asset), nil
-		}
+	asset, ok := ctx.GetAsset(assetId)
+	if !ok {
+		return nil, fmt.Errorf("asset with key \"%s\" not found", assetId)
 	}
-	return nil, fmt.Errorf("asset with key \"%s\" not found", assetId)
+
+	return ctx.GetAssetPositions(asset), nil
 }
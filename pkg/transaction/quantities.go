@@ -2,30 +2,28 @@ package transaction
 
 import (
 	"math/big"
+	"time"
 )
 
 // GetAssetMap calculates the overall owned quantities based on the Context.
 // Assets that can be found in the transaction history but have already been sold will not be shown in the summary.
 func (ctx *Context) GetAssetMap() map[*TxAsset]*big.Rat {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.getAssetMap()
+}
+
+// getAssetMap calculates the overall owned quantities without locking the Context.
+func (ctx *Context) getAssetMap() map[*TxAsset]*big.Rat {
 	summary := map[*TxAsset]*big.Rat{}
 
 	for i := range ctx.Assets {
 		asset := ctx.Assets[i]
-		quantity := big.NewRat(0, 1)
-
-		for _, transaction := range asset.Transactions {
-			switch transaction.Type {
-			case BUY:
-				quantity.Add(quantity, transaction.Quantity)
-			case SELL:
-				quantity.Sub(quantity, transaction.Quantity)
-			default:
-				// not relevant
-			}
-		}
+		quantity := ctx.assetLedger(asset).quantity
 
 		if quantity.Cmp(big.NewRat(0, 1)) != 0 {
-			summary[asset] = quantity
+			summary[asset] = big.NewRat(0, 1).Set(quantity)
 		}
 	}
 
@@ -43,3 +41,33 @@ func (ctx *Context) GetAssetKeyMap() map[string]*big.Rat {
 
 	return keyMap
 }
+
+// GetAssetQuantityAt calculates the quantity of the TxAsset owned at the given time, including the transactions at exactly that time.
+func (ctx *Context) GetAssetQuantityAt(a *TxAsset, t time.Time) *big.Rat {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	quantity := big.NewRat(0, 1)
+	for _, transaction := range a.Transactions {
+		if transaction.Timestamp.After(t) {
+			continue
+		}
+
+		quantity.Add(quantity, transaction.QuantityChange())
+	}
+
+	return quantity
+}
+
+// QuantityChange returns by how much the Tx changes the owned quantity of its asset, e.g. the negated quantity of a SELL. Transactions not
+// affecting the quantity, like a DIVIDEND, return zero.
+func (t *Tx) QuantityChange() *big.Rat {
+	switch t.Type {
+	case BUY, SPLIT, REINVEST:
+		return big.NewRat(0, 1).Set(t.Quantity)
+	case SELL:
+		return big.NewRat(0, 1).Neg(t.Quantity)
+	default:
+		return big.NewRat(0, 1)
+	}
+}
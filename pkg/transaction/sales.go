@@ -2,17 +2,34 @@ package transaction
 
 import (
 	"math/big"
-	"slices"
 )
 
 // GetRealizedProfit sums up the earnings for every transaction that was sold higher than the initial price.
 func (ctx *Context) GetRealizedProfit() *big.Rat {
-	profit := ctx.getRealizedProfitsAndLosses()
+	return sumProfits(ctx.getRealizedProfitsAndLosses(false))
+}
+
+// GetRealizedLoss sums up the earnings for every transaction that was sold lower than the initial price.
+func (ctx *Context) GetRealizedLoss() *big.Rat {
+	return sumLosses(ctx.getRealizedProfitsAndLosses(false))
+}
+
+// GetRealizedSalesProfit sums up the profits realized with SELL transactions. Unlike GetRealizedProfit, income like dividends is not
+// included.
+func (ctx *Context) GetRealizedSalesProfit() *big.Rat {
+	return sumProfits(ctx.getRealizedProfitsAndLosses(true))
+}
 
+// GetRealizedSalesLoss sums up the losses realized with SELL transactions.
+func (ctx *Context) GetRealizedSalesLoss() *big.Rat {
+	return sumLosses(ctx.getRealizedProfitsAndLosses(true))
+}
+
+// sumProfits sums up the positive values.
+func sumProfits(diffs []*big.Rat) *big.Rat {
 	p := big.NewRat(0, 1)
-	zero := big.NewRat(0, 1)
-	for _, diff := range profit {
-		if zero.Cmp(diff) < 0 {
+	for _, diff := range diffs {
+		if diff.Sign() > 0 {
 			p.Add(p, diff)
 		}
 	}
@@ -20,14 +37,11 @@ func (ctx *Context) GetRealizedProfit() *big.Rat {
 	return p
 }
 
-// GetRealizedLoss sums up the earnings for every transaction that was sold lower than the initial price.
-func (ctx *Context) GetRealizedLoss() *big.Rat {
-	profit := ctx.getRealizedProfitsAndLosses()
-
+// sumLosses sums up the absolute values of the negative values.
+func sumLosses(diffs []*big.Rat) *big.Rat {
 	p := big.NewRat(0, 1)
-	zero := big.NewRat(0, 1)
-	for _, diff := range profit {
-		if diff.Cmp(zero) < 0 {
+	for _, diff := range diffs {
+		if diff.Sign() < 0 {
 			p.Sub(p, diff)
 		}
 	}
@@ -35,31 +49,53 @@ func (ctx *Context) GetRealizedLoss() *big.Rat {
 	return p
 }
 
-// getRealizedProfitsAndLosses returns a slice of profits and losses realized with every individual SELL transaction.
-func (ctx *Context) getRealizedProfitsAndLosses() []*big.Rat {
-	m := map[*TxAsset][]Position{}
+// getRealizedProfitsAndLosses returns a slice of profits and losses realized with every individual SELL and DIVIDEND transaction. If
+// salesOnly is true, only the profits and losses of SELL transactions are returned.
+func (ctx *Context) getRealizedProfitsAndLosses(salesOnly bool) []*big.Rat {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
 	var profit []*big.Rat
 
 	for i := range ctx.Assets {
-		asset := ctx.Assets[i]
-
-		// sort transactions according to timestamp
-		slices.SortFunc(asset.Transactions, func(a, b *Tx) int {
-			return a.Timestamp.Compare(b.Timestamp)
-		})
-
-		for _, transaction := range asset.Transactions {
-			switch transaction.Type {
-			case BUY:
-				m[asset] = append(m[asset], transaction.Clone())
-			case SELL:
-				_, diffs := subtractAssetPosition(m[asset], transaction.Clone())
-				profit = append(profit, diffs...)
-			case DIVIDEND:
-				profit = append(profit, transaction.UnitPrice)
-			}
+		l := ctx.assetLedger(ctx.Assets[i])
+		if salesOnly {
+			profit = append(profit, l.sales...)
+		} else {
+			profit = append(profit, l.realized...)
 		}
 	}
 
 	return profit
 }
+
+// GetFees sums up the fees charged by the broker. Fees are not part of the realized profits and losses.
+func (ctx *Context) GetFees() *big.Rat {
+	return ctx.sumAmounts(FEE)
+}
+
+// GetTaxes sums up the taxes deducted by the broker. Taxes are not part of the realized profits and losses. Taxes withheld from dividends
+// are reported by GetWithholdingReport instead.
+func (ctx *Context) GetTaxes() *big.Rat {
+	return ctx.sumAmounts(TAX)
+}
+
+// GetInterest sums up the interest earned on cash. Interest is not part of the realized profits and losses.
+func (ctx *Context) GetInterest() *big.Rat {
+	return ctx.sumAmounts(INTEREST)
+}
+
+// sumAmounts sums up the amounts of the transactions of the given type, which are stored in the UnitPrice field.
+func (ctx *Context) sumAmounts(txType TxType) *big.Rat {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	sum := big.NewRat(0, 1)
+	for _, t := range ctx.Transactions {
+		if t.Type == txType {
+			sum.Add(sum, t.UnitPrice)
+		}
+	}
+
+	return sum
+}
@@ -0,0 +1,52 @@
+package transaction_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"math/big"
+	"testing"
+)
+
+// TestContext_GetRealizedProfit_Fees_And_Taxes tests that fees, taxes and interest are reported separately and do not change the realized
+// profits and losses of sales and dividends.
+func TestContext_GetRealizedProfit_Fees_And_Taxes(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	assert.Nil(t, ctx.AddTransactions([]transaction.Tx{
+		newTx(1, transaction.BUY, 10, 100),
+		newTx(2, transaction.FEE, 1, 5),
+		newTx(3, transaction.DIVIDEND, 1, 20),
+		newTx(4, transaction.TAX, 1, 3),
+		newTx(5, transaction.SELL, 5, 90),
+		newTx(6, transaction.SELL, 5, 110),
+		newTx(7, transaction.FEE, 1, 2),
+		newTx(8, transaction.INTEREST, 1, 4),
+	}))
+
+	assert.Equal(t, big.NewRat(70, 1), ctx.GetRealizedProfit())
+	assert.Equal(t, big.NewRat(50, 1), ctx.GetRealizedLoss())
+	assert.Equal(t, big.NewRat(7, 1), ctx.GetFees())
+	assert.Equal(t, big.NewRat(3, 1), ctx.GetTaxes())
+	assert.Equal(t, big.NewRat(4, 1), ctx.GetInterest())
+	assert.Equal(t, big.NewRat(50, 1), ctx.GetRealizedSalesProfit(), "the dividend should not count as sales profit")
+	assert.Equal(t, big.NewRat(50, 1), ctx.GetRealizedSalesLoss())
+}
+
+// TestContext_GetRealizedProfit_Consecutive_Sales tests that a sale consumes the positions it is matched with, so a later sale is matched
+// with the next position instead of the one already sold.
+func TestContext_GetRealizedProfit_Consecutive_Sales(t *testing.T) {
+	t.Parallel()
+
+	ctx := transaction.Context{}
+	assert.Nil(t, ctx.AddTransactions([]transaction.Tx{
+		newTx(1, transaction.BUY, 1, 10),
+		newTx(2, transaction.BUY, 1, 20),
+		newTx(3, transaction.SELL, 1, 15),
+		newTx(4, transaction.SELL, 1, 15),
+	}))
+
+	assert.Equal(t, big.NewRat(5, 1), ctx.GetRealizedProfit())
+	assert.Equal(t, big.NewRat(5, 1), ctx.GetRealizedLoss())
+	assert.Empty(t, ctx.GetAssetKeyMap())
+}
@@ -7,7 +7,15 @@ import (
 
 // Validate verifies that the Context is in a valid state.
 func (ctx *Context) Validate() error {
-	summary := ctx.GetAssetMap()
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.validate()
+}
+
+// validate verifies that the Context is in a valid state without locking it.
+func (ctx *Context) validate() error {
+	summary := ctx.getAssetMap()
 
 	for asset, quantity := range summary {
 		if quantity.Cmp(big.NewRat(0, 1)) == -1 {
@@ -0,0 +1,113 @@
+package transaction
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+)
+
+// WithholdingSummary sums up the taxes withheld at the source from the dividends paid by a country in a currency.
+type WithholdingSummary struct {
+	// Country is the ISO 3166-1 alpha-2 code of the country the taxes were withheld by.
+	Country string
+	// Currency is the ISO 4217 code of the currency the dividends were paid in.
+	Currency string
+	Gross    *big.Rat
+	Withheld *big.Rat
+	Net      *big.Rat
+	// TreatyRate is the rate up to which the withheld tax can be credited against the domestic tax under the tax treaty, e.g. 0.15 for 15%.
+	TreatyRate *big.Rat
+	// Creditable is the part of the withheld tax that can be credited, i.e. at most the treaty rate of the gross amount.
+	Creditable *big.Rat
+	// Reclaimable is the part of the withheld tax exceeding the treaty rate, which can be reclaimed from the country.
+	Reclaimable *big.Rat
+}
+
+// withholding returns the gross amount, the withheld tax and the net amount of a dividend. If the gross amount or the withheld tax is
+// unknown, it is derived from the other one and the net amount. The last return value is false if the transaction is not a dividend with
+// withholding details.
+func withholding(t *Tx) (*big.Rat, *big.Rat, *big.Rat, bool) {
+	net, ok := dividendAmount(t)
+	if !ok || (t.Gross == nil && t.WithheldTax == nil) {
+		return nil, nil, nil, false
+	}
+
+	gross, withheld := t.Gross, t.WithheldTax
+	if gross == nil {
+		gross = big.NewRat(0, 1).Add(net, withheld)
+	}
+	if withheld == nil {
+		withheld = big.NewRat(0, 1).Sub(gross, net)
+	}
+
+	return gross, withheld, net, true
+}
+
+// GetWithholdingReport sums up the taxes withheld from the dividends paid between from and to per country and currency, and splits them
+// into the creditable and the reclaimable part according to the treaty rates, which map country codes to rates. A zero from or to leaves
+// the period open at that side. Dividends without a gross amount or withheld tax are not listed. An error is returned if a dividend with
+// withholding details has no country or there is no treaty rate for its country.
+func (ctx *Context) GetWithholdingReport(rates map[string]*big.Rat, from time.Time, to time.Time) ([]WithholdingSummary, error) {
+	type key struct {
+		country  string
+		currency string
+	}
+
+	summaries := map[key]*WithholdingSummary{}
+	for _, t := range ctx.GetTransactions() {
+		gross, withheld, net, ok := withholding(t)
+		if !ok || (!from.IsZero() && t.Timestamp.Before(from)) || (!to.IsZero() && t.Timestamp.After(to)) {
+			continue
+		}
+
+		if t.Country == "" {
+			return nil, fmt.Errorf("missing country of dividend %v", t)
+		}
+
+		rate, ok := rates[t.Country]
+		if !ok || rate == nil {
+			return nil, fmt.Errorf("missing treaty rate for country %s", t.Country)
+		}
+
+		k := key{country: t.Country, currency: t.Currency}
+		s, ok := summaries[k]
+		if !ok {
+			s = &WithholdingSummary{
+				Country:     t.Country,
+				Currency:    t.Currency,
+				Gross:       big.NewRat(0, 1),
+				Withheld:    big.NewRat(0, 1),
+				Net:         big.NewRat(0, 1),
+				TreatyRate:  big.NewRat(0, 1).Set(rate),
+				Creditable:  big.NewRat(0, 1),
+				Reclaimable: big.NewRat(0, 1),
+			}
+			summaries[k] = s
+		}
+
+		s.Gross.Add(s.Gross, gross)
+		s.Withheld.Add(s.Withheld, withheld)
+		s.Net.Add(s.Net, net)
+
+		/* The creditable tax is capped per dividend, since the treaty rate applies to every payment. */
+		creditable := big.NewRat(0, 1).Mul(gross, rate)
+		if creditable.Cmp(withheld) > 0 {
+			creditable.Set(withheld)
+		}
+		s.Creditable.Add(s.Creditable, creditable)
+		s.Reclaimable.Add(s.Reclaimable, creditable.Sub(withheld, creditable))
+	}
+
+	report := make([]WithholdingSummary, 0, len(summaries))
+	for _, s := range summaries {
+		report = append(report, *s)
+	}
+
+	slices.SortFunc(report, func(a, b WithholdingSummary) int {
+		return cmp.Or(cmp.Compare(a.Country, b.Country), cmp.Compare(a.Currency, b.Currency))
+	})
+
+	return report, nil
+}
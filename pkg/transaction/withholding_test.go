@@ -0,0 +1,50 @@
+package transaction_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlachs/wstonks/pkg/transaction"
+	"github.com/wlachs/wstonks/pkg/transaction/io"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// TestContext_GetWithholdingReport checks the withheld taxes per country and their split into creditable and reclaimable parts.
+func TestContext_GetWithholdingReport(t *testing.T) {
+	t.Parallel()
+
+	ctx := &transaction.Context{}
+	assert.NoError(t, io.TxCsvLoader{Path: "../../test/data/io/transactions/withholding.csv"}.Load(ctx))
+
+	rates := map[string]*big.Rat{"US": big.NewRat(15, 100), "CH": big.NewRat(15, 100)}
+	report, err := ctx.GetWithholdingReport(rates, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, report, 2)
+
+	/* 26.375% Swiss tax on a gross dividend of 10, of which 15% can be credited and the rest reclaimed. */
+	ch := report[0]
+	assert.Equal(t, "CH", ch.Country)
+	assert.Equal(t, "CHF", ch.Currency)
+	assert.Equal(t, big.NewRat(10, 1), ch.Gross)
+	assert.Equal(t, big.NewRat(211, 80), ch.Withheld)
+	assert.Equal(t, big.NewRat(3, 2), ch.Creditable)
+	assert.Equal(t, big.NewRat(91, 80), ch.Reclaimable)
+
+	/* 15% US tax on two dividends, which is fully creditable. */
+	us := report[1]
+	assert.Equal(t, "US", us.Country)
+	assert.Equal(t, big.NewRat(15, 1), us.Gross)
+	assert.Equal(t, big.NewRat(9, 4), us.Withheld)
+	assert.Equal(t, big.NewRat(51, 4), us.Net)
+	assert.Equal(t, big.NewRat(9, 4), us.Creditable)
+	assert.Equal(t, 0, us.Reclaimable.Sign())
+
+	report, err = ctx.GetWithholdingReport(rates, time.UnixMilli(1712350000000), time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, report, 1)
+	assert.Equal(t, big.NewRat(5, 1), report[0].Gross)
+
+	_, err = ctx.GetWithholdingReport(map[string]*big.Rat{"US": big.NewRat(15, 100)}, time.Time{}, time.Time{})
+	assert.Equal(t, fmt.Errorf("missing treaty rate for country CH"), err)
+}